@@ -0,0 +1,180 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/go-telegram/bot"
+	"github.com/joho/godotenv"
+	"github.com/peterbourgon/ff/v3/ffcli"
+
+	"github.com/sig-0/chigui-cifras/cmd/env"
+	"github.com/sig-0/chigui-cifras/internal/broadcast"
+	"github.com/sig-0/chigui-cifras/internal/clock"
+	"github.com/sig-0/chigui-cifras/internal/config"
+	"github.com/sig-0/chigui-cifras/internal/store"
+)
+
+var (
+	errMissingStorePath = errors.New("broadcasts need a store path, to know the chats")
+	errMissingMessage   = errors.New("missing broadcast message")
+	errUnexpectedArgs   = errors.New("resume doesn't take a message")
+	errBotRunning       = errors.New("the bot has the store open, stop it or use the /broadcast admin command")
+)
+
+// broadcastCfg wraps the broadcast configuration
+type broadcastCfg struct {
+	configPath string
+
+	dryRun bool
+	resume bool
+}
+
+// NewBroadcastCmd creates the broadcast subcommand
+func NewBroadcastCmd() *ffcli.Command {
+	cfg := &broadcastCfg{}
+
+	fs := flag.NewFlagSet("broadcast", flag.ExitOnError)
+	cfg.registerFlags(fs)
+
+	return &ffcli.Command{
+		Name:       "broadcast",
+		ShortUsage: "broadcast [flags] <message>",
+		LongHelp: "Sends a plain text announcement to every known chat. " +
+			"The store is locked while the bot runs, so stop the bot first, " +
+			"or use the /broadcast admin command instead",
+		FlagSet: fs,
+		Exec:    cfg.exec,
+	}
+}
+
+func (c *broadcastCfg) registerFlags(fs *flag.FlagSet) {
+	fs.StringVar(
+		&c.configPath,
+		"config",
+		"",
+		"the path to the server TOML configuration, if any",
+	)
+
+	fs.BoolVar(
+		&c.dryRun,
+		"dry-run",
+		false,
+		"report the recipients, without sending anything",
+	)
+
+	fs.BoolVar(
+		&c.resume,
+		"resume",
+		false,
+		"resume the interrupted broadcast, instead of starting a new one",
+	)
+}
+
+func (c *broadcastCfg) exec(ctx context.Context, args []string) error {
+	message := strings.TrimSpace(strings.Join(args, " "))
+
+	switch {
+	case c.resume && message != "":
+		return errUnexpectedArgs
+	case !c.resume && message == "":
+		return errMissingMessage
+	}
+
+	cfg := config.DefaultConfig()
+
+	// Read the server configuration, if any
+	if c.configPath != "" {
+		serverCfg, err := config.Read(c.configPath)
+		if err != nil {
+			return fmt.Errorf("unable to read server config, %w", err)
+		}
+
+		cfg = serverCfg
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	// Load .env
+	if err := godotenv.Load(); err != nil {
+		logger.Warn("unable to load .env file")
+	}
+
+	if err := env.Apply(cfg); err != nil {
+		return err
+	}
+
+	if err := config.ValidateConfig(cfg); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	if cfg.Store.Path == "" {
+		return errMissingStorePath
+	}
+
+	queue := broadcast.NewQueue(logger, cfg.Admin.BroadcastInterval, clock.System)
+
+	if c.dryRun {
+		// Dry runs only read the store, so they work while the bot runs
+		chatStore, err := store.OpenReadOnly(cfg.Store.Path)
+		if err != nil {
+			return fmt.Errorf("unable to open store: %w", err)
+		}
+
+		report := broadcast.New(chatStore, logger, queue, clock.System).DryRun()
+
+		fmt.Printf("The message would be sent to %d chats, skipping %d blocked\n", report.Pending, report.Blocked)
+
+		return nil
+	}
+
+	chatStore, err := store.Open(cfg.Store.Path)
+	if errors.Is(err, store.ErrLocked) {
+		return errBotRunning
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to open store: %w", err)
+	}
+
+	defer chatStore.Close()
+
+	broadcaster := broadcast.New(chatStore, logger, queue, clock.System)
+
+	tgBot, err := bot.New(cfg.Telegram.Token, bot.WithSkipGetMe())
+	if err != nil {
+		return fmt.Errorf("unable to create telegram bot: %w", err)
+	}
+
+	if !c.resume {
+		if _, err := broadcaster.Start(message); err != nil {
+			return fmt.Errorf("unable to start broadcast: %w", err)
+		}
+	}
+
+	// Interrupted broadcasts resume with -resume, or when the bot starts
+	runCtx, cancelFn := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancelFn()
+
+	report, err := broadcaster.Run(runCtx, broadcast.NewTelegramSender(tgBot))
+	if err != nil {
+		return fmt.Errorf("broadcast interrupted with %d pending: %w", report.Pending, err)
+	}
+
+	fmt.Printf(
+		"Broadcast finished: %d of %d sent, %d failed, %d blocked\n",
+		report.Sent,
+		report.Total(),
+		report.Failed,
+		report.Blocked,
+	)
+
+	return nil
+}
@@ -1,11 +1,107 @@
 package env
 
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sig-0/chigui-cifras/internal/config"
+)
+
 const (
 	Prefix = "CHIGUI"
 
 	TelegramTokenSuffix      = "TELEGRAM_TOKEN"
 	WebhookURLSuffix         = "WEBHOOK_URL"
 	WebhookSecretTokenSuffix = "WEBHOOK_SECRET_TOKEN"
+	ParseModeSuffix          = "PARSE_MODE"
+	TimeZoneSuffix           = "TIME_ZONE"
 	FXRatesURLSuffix         = "FXRATES_URL"
 	FXRatesTimeoutSuffix     = "FXRATES_TIMEOUT"
+	FXRatesCacheTTLSuffix    = "FXRATES_CACHE_TTL"
+	StorePathSuffix          = "STORE_PATH"
+	AdminUserIDsSuffix       = "ADMIN_USER_IDS"
 )
+
+// Apply overrides the configuration with the environment variables, if set
+func Apply(cfg *config.Config) error {
+	if v, ok := os.LookupEnv(Prefix + "_" + TelegramTokenSuffix); ok {
+		cfg.Telegram.Token = v
+	}
+
+	if v, ok := os.LookupEnv(Prefix + "_" + WebhookURLSuffix); ok {
+		cfg.Telegram.WebhookURL = v
+	}
+
+	if v, ok := os.LookupEnv(Prefix + "_" + WebhookSecretTokenSuffix); ok {
+		cfg.Telegram.WebhookSecretToken = v
+	}
+
+	if v, ok := os.LookupEnv(Prefix + "_" + ParseModeSuffix); ok {
+		cfg.Telegram.ParseMode = v
+	}
+
+	if v, ok := os.LookupEnv(Prefix + "_" + TimeZoneSuffix); ok {
+		cfg.Telegram.TimeZone = v
+	}
+
+	if v, ok := os.LookupEnv(Prefix + "_" + FXRatesURLSuffix); ok {
+		cfg.FXRates.BaseURL = v
+	}
+
+	if v, ok := os.LookupEnv(Prefix + "_" + FXRatesTimeoutSuffix); ok {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s_%s: %w", Prefix, FXRatesTimeoutSuffix, err)
+		}
+
+		cfg.FXRates.Timeout = timeout
+	}
+
+	if v, ok := os.LookupEnv(Prefix + "_" + FXRatesCacheTTLSuffix); ok {
+		cacheTTL, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s_%s: %w", Prefix, FXRatesCacheTTLSuffix, err)
+		}
+
+		cfg.FXRates.CacheTTL = cacheTTL
+	}
+
+	if v, ok := os.LookupEnv(Prefix + "_" + StorePathSuffix); ok {
+		cfg.Store.Path = v
+	}
+
+	if v, ok := os.LookupEnv(Prefix + "_" + AdminUserIDsSuffix); ok {
+		userIDs, err := parseUserIDs(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s_%s: %w", Prefix, AdminUserIDsSuffix, err)
+		}
+
+		cfg.Admin.UserIDs = userIDs
+	}
+
+	return nil
+}
+
+// parseUserIDs parses a comma-separated list of Telegram user IDs
+func parseUserIDs(value string) ([]int64, error) {
+	var userIDs []int64
+
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		userID, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, nil
+}
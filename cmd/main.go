@@ -8,8 +8,10 @@ import (
 
 	"github.com/peterbourgon/ff/v3/ffcli"
 
+	"github.com/sig-0/chigui-cifras/cmd/broadcast"
 	"github.com/sig-0/chigui-cifras/cmd/generate"
 	"github.com/sig-0/chigui-cifras/cmd/serve"
+	"github.com/sig-0/chigui-cifras/cmd/stats"
 )
 
 func main() {
@@ -27,6 +29,8 @@ func main() {
 	cmd.Subcommands = []*ffcli.Command{
 		serve.NewServeCmd(),
 		generate.NewGenerateCmd(),
+		broadcast.NewBroadcastCmd(),
+		stats.NewStatsCmd(),
 	}
 
 	if err := cmd.ParseAndRun(context.Background(), os.Args[1:]); err != nil {
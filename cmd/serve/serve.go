@@ -1,6 +1,7 @@
 package serve
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"flag"
@@ -14,14 +15,22 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/go-telegram/bot/models"
 	"github.com/joho/godotenv"
 	"github.com/peterbourgon/ff/v3/ffcli"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/sig-0/chigui-cifras/cmd/env"
+	"github.com/sig-0/chigui-cifras/internal/analytics"
 	"github.com/sig-0/chigui-cifras/internal/bot"
+	"github.com/sig-0/chigui-cifras/internal/calendar"
+	"github.com/sig-0/chigui-cifras/internal/clock"
 	"github.com/sig-0/chigui-cifras/internal/config"
+	"github.com/sig-0/chigui-cifras/internal/freshness"
 	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/metrics"
+	"github.com/sig-0/chigui-cifras/internal/preference"
+	"github.com/sig-0/chigui-cifras/internal/store"
 )
 
 // serveCfg wraps the serve configuration
@@ -90,7 +99,7 @@ func (c *serveCfg) exec(ctx context.Context, _ []string) error {
 		logger.Warn("unable to load .env file")
 	}
 
-	if err := applyEnv(c.config); err != nil {
+	if err := env.Apply(c.config); err != nil {
 		return err
 	}
 
@@ -101,14 +110,52 @@ func (c *serveCfg) exec(ctx context.Context, _ []string) error {
 	// Initialize fxrates client
 	fxClient := fxrates.NewClient(c.config.FXRates.BaseURL, c.config.FXRates.Timeout)
 
+	// Open the chat state store
+	chatStore, err := store.Open(c.config.Store.Path)
+	if err != nil {
+		return fmt.Errorf("unable to open store: %w", err)
+	}
+
+	// Persist the batched changes and release the store lock, after the rest is flushed
+	defer func() {
+		if err := chatStore.Close(); err != nil {
+			logger.Error("unable to close store", "error", err)
+		}
+	}()
+
+	if c.config.Store.Path == "" {
+		logger.Warn("no store path configured, chat settings will not persist across restarts")
+	}
+
+	settings, err := botSettings(c.config)
+	if err != nil {
+		return err
+	}
+
+	// Track the chosen inline results, exposed through the metrics endpoint
+	tracker := analytics.NewTracker(settings.Clock)
+
+	// Track how old the upstream rates are, exposed through the metrics and readiness endpoints
+	monitor := freshness.NewMonitor(freshnessPolicy(c.config.Freshness), settings.Clock)
+
+	registry := metrics.NewRegistry()
+	registry.Register(tracker)
+	registry.Register(monitor)
+
+	// Roll the usage up by day, persisted in the store
+	usageStats := analytics.NewAggregator(chatStore, c.config.Stats.RetentionDays)
+
+	settings.Analytics = tracker
+	settings.UsageStats = usageStats
+	settings.Reload = c.reloadSettings
+
 	// Initialize the Telegram bot
 	tgBot, err := bot.New(
 		c.config.Telegram.Token,
 		fxClient,
+		chatStore,
 		logger,
-		bot.Settings{
-			WebhookSecretToken: c.config.Telegram.WebhookSecretToken,
-		},
+		settings,
 	)
 	if err != nil {
 		return fmt.Errorf("unable to create bot: %w", err)
@@ -124,16 +171,244 @@ func (c *serveCfg) exec(ctx context.Context, _ []string) error {
 	)
 	defer cancelFn()
 
+	go usageStats.Run(runCtx, c.config.Stats.FlushInterval, func(err error) {
+		logger.Error("unable to flush usage statistics", "error", err)
+	})
+
+	// Keep the usage recorded since the last flush
+	defer func() {
+		if err := usageStats.Flush(settings.Clock.Now()); err != nil {
+			logger.Error("unable to flush usage statistics", "error", err)
+		}
+	}()
+
+	// Resume the broadcast interrupted by the last shutdown, if any
+	go func() {
+		if err := tgBot.ResumeBroadcast(runCtx); err != nil && !errors.Is(err, context.Canceled) {
+			logger.Error("unable to resume broadcast", "error", err)
+		}
+	}()
+
+	// Keep the channel posts and the pinned live rates up to date
+	go tgBot.RunChannelPosts(runCtx)
+	go tgBot.RunLiveRates(runCtx)
+
+	// Announce the new official rates to the opted-in chats
+	go tgBot.RunRateWatcher(runCtx)
+
+	// Track the freshness of the upstream rates, for the metrics endpoint and the readiness check
+	go monitor.Run(
+		runCtx,
+		c.config.Freshness.CheckInterval,
+		freshnessPairs(c.config.Freshness.Pairs),
+		func(ctx context.Context, pair freshness.Pair) ([]fxrates.ExchangeRate, error) {
+			rates, err := fxClient.Rate(ctx, pair.Base, pair.Target, "")
+			if err != nil {
+				return nil, err
+			}
+
+			return rates.Results, nil
+		},
+		func(err error) {
+			logger.Warn("unable to check rate freshness", "error", err)
+		},
+	)
+
 	if strings.TrimSpace(c.config.Telegram.WebhookURL) != "" {
-		return runWebhookMode(runCtx, tgBot, logger, c.config)
+		return runWebhookMode(runCtx, tgBot, registry, monitor, logger, c.config)
+	}
+
+	return runPollingMode(runCtx, tgBot, registry, monitor, logger, c.config)
+}
+
+// reloadSettings re-reads the server configuration, for the /reload admin command
+func (c *serveCfg) reloadSettings() (bot.Settings, error) {
+	cfg := config.DefaultConfig()
+
+	if c.configPath != "" {
+		serverCfg, err := config.Read(c.configPath)
+		if err != nil {
+			return bot.Settings{}, fmt.Errorf("unable to read server config, %w", err)
+		}
+
+		cfg = serverCfg
+	}
+
+	if err := env.Apply(cfg); err != nil {
+		return bot.Settings{}, err
 	}
 
-	return runPollingMode(runCtx, tgBot, logger, c.config)
+	if err := config.ValidateConfig(cfg); err != nil {
+		return bot.Settings{}, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return botSettings(cfg)
+}
+
+// botSettings maps the server configuration to the bot settings,
+// loading the holidays file, if any
+func botSettings(cfg *config.Config) (bot.Settings, error) {
+	bankingCalendar, err := calendar.New(nil)
+
+	if cfg.Calendar.HolidaysFile != "" {
+		bankingCalendar, err = calendar.Load(cfg.Calendar.HolidaysFile)
+	}
+
+	if err != nil {
+		return bot.Settings{}, fmt.Errorf("unable to load holidays, %w", err)
+	}
+
+	var (
+		policy      = freshnessPolicy(cfg.Freshness)
+		preferences = preferencePolicy(cfg.Preferences)
+	)
+
+	// The time zone was validated along with the configuration
+	location, err := time.LoadLocation(cfg.Telegram.TimeZone)
+	if err != nil {
+		return bot.Settings{}, fmt.Errorf("unable to load time zone, %w", err)
+	}
+
+	return bot.Settings{
+		WebhookSecretToken: cfg.Telegram.WebhookSecretToken,
+		ParseMode:          models.ParseMode(cfg.Telegram.ParseMode),
+		InlineCache:        inlineCacheSettings(cfg.Telegram.Inline),
+		CurrencyCacheTTL:   cfg.FXRates.CacheTTL,
+		Calendar:           bankingCalendar,
+		Freshness:          &policy,
+		Preferences:        &preferences,
+		TimeZone:           location,
+		Clock:              clock.System,
+		AdminUserIDs:       cfg.Admin.UserIDs,
+		BroadcastInterval:  cfg.Admin.BroadcastInterval,
+		ChannelPosts:       channelPosts(cfg.Channels),
+		Shortcuts:          shortcuts(cfg.Shortcuts),
+		LiveRatesInterval:  cfg.LiveRates.Interval,
+		WatchedPairs:       watchedPairs(cfg.Watcher.Pairs),
+		WatchInterval:      cfg.Watcher.Interval,
+	}, nil
+}
+
+// watchedPairs maps the configured new rate announcement pairs, already validated
+func watchedPairs(pairs []string) []bot.WatchedPair {
+	watched := make([]bot.WatchedPair, 0, len(pairs))
+
+	for _, pair := range pairs {
+		if base, target, ok := config.ParsePair(pair); ok {
+			watched = append(watched, bot.WatchedPair{
+				Base:   fxrates.Currency(base),
+				Target: fxrates.Currency(target),
+			})
+		}
+	}
+
+	return watched
+}
+
+// freshnessPolicy maps the configured staleness thresholds
+func freshnessPolicy(cfg config.FreshnessConfig) freshness.Policy {
+	sources := make(map[string]time.Duration, len(cfg.Sources))
+	for source, threshold := range cfg.Sources {
+		sources[strings.ToUpper(source)] = threshold
+	}
+
+	return freshness.Policy{
+		Sources: sources,
+		Default: cfg.Default,
+	}
+}
+
+// preferencePolicy maps the configured rate preferences, already validated
+func preferencePolicy(cfg config.PreferencesConfig) preference.Policy {
+	currencies := make(map[fxrates.Currency]preference.Rule, len(cfg.Currencies))
+	for currency, rule := range cfg.Currencies {
+		currencies[fxrates.Currency(strings.ToUpper(strings.TrimSpace(currency)))] = preferenceRule(rule)
+	}
+
+	return preference.Policy{
+		Currencies: currencies,
+		Default:    preferenceRule(cfg.Default),
+	}
+}
+
+// preferenceRule maps a configured currency rule, skipping the invalid preferences
+func preferenceRule(cfg config.PreferenceRuleConfig) preference.Rule {
+	rule := preference.Rule{
+		Source:      fxrates.Source(strings.ToUpper(cfg.Source)),
+		Preferences: make([]preference.Preference, 0, len(cfg.Prefer)),
+	}
+
+	for _, prefer := range cfg.Prefer {
+		if source, rateType, ok := config.ParsePreference(prefer); ok {
+			rule.Preferences = append(rule.Preferences, preference.Preference{
+				Source:   fxrates.Source(source),
+				RateType: fxrates.RateType(rateType),
+			})
+		}
+	}
+
+	return rule
+}
+
+// freshnessPairs maps the configured pairs tracked for readiness, already validated
+func freshnessPairs(pairs []string) []freshness.Pair {
+	tracked := make([]freshness.Pair, 0, len(pairs))
+
+	for _, pair := range pairs {
+		if base, target, ok := config.ParsePair(pair); ok {
+			tracked = append(tracked, freshness.Pair{Base: base, Target: target})
+		}
+	}
+
+	return tracked
+}
+
+// channelPosts maps the configured channel posts, filling in the defaults
+func channelPosts(channels []config.ChannelConfig) []bot.ChannelPost {
+	posts := make([]bot.ChannelPost, 0, len(channels))
+
+	for _, channel := range channels {
+		post := bot.ChannelPost{
+			Base:     fxrates.Currency(strings.ToUpper(channel.Base)),
+			Target:   fxrates.Currency(strings.ToUpper(cmp.Or(channel.Target, config.DefaultChannelTarget))),
+			Language: bot.Language(cmp.Or(channel.Language, config.DefaultChannelLanguage)),
+			ChatID:   channel.ChatID,
+			Interval: cmp.Or(channel.Interval, config.DefaultChannelInterval),
+			Pin:      channel.Pin,
+		}
+
+		posts = append(posts, post)
+	}
+
+	return posts
+}
+
+// shortcuts maps the configured shortcuts, already validated.
+// Without any, the bot uses its built-in ones
+func shortcuts(configured []config.ShortcutConfig) []bot.Shortcut {
+	if len(configured) == 0 {
+		return nil
+	}
+
+	mapped := make([]bot.Shortcut, 0, len(configured))
+
+	for _, shortcut := range configured {
+		mapped = append(mapped, bot.Shortcut{
+			Command: shortcut.Command,
+			Base:    fxrates.Currency(strings.ToUpper(shortcut.Base)),
+			Target:  fxrates.Currency(strings.ToUpper(shortcut.Target)),
+			Source:  fxrates.Source(strings.ToUpper(shortcut.Source)),
+		})
+	}
+
+	return mapped
 }
 
 func runWebhookMode(
 	ctx context.Context,
 	tgBot *bot.Bot,
+	registry *metrics.Registry,
+	monitor *freshness.Monitor,
 	logger *slog.Logger,
 	cfg *config.Config,
 ) error {
@@ -177,6 +452,8 @@ func runWebhookMode(
 	mux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
+	mux.Handle("/metrics", registry)
+	mux.Handle("/ready", monitor)
 
 	server := &http.Server{
 		Addr:              cfg.ListenAddress,
@@ -223,6 +500,8 @@ func runWebhookMode(
 func runPollingMode(
 	ctx context.Context,
 	tgBot *bot.Bot,
+	registry *metrics.Registry,
+	monitor *freshness.Monitor,
 	logger *slog.Logger,
 	cfg *config.Config,
 ) error {
@@ -232,12 +511,14 @@ func runPollingMode(
 		return fmt.Errorf("unable to delete webhook: %w", err)
 	}
 
-	// Set up a minimal HTTP server for the health endpoint,
+	// Set up a minimal HTTP server for the health, readiness and metrics endpoints,
 	// since the polling mode does not need an HTTP handler to operate
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
+	mux.Handle("/metrics", registry)
+	mux.Handle("/ready", monitor)
 
 	server := &http.Server{
 		Addr:              cfg.ListenAddress,
@@ -279,31 +560,20 @@ func runPollingMode(
 	return group.Wait()
 }
 
-func applyEnv(cfg *config.Config) error {
-	if v, ok := os.LookupEnv(env.Prefix + "_" + env.TelegramTokenSuffix); ok {
-		cfg.Telegram.Token = v
-	}
-
-	if v, ok := os.LookupEnv(env.Prefix + "_" + env.WebhookURLSuffix); ok {
-		cfg.Telegram.WebhookURL = v
-	}
-
-	if v, ok := os.LookupEnv(env.Prefix + "_" + env.WebhookSecretTokenSuffix); ok {
-		cfg.Telegram.WebhookSecretToken = v
-	}
-
-	if v, ok := os.LookupEnv(env.Prefix + "_" + env.FXRatesURLSuffix); ok {
-		cfg.FXRates.BaseURL = v
-	}
-
-	if v, ok := os.LookupEnv(env.Prefix + "_" + env.FXRatesTimeoutSuffix); ok {
-		timeout, err := time.ParseDuration(v)
-		if err != nil {
-			return fmt.Errorf("invalid %s_%s: %w", env.Prefix, env.FXRatesTimeoutSuffix, err)
+// inlineCacheSettings maps the inline caching config to the bot settings
+func inlineCacheSettings(inline config.InlineConfig) bot.InlineCacheSettings {
+	policy := func(cfg config.InlineCacheConfig) bot.InlineCachePolicy {
+		return bot.InlineCachePolicy{
+			CacheTime: cfg.CacheTime,
+			Personal:  cfg.Personal,
 		}
-
-		cfg.FXRates.Timeout = timeout
 	}
 
-	return nil
+	return bot.InlineCacheSettings{
+		Help:        policy(inline.Help),
+		Suggestions: policy(inline.Suggestions),
+		Rates:       policy(inline.Rates),
+		Listing:     policy(inline.Listing),
+		Errors:      policy(inline.Errors),
+	}
 }
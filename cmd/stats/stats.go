@@ -0,0 +1,149 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/peterbourgon/ff/v3/ffcli"
+
+	"github.com/sig-0/chigui-cifras/cmd/env"
+	"github.com/sig-0/chigui-cifras/internal/analytics"
+	"github.com/sig-0/chigui-cifras/internal/clock"
+	"github.com/sig-0/chigui-cifras/internal/config"
+	"github.com/sig-0/chigui-cifras/internal/store"
+)
+
+const defaultDays = 7
+
+var (
+	errMissingStorePath = errors.New("usage statistics are kept in the store, but no store path is set")
+	errInvalidDays      = errors.New("days must be positive")
+)
+
+// statsCfg wraps the stats configuration
+type statsCfg struct {
+	configPath string
+
+	days int
+}
+
+// NewStatsCmd creates the stats subcommand
+func NewStatsCmd() *ffcli.Command {
+	cfg := &statsCfg{}
+
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	cfg.registerFlags(fs)
+
+	return &ffcli.Command{
+		Name:       "stats",
+		ShortUsage: "stats [flags]",
+		LongHelp:   "Prints the daily usage statistics kept in the store",
+		FlagSet:    fs,
+		Exec:       cfg.exec,
+	}
+}
+
+func (c *statsCfg) registerFlags(fs *flag.FlagSet) {
+	fs.StringVar(
+		&c.configPath,
+		"config",
+		"",
+		"the path to the server TOML configuration, if any",
+	)
+
+	fs.IntVar(
+		&c.days,
+		"days",
+		defaultDays,
+		"the number of days to summarize, including today",
+	)
+}
+
+func (c *statsCfg) exec(_ context.Context, _ []string) error {
+	if c.days <= 0 {
+		return errInvalidDays
+	}
+
+	cfg := config.DefaultConfig()
+
+	// Read the server configuration, if any
+	if c.configPath != "" {
+		serverCfg, err := config.Read(c.configPath)
+		if err != nil {
+			return fmt.Errorf("unable to read server config, %w", err)
+		}
+
+		cfg = serverCfg
+	}
+
+	// Load .env, if any. Only the store path is needed
+	_ = godotenv.Load()
+
+	if err := env.Apply(cfg); err != nil {
+		return err
+	}
+
+	if cfg.Store.Path == "" {
+		return errMissingStorePath
+	}
+
+	// The bot may have the store open, so it's only read
+	chatStore, err := store.OpenReadOnly(cfg.Store.Path)
+	if err != nil {
+		return fmt.Errorf("unable to open store: %w", err)
+	}
+
+	summary := analytics.NewAggregator(chatStore, cfg.Stats.RetentionDays).Summary(clock.System.Now(), c.days)
+
+	return printSummary(os.Stdout, summary)
+}
+
+// printSummary writes the usage summary as plain text
+func printSummary(w io.Writer, summary analytics.Summary) error {
+	lines := []string{
+		fmt.Sprintf("Usage statistics for the last %d days", summary.Days),
+		"",
+		fmt.Sprintf("Active chats: %d in total, %.1f per day", summary.ActiveChats, summary.AverageActiveChats()),
+	}
+
+	if summary.Busiest.Count > 0 {
+		lines = append(lines, fmt.Sprintf("Busiest day: %s (%d chats)", summary.Busiest.Key, summary.Busiest.Count))
+	}
+
+	lines = append(lines, fmt.Sprintf("Updates: %d", summary.Updates))
+
+	sections := []struct {
+		title  string
+		counts []analytics.Count
+	}{
+		{title: "Commands", counts: summary.Commands},
+		{title: "Pairs", counts: summary.Pairs},
+		{title: "Languages", counts: summary.Languages},
+		{title: "Chat types", counts: summary.ChatTypes},
+	}
+
+	for _, section := range sections {
+		if len(section.counts) == 0 {
+			continue
+		}
+
+		lines = append(lines, "", section.title+":")
+
+		for _, count := range section.counts {
+			lines = append(lines, fmt.Sprintf("  %s: %d", count.Key, count.Count))
+		}
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,280 @@
+package analytics
+
+import (
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sig-0/chigui-cifras/internal/clock"
+	"github.com/sig-0/chigui-cifras/internal/metrics"
+)
+
+const (
+	// inlineChosenMetric is the metric counting the chosen inline results
+	inlineChosenMetric = "chigui_inline_chosen_total"
+
+	// commandsMetric is the metric counting the handled commands
+	commandsMetric = "chigui_commands_total"
+
+	// inlineQueriesMetric is the metric counting the received inline queries
+	inlineQueriesMetric = "chigui_inline_queries_total"
+
+	inlineChosenHelp  = "Inline results chosen by users, by pair and language"
+	commandsHelp      = "Commands handled, by command"
+	inlineQueriesHelp = "Inline queries received"
+)
+
+// InlineChoice is an inline result a user picked to share in a chat
+type InlineChoice struct {
+	ChosenAt time.Time
+	Base     string
+	Target   string
+	Language string
+}
+
+// Sink records analytics events
+type Sink interface {
+	// RecordInlineChoice records a chosen inline result
+	RecordInlineChoice(choice InlineChoice)
+
+	// RecordCommand records a handled command
+	RecordCommand(command string)
+
+	// RecordInlineQuery records a received inline query
+	RecordInlineQuery()
+}
+
+// Usage holds the usage counters since the tracker started
+type Usage struct {
+	StartedAt time.Time
+
+	// Commands are the handled commands, by command
+	Commands map[string]int
+
+	InlineQueries int
+	InlineChosen  int
+}
+
+// PairPopularity is how many times a pair was chosen in inline mode
+type PairPopularity struct {
+	LastChosenAt time.Time
+	Base         string
+	Target       string
+	Count        int
+}
+
+// choiceKey identifies the aggregated choices of a pair in a language
+type choiceKey struct {
+	base     string
+	target   string
+	language string
+}
+
+// choiceStats holds the aggregated choices of a pair in a language
+type choiceStats struct {
+	lastChosenAt time.Time
+	count        int
+}
+
+// Tracker is an in-memory sink that aggregates the usage counters
+// and the chosen inline results
+type Tracker struct {
+	startedAt     time.Time
+	choices       map[choiceKey]*choiceStats
+	commands      map[string]int
+	inlineQueries int
+	mux           sync.RWMutex
+}
+
+// NewTracker creates a new, empty tracker, started at the current time of the clock.
+// If the clock is nil, the system clock is used
+func NewTracker(clk clock.Clock) *Tracker {
+	return &Tracker{
+		startedAt: clock.Or(clk).Now(),
+		choices:   make(map[choiceKey]*choiceStats),
+		commands:  make(map[string]int),
+	}
+}
+
+// RecordCommand records a handled command
+func (t *Tracker) RecordCommand(command string) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	t.commands[command]++
+}
+
+// RecordInlineQuery records a received inline query
+func (t *Tracker) RecordInlineQuery() {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	t.inlineQueries++
+}
+
+// Usage returns a snapshot of the usage counters
+func (t *Tracker) Usage() Usage {
+	t.mux.RLock()
+	defer t.mux.RUnlock()
+
+	usage := Usage{
+		StartedAt:     t.startedAt,
+		Commands:      make(map[string]int, len(t.commands)),
+		InlineQueries: t.inlineQueries,
+	}
+
+	for command, count := range t.commands {
+		usage.Commands[command] = count
+	}
+
+	for _, stats := range t.choices {
+		usage.InlineChosen += stats.count
+	}
+
+	return usage
+}
+
+// RecordInlineChoice records a chosen inline result
+func (t *Tracker) RecordInlineChoice(choice InlineChoice) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	key := choiceKey{
+		base:     choice.Base,
+		target:   choice.Target,
+		language: choice.Language,
+	}
+
+	stats, ok := t.choices[key]
+	if !ok {
+		stats = &choiceStats{}
+		t.choices[key] = stats
+	}
+
+	stats.count++
+
+	if choice.ChosenAt.After(stats.lastChosenAt) {
+		stats.lastChosenAt = choice.ChosenAt
+	}
+}
+
+// Popular returns the most chosen pairs across every language, the most popular first.
+// A non-positive limit returns every pair
+func (t *Tracker) Popular(limit int) []PairPopularity {
+	t.mux.RLock()
+
+	byPair := make(map[[2]string]*PairPopularity)
+
+	for key, stats := range t.choices {
+		pair := [2]string{key.base, key.target}
+
+		popularity, ok := byPair[pair]
+		if !ok {
+			popularity = &PairPopularity{
+				Base:   key.base,
+				Target: key.target,
+			}
+			byPair[pair] = popularity
+		}
+
+		popularity.Count += stats.count
+
+		if stats.lastChosenAt.After(popularity.LastChosenAt) {
+			popularity.LastChosenAt = stats.lastChosenAt
+		}
+	}
+
+	t.mux.RUnlock()
+
+	popular := make([]PairPopularity, 0, len(byPair))
+	for _, popularity := range byPair {
+		popular = append(popular, *popularity)
+	}
+
+	sort.Slice(popular, func(i, j int) bool {
+		if popular[i].Count != popular[j].Count {
+			return popular[i].Count > popular[j].Count
+		}
+
+		if popular[i].Base != popular[j].Base {
+			return popular[i].Base < popular[j].Base
+		}
+
+		return popular[i].Target < popular[j].Target
+	})
+
+	if limit > 0 && len(popular) > limit {
+		popular = popular[:limit]
+	}
+
+	return popular
+}
+
+// WriteMetrics writes the usage counters and the chosen inline results, by pair and language
+func (t *Tracker) WriteMetrics(w io.Writer) error {
+	t.mux.RLock()
+	defer t.mux.RUnlock()
+
+	if err := metrics.WriteHeader(w, commandsMetric, commandsHelp, metrics.Counter); err != nil {
+		return err
+	}
+
+	commands := make([]string, 0, len(t.commands))
+	for command := range t.commands {
+		commands = append(commands, command)
+	}
+
+	sort.Strings(commands)
+
+	for _, command := range commands {
+		labels := map[string]string{"command": command}
+
+		if err := metrics.WriteSample(w, commandsMetric, labels, float64(t.commands[command])); err != nil {
+			return err
+		}
+	}
+
+	if err := metrics.WriteHeader(w, inlineQueriesMetric, inlineQueriesHelp, metrics.Counter); err != nil {
+		return err
+	}
+
+	if err := metrics.WriteSample(w, inlineQueriesMetric, nil, float64(t.inlineQueries)); err != nil {
+		return err
+	}
+
+	keys := make([]choiceKey, 0, len(t.choices))
+	for key := range t.choices {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].base != keys[j].base {
+			return keys[i].base < keys[j].base
+		}
+
+		if keys[i].target != keys[j].target {
+			return keys[i].target < keys[j].target
+		}
+
+		return keys[i].language < keys[j].language
+	})
+
+	if err := metrics.WriteHeader(w, inlineChosenMetric, inlineChosenHelp, metrics.Counter); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		labels := map[string]string{
+			"base":     key.base,
+			"target":   key.target,
+			"language": key.language,
+		}
+
+		if err := metrics.WriteSample(w, inlineChosenMetric, labels, float64(t.choices[key].count)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package analytics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker_Popular(t *testing.T) {
+	t.Parallel()
+
+	var (
+		tracker = NewTracker(nil)
+		start   = time.Date(2026, time.January, 2, 15, 4, 0, 0, time.UTC)
+	)
+
+	choices := []InlineChoice{
+		{Base: "USD", Target: "VES", Language: "es", ChosenAt: start},
+		{Base: "USD", Target: "VES", Language: "en", ChosenAt: start.Add(2 * time.Minute)},
+		{Base: "USD", Target: "VES", Language: "es", ChosenAt: start.Add(time.Minute)},
+		{Base: "EUR", Target: "VES", Language: "es", ChosenAt: start},
+		{Base: "USDT", Target: "VES", Language: "pt", ChosenAt: start},
+	}
+
+	for _, choice := range choices {
+		tracker.RecordInlineChoice(choice)
+	}
+
+	popular := tracker.Popular(0)
+	require.Len(t, popular, 3)
+
+	// Languages are merged, and ties are sorted by pair
+	assert.Equal(t, PairPopularity{
+		Base:         "USD",
+		Target:       "VES",
+		Count:        3,
+		LastChosenAt: start.Add(2 * time.Minute),
+	}, popular[0])
+	assert.Equal(t, "EUR", popular[1].Base)
+	assert.Equal(t, "USDT", popular[2].Base)
+
+	assert.Len(t, tracker.Popular(2), 2)
+}
+
+func TestTracker_WriteMetrics(t *testing.T) {
+	t.Parallel()
+
+	tracker := NewTracker(nil)
+
+	tracker.RecordInlineChoice(InlineChoice{Base: "USD", Target: "VES", Language: "es"})
+	tracker.RecordInlineChoice(InlineChoice{Base: "USD", Target: "VES", Language: "es"})
+	tracker.RecordInlineChoice(InlineChoice{Base: "EUR", Target: "VES", Language: "en"})
+
+	tracker.RecordCommand("/tasa")
+	tracker.RecordInlineQuery()
+
+	var sb strings.Builder
+
+	require.NoError(t, tracker.WriteMetrics(&sb))
+
+	expected := "# HELP chigui_commands_total Commands handled, by command\n" +
+		"# TYPE chigui_commands_total counter\n" +
+		"chigui_commands_total{command=\"/tasa\"} 1\n" +
+		"# HELP chigui_inline_queries_total Inline queries received\n" +
+		"# TYPE chigui_inline_queries_total counter\n" +
+		"chigui_inline_queries_total 1\n" +
+		"# HELP chigui_inline_chosen_total Inline results chosen by users, by pair and language\n" +
+		"# TYPE chigui_inline_chosen_total counter\n" +
+		"chigui_inline_chosen_total{base=\"EUR\",language=\"en\",target=\"VES\"} 1\n" +
+		"chigui_inline_chosen_total{base=\"USD\",language=\"es\",target=\"VES\"} 2\n"
+
+	assert.Equal(t, expected, sb.String())
+}
+
+func TestTracker_Usage(t *testing.T) {
+	t.Parallel()
+
+	tracker := NewTracker(nil)
+
+	tracker.RecordCommand("/tasa")
+	tracker.RecordCommand("/tasa")
+	tracker.RecordCommand("/dolar")
+	tracker.RecordInlineQuery()
+	tracker.RecordInlineChoice(InlineChoice{Base: "USD", Target: "VES", Language: "es"})
+
+	usage := tracker.Usage()
+
+	assert.Equal(t, map[string]int{"/tasa": 2, "/dolar": 1}, usage.Commands)
+	assert.Equal(t, 1, usage.InlineQueries)
+	assert.Equal(t, 1, usage.InlineChosen)
+	assert.False(t, usage.StartedAt.IsZero())
+
+	// The snapshot is a copy
+	usage.Commands["/tasa"] = 10
+	assert.Equal(t, 2, tracker.Usage().Commands["/tasa"])
+}
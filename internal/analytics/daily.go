@@ -0,0 +1,276 @@
+package analytics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sig-0/chigui-cifras/internal/clock"
+	"github.com/sig-0/chigui-cifras/internal/store"
+)
+
+const (
+	// DefaultRetentionDays is how many days of usage counters are kept
+	DefaultRetentionDays = 90
+
+	// dayLayout is the format of the daily rollup keys
+	dayLayout = "2006-01-02"
+)
+
+// Event is a single usage event. Empty fields aren't counted,
+// so pairs can be recorded apart from the update that requested them
+type Event struct {
+	At time.Time
+
+	// Command is the handled command, if any
+	Command string
+
+	// Base and Target are the requested pair, if any
+	Base   string
+	Target string
+
+	// Language is the response language of the update, if any
+	Language string
+
+	// ChatType is the Telegram chat type of the update, if any
+	ChatType string
+
+	// ChatID is the chat the update came from, if any
+	ChatID int64
+
+	// Update marks the event as a received update
+	Update bool
+}
+
+// Aggregator rolls the usage events up by day, in memory,
+// until they're flushed to the store
+type Aggregator struct {
+	store         *store.Store
+	location      *time.Location
+	pending       map[string]*store.DailyUsage
+	retentionDays int
+	mux           sync.Mutex
+}
+
+// NewAggregator creates a new aggregator, keeping the given days of counters.
+// If the retention is zero, DefaultRetentionDays is used
+func NewAggregator(chatStore *store.Store, retentionDays int) *Aggregator {
+	if retentionDays <= 0 {
+		retentionDays = DefaultRetentionDays
+	}
+
+	return &Aggregator{
+		store:         chatStore,
+		location:      clock.Caracas,
+		pending:       make(map[string]*store.DailyUsage),
+		retentionDays: retentionDays,
+	}
+}
+
+// Record adds the event to the day it happened
+func (a *Aggregator) Record(event Event) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	day := a.day(event.At)
+
+	usage, ok := a.pending[day]
+	if !ok {
+		usage = &store.DailyUsage{Day: day}
+		a.pending[day] = usage
+	}
+
+	delta := store.DailyUsage{}
+
+	if event.Update {
+		delta.Updates = 1
+	}
+
+	if event.ChatID != 0 {
+		delta.Chats = map[int64]int{event.ChatID: 1}
+	}
+
+	if event.Command != "" {
+		delta.Commands = map[string]int{event.Command: 1}
+	}
+
+	if event.Base != "" && event.Target != "" {
+		delta.Pairs = map[string]int{event.Base + "/" + event.Target: 1}
+	}
+
+	if event.Language != "" {
+		delta.Languages = map[string]int{event.Language: 1}
+	}
+
+	if event.ChatType != "" {
+		delta.ChatTypes = map[string]int{event.ChatType: 1}
+	}
+
+	usage.Merge(delta)
+}
+
+// Flush writes the pending counters to the store, dropping the days past the retention
+func (a *Aggregator) Flush(now time.Time) error {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	usage := make([]store.DailyUsage, 0, len(a.pending))
+	for _, day := range a.pending {
+		usage = append(usage, *day)
+	}
+
+	if err := a.store.AddUsage(usage, a.since(now, a.retentionDays)); err != nil {
+		// Keep the counters, to retry on the next flush
+		return err
+	}
+
+	a.pending = make(map[string]*store.DailyUsage)
+
+	return nil
+}
+
+// Run flushes the pending counters every interval, until the context is done.
+// The caller is expected to flush once more on shutdown
+func (a *Aggregator) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := a.Flush(now); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Summary summarizes the counters of the last given days, including today,
+// along with the ones not flushed yet
+func (a *Aggregator) Summary(now time.Time, days int) Summary {
+	since := a.since(now, days)
+	usage := a.store.Usage(since)
+
+	a.mux.Lock()
+
+	for _, day := range a.pending {
+		if day.Day >= since {
+			usage = append(usage, *day)
+		}
+	}
+
+	a.mux.Unlock()
+
+	return Summarize(usage, days)
+}
+
+// day returns the rollup key of the time
+func (a *Aggregator) day(at time.Time) string {
+	return at.In(a.location).Format(dayLayout)
+}
+
+// since returns the rollup key of the first of the last given days, including today
+func (a *Aggregator) since(now time.Time, days int) string {
+	return a.day(now.AddDate(0, 0, -(days - 1)))
+}
+
+// Count is a counter of a single key
+type Count struct {
+	Key   string
+	Count int
+}
+
+// Summary aggregates the daily usage counters over a period
+type Summary struct {
+	// Busiest is the day with the most active chats
+	Busiest Count
+
+	Commands  []Count
+	Pairs     []Count
+	Languages []Count
+	ChatTypes []Count
+
+	// Days is the length of the period, including the days without usage
+	Days int
+
+	// ActiveChats is the number of distinct chats active in the period
+	ActiveChats int
+
+	// DailyActiveChats is the sum of the daily active chats over the period
+	DailyActiveChats int
+
+	Updates int
+}
+
+// AverageActiveChats returns the average of daily active chats over the period
+func (s Summary) AverageActiveChats() float64 {
+	if s.Days == 0 {
+		return 0
+	}
+
+	return float64(s.DailyActiveChats) / float64(s.Days)
+}
+
+// Summarize aggregates the daily usage counters over a period of the given days.
+// Counters are sorted by count, the highest first
+func Summarize(usage []store.DailyUsage, days int) Summary {
+	var (
+		summary = Summary{Days: days}
+		total   store.DailyUsage
+		byDay   = make(map[string]*store.DailyUsage, len(usage))
+	)
+
+	// The same day may be both stored and pending
+	for _, day := range usage {
+		total.Merge(day)
+
+		merged, ok := byDay[day.Day]
+		if !ok {
+			merged = &store.DailyUsage{Day: day.Day}
+			byDay[day.Day] = merged
+		}
+
+		merged.Merge(day)
+	}
+
+	for _, day := range byDay {
+		summary.DailyActiveChats += len(day.Chats)
+
+		busier := len(day.Chats) > summary.Busiest.Count ||
+			(len(day.Chats) == summary.Busiest.Count && day.Day > summary.Busiest.Key)
+
+		if len(day.Chats) > 0 && busier {
+			summary.Busiest = Count{Key: day.Day, Count: len(day.Chats)}
+		}
+	}
+
+	summary.ActiveChats = len(total.Chats)
+	summary.Updates = total.Updates
+	summary.Commands = sortedCounts(total.Commands)
+	summary.Pairs = sortedCounts(total.Pairs)
+	summary.Languages = sortedCounts(total.Languages)
+	summary.ChatTypes = sortedCounts(total.ChatTypes)
+
+	return summary
+}
+
+// sortedCounts returns the counters sorted by count, ties sorted by key
+func sortedCounts(counts map[string]int) []Count {
+	sorted := make([]Count, 0, len(counts))
+	for key, count := range counts {
+		sorted = append(sorted, Count{Key: key, Count: count})
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+
+		return sorted[i].Key < sorted[j].Key
+	})
+
+	return sorted
+}
@@ -0,0 +1,111 @@
+package analytics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sig-0/chigui-cifras/internal/store"
+)
+
+func TestAggregator_Summary(t *testing.T) {
+	t.Parallel()
+
+	var (
+		chatStore  = store.NewMemory()
+		aggregator = NewAggregator(chatStore, 0)
+
+		// Late in the day in Caracas, but already the next day in UTC
+		today     = time.Date(2026, time.January, 3, 1, 0, 0, 0, time.UTC)
+		yesterday = today.Add(-24 * time.Hour)
+	)
+
+	aggregator.Record(Event{
+		At:       yesterday,
+		Command:  "/tasa",
+		Language: "es",
+		ChatType: "private",
+		ChatID:   1,
+		Update:   true,
+	})
+	aggregator.Record(Event{At: yesterday, Base: "USD", Target: "VES"})
+
+	require.NoError(t, aggregator.Flush(today))
+
+	// Not flushed yet, on the same day
+	aggregator.Record(Event{At: today, Command: "/dolar", Language: "en", ChatType: "group", ChatID: 2, Update: true})
+	aggregator.Record(Event{At: today, Base: "USD", Target: "VES"})
+	aggregator.Record(Event{At: today, Command: "/tasa", Language: "es", ChatType: "private", ChatID: 1, Update: true})
+
+	usage := chatStore.Usage("")
+	require.Len(t, usage, 1)
+	assert.Equal(t, "2026-01-01", usage[0].Day)
+
+	summary := aggregator.Summary(today, 7)
+
+	assert.Equal(t, 7, summary.Days)
+	assert.Equal(t, 3, summary.Updates)
+	assert.Equal(t, 2, summary.ActiveChats)
+	assert.Equal(t, 3, summary.DailyActiveChats)
+	assert.InDelta(t, 3.0/7, summary.AverageActiveChats(), 0.001)
+	assert.Equal(t, Count{Key: "2026-01-02", Count: 2}, summary.Busiest)
+
+	assert.Equal(t, []Count{{Key: "/tasa", Count: 2}, {Key: "/dolar", Count: 1}}, summary.Commands)
+	assert.Equal(t, []Count{{Key: "USD/VES", Count: 2}}, summary.Pairs)
+	assert.Equal(t, []Count{{Key: "es", Count: 2}, {Key: "en", Count: 1}}, summary.Languages)
+	assert.Equal(t, []Count{{Key: "private", Count: 2}, {Key: "group", Count: 1}}, summary.ChatTypes)
+
+	// Only today
+	assert.Equal(t, 2, aggregator.Summary(today, 1).ActiveChats)
+}
+
+func TestAggregator_Retention(t *testing.T) {
+	t.Parallel()
+
+	var (
+		chatStore  = store.NewMemory()
+		aggregator = NewAggregator(chatStore, 2)
+		now        = time.Date(2026, time.January, 10, 12, 0, 0, 0, time.UTC)
+	)
+
+	for days := range 4 {
+		aggregator.Record(Event{At: now.AddDate(0, 0, -days), ChatID: 1, Update: true})
+	}
+
+	require.NoError(t, aggregator.Flush(now))
+
+	usage := chatStore.Usage("")
+	require.Len(t, usage, 2)
+
+	assert.Equal(t, "2026-01-09", usage[0].Day)
+	assert.Equal(t, "2026-01-10", usage[1].Day)
+}
+
+func TestAggregator_FlushRetries(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "store")
+	require.NoError(t, os.Mkdir(dir, 0o700))
+
+	chatStore, err := store.Open(filepath.Join(dir, "store.json"))
+	require.NoError(t, err)
+
+	// The store can't be persisted once its directory is missing
+	require.NoError(t, os.RemoveAll(dir))
+
+	var (
+		aggregator = NewAggregator(chatStore, 0)
+		now        = time.Date(2026, time.January, 10, 12, 0, 0, 0, time.UTC)
+	)
+
+	aggregator.Record(Event{At: now, ChatID: 1, Update: true})
+
+	require.Error(t, aggregator.Flush(now))
+
+	// The counters are kept for the next flush
+	assert.Equal(t, 1, aggregator.Summary(now, 1).Updates)
+}
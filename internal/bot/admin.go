@@ -0,0 +1,273 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/sig-0/chigui-cifras/internal/broadcast"
+	"github.com/sig-0/chigui-cifras/internal/i18n"
+	"github.com/sig-0/chigui-cifras/internal/store"
+)
+
+const (
+	// maxPopularPairs is the number of pairs listed by the /popular admin command
+	maxPopularPairs = 10
+
+	// defaultUsageStatsDays is the period summarized by the /estadisticas admin command
+	defaultUsageStatsDays = 7
+
+	// maxUsageStatsDays is the longest period the /estadisticas admin command summarizes
+	maxUsageStatsDays = 366
+)
+
+// Popular handles the /popular admin command,
+// listing the pairs most shared through inline mode
+func (h *FxHandler) Popular(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if !h.isAdmin(update) {
+		return
+	}
+
+	h.reply(ctx, b, update, PopularPairsMessage(h.tracker.Popular(maxPopularPairs), h.commandLocale(update)))
+}
+
+// Stats handles the /stats admin command, showing the usage counters
+func (h *FxHandler) Stats(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if !h.isAdmin(update) {
+		return
+	}
+
+	chats := 0
+	if h.store != nil {
+		chats = len(h.store.Chats())
+	}
+
+	h.reply(ctx, b, update, StatsMessage(h.tracker.Usage(), chats, h.commandLocale(update)))
+}
+
+// UsageStats handles the /estadisticas admin command,
+// summarizing the daily usage of the last days
+func (h *FxHandler) UsageStats(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if !h.isAdmin(update) {
+		return
+	}
+
+	loc := h.commandLocale(update)
+
+	if h.stats == nil {
+		h.reply(ctx, b, update, loc.text("admin.usage.disabled", nil))
+
+		return
+	}
+
+	days := defaultUsageStatsDays
+
+	if args := h.parseArgs(updateMessage(update).Text); len(args) > 0 {
+		parsed, err := strconv.Atoi(args[0])
+		if err != nil || parsed < 1 || parsed > maxUsageStatsDays {
+			h.reply(ctx, b, update, InvalidUsageMessage(translate(loc.Language, "usage.usage_stats", nil), loc))
+
+			return
+		}
+
+		days = parsed
+	}
+
+	h.reply(ctx, b, update, UsageStatsMessage(h.stats.Summary(h.clock.Now(), days), loc))
+}
+
+// Upstream handles the /upstream admin command,
+// showing the fxrates API health and the client circuit breaker state
+func (h *FxHandler) Upstream(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if !h.isAdmin(update) {
+		return
+	}
+
+	loc := h.commandLocale(update)
+
+	if h.fxClient == nil {
+		h.reply(ctx, b, update, ErrorMessage(errFXClientNotConfigured, loc))
+
+		return
+	}
+
+	start := h.clock.Now()
+	healthErr := h.fxClient.Health(ctx)
+	latency := h.clock.Now().Sub(start)
+
+	h.reply(ctx, b, update, UpstreamMessage(healthErr, latency, h.fxClient.Circuit(), loc))
+}
+
+// Broadcast handles the /broadcast admin command, sending the given text
+// to every known chat. It also supports a dry run, reporting the progress
+// of the latest broadcast, and resuming an interrupted one
+func (h *FxHandler) Broadcast(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if !h.isAdmin(update) {
+		return
+	}
+
+	loc := h.commandLocale(update)
+
+	argument := commandArgument(updateMessage(update).Text)
+	if argument == "" || h.broadcaster == nil {
+		h.reply(ctx, b, update, InvalidUsageMessage(translate(loc.Language, "usage.broadcast", nil), loc))
+
+		return
+	}
+
+	action, text := splitBroadcastArgument(argument)
+
+	switch action {
+	case broadcastStatus:
+		report, ok := h.broadcaster.Status()
+		h.reply(ctx, b, update, BroadcastStatusMessage(report, ok, loc))
+	case broadcastDryRun:
+		if text == "" {
+			h.reply(ctx, b, update, InvalidUsageMessage(translate(loc.Language, "usage.broadcast", nil), loc))
+
+			return
+		}
+
+		h.reply(ctx, b, update, BroadcastDryRunMessage(h.broadcaster.DryRun(), loc))
+	case broadcastResume:
+		if !h.broadcaster.Pending() {
+			report, ok := h.broadcaster.Status()
+			h.reply(ctx, b, update, BroadcastStatusMessage(report, ok, loc))
+
+			return
+		}
+
+		h.runBroadcast(ctx, b, update, loc)
+	default:
+		report, err := h.broadcaster.Start(argument)
+		if errors.Is(err, store.ErrBroadcastInProgress) {
+			h.reply(ctx, b, update, loc.text("admin.broadcast.in_progress", nil))
+
+			return
+		}
+
+		if err != nil {
+			h.logger.Error("unable to start broadcast", "error", err)
+			h.reply(ctx, b, update, ErrorMessage(err, loc))
+
+			return
+		}
+
+		h.reply(ctx, b, update, BroadcastStartedMessage(report, loc))
+		h.runBroadcast(ctx, b, update, loc)
+	}
+}
+
+// runBroadcast delivers the unfinished broadcast in the background,
+// and reports the outcome to the admin once it's done
+func (h *FxHandler) runBroadcast(ctx context.Context, b *bot.Bot, update *models.Update, loc Locale) {
+	// Sending takes a while, so don't hold up the admin's other commands
+	go func() {
+		report, err := h.broadcaster.Run(ctx, broadcast.NewTelegramSender(b))
+
+		switch {
+		case errors.Is(err, broadcast.ErrRunning):
+			h.reply(ctx, b, update, loc.text("admin.broadcast.in_progress", nil))
+		case err != nil:
+			h.reply(ctx, b, update, BroadcastInterruptedMessage(report, err, loc))
+		default:
+			h.reply(ctx, b, update, BroadcastDoneMessage(report, loc))
+		}
+	}()
+}
+
+// broadcastAction is an admin /broadcast subcommand
+type broadcastAction string
+
+const (
+	broadcastSend   broadcastAction = ""
+	broadcastStatus broadcastAction = "status"
+	broadcastDryRun broadcastAction = "dry-run"
+	broadcastResume broadcastAction = "resume"
+)
+
+// splitBroadcastArgument splits the /broadcast argument into the subcommand,
+// if any, and the text to send
+func splitBroadcastArgument(argument string) (broadcastAction, string) {
+	first := strings.Fields(argument)[0]
+
+	switch action := broadcastAction(strings.ToLower(first)); action {
+	case broadcastStatus, broadcastDryRun, broadcastResume:
+		return action, strings.TrimSpace(strings.TrimPrefix(argument, first))
+	default:
+		return broadcastSend, argument
+	}
+}
+
+// Reload handles the /reload admin command, re-reading the configuration
+// and applying the settings that can change while running
+func (h *FxHandler) Reload(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if !h.isAdmin(update) {
+		return
+	}
+
+	loc := h.commandLocale(update)
+
+	if h.reload == nil {
+		h.reply(ctx, b, update, loc.text("admin.reload.disabled", nil))
+
+		return
+	}
+
+	settings, err := h.reload()
+	if err != nil {
+		h.logger.Error("unable to reload configuration", "error", err)
+		h.reply(ctx, b, update, loc.text("admin.reload.failed", i18n.Params{"error": err}))
+
+		return
+	}
+
+	runtime, err := newRuntimeSettings(settings)
+	if err != nil {
+		h.logger.Error("invalid reloaded configuration", "error", err)
+		h.reply(ctx, b, update, loc.text("admin.reload.failed", i18n.Params{"error": err}))
+
+		return
+	}
+
+	h.runtime.Store(runtime)
+	h.resolver.setTTL(settings.CurrencyCacheTTL)
+
+	h.logger.Info("configuration reloaded", "user_id", updateMessage(update).From.ID)
+
+	// Reply with the reloaded settings, in case the parse mode changed
+	h.reply(ctx, b, update, h.commandLocale(update).text("admin.reload.done", nil))
+}
+
+// commandArgument returns the raw text following the command,
+// preserving its line breaks
+func commandArgument(text string) string {
+	end := strings.IndexFunc(text, unicode.IsSpace)
+	if end == -1 {
+		return ""
+	}
+
+	return strings.TrimSpace(text[end:])
+}
+
+// isAdmin checks if the message comes from a configured operator
+func (h *FxHandler) isAdmin(update *models.Update) bool {
+	// Channel posts have no sender, so they can't run admin commands
+	message := updateMessage(update)
+	if message == nil || message.From == nil {
+		return false
+	}
+
+	if _, ok := h.settings().admins[message.From.ID]; !ok {
+		h.logger.Debug("ignoring admin command from non-admin", "user_id", message.From.ID)
+
+		return false
+	}
+
+	return true
+}
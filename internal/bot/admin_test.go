@@ -0,0 +1,411 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sig-0/chigui-cifras/internal/analytics"
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/store"
+)
+
+const testAdminID int64 = 42
+
+func TestAdmin_Popular(t *testing.T) {
+	t.Parallel()
+
+	tracker := analytics.NewTracker(nil)
+	tracker.RecordInlineChoice(analytics.InlineChoice{
+		ChosenAt: time.Date(2026, time.January, 2, 15, 4, 0, 0, time.UTC),
+		Base:     "USD",
+		Target:   "VES",
+		Language: "es",
+	})
+
+	h := newTestHandler(t, nil, store.NewMemory(), Settings{AdminUserIDs: []int64{testAdminID}, Analytics: tracker})
+
+	srv, messages := newMessageServer(t)
+	b := newTelegramBot(t, srv.URL)
+
+	h.Popular(context.Background(), b, commandUpdate(testAdminID, "/popular"))
+
+	select {
+	case message := <-messages:
+		assert.Equal(t, testAdminID, message.ChatID)
+		assert.Contains(t, message.Text, "USD/VES: 1 vez")
+	default:
+		t.Fatal("no reply sent to the admin")
+	}
+}
+
+func TestAdmin_IgnoresNonAdmins(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHandler(t, nil, store.NewMemory(), Settings{AdminUserIDs: []int64{testAdminID}})
+
+	srv, messages := newMessageServer(t)
+	b := newTelegramBot(t, srv.URL)
+
+	h.Popular(context.Background(), b, commandUpdate(7, "/popular"))
+
+	// Handlers reply synchronously, so any reply would already be recorded
+	select {
+	case message := <-messages:
+		t.Fatalf("unexpected reply to a non-admin: %q", message.Text)
+	default:
+	}
+}
+
+// receiveMessage waits for the next sent message, for handlers that reply asynchronously
+func receiveMessage(t *testing.T, messages <-chan sentMessage) sentMessage {
+	t.Helper()
+
+	select {
+	case message := <-messages:
+		return message
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a message")
+
+		return sentMessage{}
+	}
+}
+
+func TestAdmin_Stats(t *testing.T) {
+	t.Parallel()
+
+	tracker := analytics.NewTracker(nil)
+	tracker.RecordCommand("/tasa")
+	tracker.RecordCommand("/tasa")
+	tracker.RecordCommand("/dolar")
+	tracker.RecordInlineQuery()
+
+	h := newTestHandler(t, nil, store.NewMemory(), Settings{AdminUserIDs: []int64{testAdminID}, Analytics: tracker})
+
+	_, err := h.store.RegisterChat(100, "group", time.Now())
+	require.NoError(t, err)
+
+	srv, messages := newMessageServer(t)
+	b := newTelegramBot(t, srv.URL)
+
+	h.Stats(context.Background(), b, commandUpdate(testAdminID, "/stats"))
+
+	message := receiveMessage(t, messages)
+
+	assert.Contains(t, message.Text, "Chats conocidos: 1")
+	assert.Contains(t, message.Text, "Consultas inline: 1 (0 compartidas)")
+
+	// Most used commands first
+	assert.Regexp(t, `(?s)/tasa: .*2.*/dolar: .*1`, message.Text)
+}
+
+func TestAdmin_Upstream(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name     string
+		status   int
+		expected string
+	}{
+		{
+			name:     "healthy",
+			status:   http.StatusOK,
+			expected: "✅ Disponible",
+		},
+		{
+			name:     "unhealthy",
+			status:   http.StatusServiceUnavailable,
+			expected: "❌ No disponible",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fxSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/health", r.URL.Path)
+
+				w.WriteHeader(testCase.status)
+			}))
+			t.Cleanup(fxSrv.Close)
+
+			h := newTestHandler(
+				t,
+				fxrates.NewClient(fxSrv.URL, time.Second),
+				store.NewMemory(),
+				Settings{AdminUserIDs: []int64{testAdminID}},
+			)
+
+			srv, messages := newMessageServer(t)
+			b := newTelegramBot(t, srv.URL)
+
+			h.Upstream(context.Background(), b, commandUpdate(testAdminID, "/upstream"))
+
+			message := receiveMessage(t, messages)
+
+			assert.Contains(t, message.Text, testCase.expected)
+			assert.Contains(t, message.Text, "Circuito: cerrado")
+		})
+	}
+}
+
+func TestAdmin_Broadcast(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHandler(t, nil, store.NewMemory(), Settings{AdminUserIDs: []int64{testAdminID}})
+
+	for _, chatID := range []int64{100, 200} {
+		_, err := h.store.RegisterChat(chatID, "group", time.Now())
+		require.NoError(t, err)
+	}
+
+	srv, messages := newMessageServer(t)
+	b := newTelegramBot(t, srv.URL)
+
+	h.Broadcast(context.Background(), b, commandUpdate(testAdminID, "/broadcast Nueva versión\ndisponible"))
+
+	started := receiveMessage(t, messages)
+	assert.Equal(t, testAdminID, started.ChatID)
+	assert.Contains(t, started.Text, "2 chats")
+
+	// The text is sent verbatim, keeping its line breaks
+	for _, chatID := range []int64{100, 200} {
+		message := receiveMessage(t, messages)
+
+		assert.Equal(t, chatID, message.ChatID)
+		assert.Equal(t, "Nueva versión\ndisponible", message.Text)
+		assert.Empty(t, message.ParseMode)
+	}
+
+	done := receiveMessage(t, messages)
+	assert.Equal(t, testAdminID, done.ChatID)
+	assert.Contains(t, done.Text, "2 de 2 enviados, 0 fallidos, 0 bloqueados")
+
+	h.Broadcast(context.Background(), b, commandUpdate(testAdminID, "/broadcast status"))
+	assert.Contains(t, receiveMessage(t, messages).Text, "0 pendientes")
+}
+
+func TestAdmin_BroadcastWithoutText(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHandler(t, nil, store.NewMemory(), Settings{AdminUserIDs: []int64{testAdminID}})
+
+	srv, messages := newMessageServer(t)
+	b := newTelegramBot(t, srv.URL)
+
+	h.Broadcast(context.Background(), b, commandUpdate(testAdminID, "/broadcast   "))
+
+	assert.Contains(t, receiveMessage(t, messages).Text, "/broadcast [dry-run|status|resume] <mensaje>")
+}
+
+func TestAdmin_BroadcastDryRunAndStatus(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHandler(t, nil, store.NewMemory(), Settings{AdminUserIDs: []int64{testAdminID}})
+
+	for _, chatID := range []int64{100, 200} {
+		_, err := h.store.RegisterChat(chatID, "group", time.Now())
+		require.NoError(t, err)
+	}
+
+	srv, messages := newMessageServer(t)
+	b := newTelegramBot(t, srv.URL)
+
+	h.Broadcast(context.Background(), b, commandUpdate(testAdminID, "/broadcast status"))
+	assert.Contains(t, receiveMessage(t, messages).Text, "Aún no se ha hecho ninguna difusión")
+
+	// Nothing is sent, nor persisted
+	h.Broadcast(context.Background(), b, commandUpdate(testAdminID, "/broadcast dry-run Nueva versión"))
+	assert.Contains(t, receiveMessage(t, messages).Text, "se enviaría a 2 chats")
+
+	h.Broadcast(context.Background(), b, commandUpdate(testAdminID, "/broadcast status"))
+	assert.Contains(t, receiveMessage(t, messages).Text, "Aún no se ha hecho ninguna difusión")
+}
+
+func TestAdmin_SplitBroadcastArgument(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name           string
+		argument       string
+		expectedAction broadcastAction
+		expectedText   string
+	}{
+		{
+			name:           "message",
+			argument:       "Nueva versión disponible",
+			expectedAction: broadcastSend,
+			expectedText:   "Nueva versión disponible",
+		},
+		{
+			name:           "dry run",
+			argument:       "DRY-RUN Nueva\nversión",
+			expectedAction: broadcastDryRun,
+			expectedText:   "Nueva\nversión",
+		},
+		{
+			name:           "status",
+			argument:       "status",
+			expectedAction: broadcastStatus,
+		},
+		{
+			name:           "resume",
+			argument:       "resume",
+			expectedAction: broadcastResume,
+		},
+		{
+			name:           "message starting like a subcommand",
+			argument:       "statusquo",
+			expectedAction: broadcastSend,
+			expectedText:   "statusquo",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			action, text := splitBroadcastArgument(testCase.argument)
+
+			assert.Equal(t, testCase.expectedAction, action)
+			assert.Equal(t, testCase.expectedText, text)
+		})
+	}
+}
+
+func TestAdmin_Reload(t *testing.T) {
+	t.Parallel()
+
+	const newAdminID int64 = 7
+
+	h := newTestHandler(t, nil, store.NewMemory(), Settings{
+		AdminUserIDs: []int64{testAdminID},
+		Reload: func() (Settings, error) {
+			return Settings{
+				ParseMode:    models.ParseModeHTML,
+				AdminUserIDs: []int64{newAdminID},
+			}, nil
+		},
+	})
+
+	srv, messages := newMessageServer(t)
+	b := newTelegramBot(t, srv.URL)
+
+	h.Reload(context.Background(), b, commandUpdate(testAdminID, "/reload"))
+
+	message := receiveMessage(t, messages)
+	assert.Contains(t, message.Text, "Configuración recargada")
+	assert.Equal(t, string(models.ParseModeHTML), message.ParseMode)
+
+	// The reloaded admins replace the previous ones
+	assert.False(t, h.isAdmin(commandUpdate(testAdminID, "/reload")))
+	assert.True(t, h.isAdmin(commandUpdate(newAdminID, "/reload")))
+}
+
+func TestAdmin_ReloadFailure(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHandler(t, nil, store.NewMemory(), Settings{
+		AdminUserIDs: []int64{testAdminID},
+		Reload: func() (Settings, error) {
+			return Settings{}, errors.New("bad config")
+		},
+	})
+
+	srv, messages := newMessageServer(t)
+	b := newTelegramBot(t, srv.URL)
+
+	h.Reload(context.Background(), b, commandUpdate(testAdminID, "/reload"))
+
+	assert.Contains(t, receiveMessage(t, messages).Text, "bad config")
+
+	// The previous settings are kept
+	assert.True(t, h.isAdmin(commandUpdate(testAdminID, "/reload")))
+}
+
+func TestHandler_TrackUpdate(t *testing.T) {
+	t.Parallel()
+
+	tracker := analytics.NewTracker(nil)
+	h := newTestHandler(t, nil, store.NewMemory(), Settings{AdminUserIDs: []int64{testAdminID}, Analytics: tracker})
+
+	commands := map[string]struct{}{"/tasa": {}}
+
+	h.trackUpdate(commandUpdate(100, "/tasa USD"), commands)
+	h.trackUpdate(commandUpdate(100, "/unknown"), commands)
+	h.trackUpdate(commandUpdate(100, "hola"), commands)
+	h.trackUpdate(&models.Update{InlineQuery: &models.InlineQuery{Query: "USD"}}, commands)
+
+	usage := tracker.Usage()
+
+	assert.Equal(t, map[string]int{"/tasa": 1}, usage.Commands)
+	assert.Equal(t, 1, usage.InlineQueries)
+
+	chat, ok := h.store.Chat(100)
+	require.True(t, ok)
+	assert.Equal(t, string(models.ChatTypePrivate), chat.Type)
+	assert.False(t, chat.JoinedAt.IsZero())
+}
+
+func TestAdmin_UsageStats(t *testing.T) {
+	t.Parallel()
+
+	chatStore := store.NewMemory()
+	usageStats := analytics.NewAggregator(chatStore, 0)
+
+	h := newTestHandler(t, nil, chatStore, Settings{
+		AdminUserIDs: []int64{testAdminID},
+		UsageStats:   usageStats,
+	})
+
+	commands := map[string]struct{}{"/tasa": {}, "/dolar": {}}
+
+	h.trackUpdate(commandUpdate(100, "/tasa USD"), commands)
+	h.trackUpdate(commandUpdate(200, "/dolar"), commands)
+	h.trackUpdate(commandUpdate(100, "/tasa EUR"), commands)
+	h.trackUpdate(&models.Update{InlineQuery: &models.InlineQuery{
+		Query: "USD",
+		From:  &models.User{ID: 300, LanguageCode: "en"},
+	}}, commands)
+	h.recordEvent(analytics.Event{Base: "USD", Target: "VES"})
+
+	srv, messages := newMessageServer(t)
+	b := newTelegramBot(t, srv.URL)
+
+	h.UsageStats(context.Background(), b, commandUpdate(testAdminID, "/estadisticas 30"))
+
+	message := receiveMessage(t, messages).Text
+
+	assert.Contains(t, message, "Estadísticas de los últimos 30 días")
+	assert.Contains(t, message, "Chats activos: 2 en total")
+	assert.Contains(t, message, "Actualizaciones: 4")
+	assert.Regexp(t, `(?s)/tasa: .*2.*/dolar: .*1`, message)
+	assert.Regexp(t, `USD/VES: .*1`, message)
+	assert.Regexp(t, `inline: .*1`, message)
+}
+
+func TestAdmin_UsageStatsInvalidDays(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHandler(t, nil, store.NewMemory(), Settings{
+		AdminUserIDs: []int64{testAdminID},
+		UsageStats:   analytics.NewAggregator(store.NewMemory(), 0),
+	})
+
+	srv, messages := newMessageServer(t)
+	b := newTelegramBot(t, srv.URL)
+
+	for _, text := range []string{"/estadisticas 0", "/estadisticas semana"} {
+		h.UsageStats(context.Background(), b, commandUpdate(testAdminID, text))
+
+		assert.Contains(t, receiveMessage(t, messages).Text, "/estadisticas [días]")
+	}
+}
@@ -0,0 +1,252 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/store"
+)
+
+const (
+	// defaultWatchInterval is how often the watched pairs are checked for new rates
+	defaultWatchInterval = 5 * time.Minute
+
+	// maxAnnouncementAttempts is how many times a failed announcement is sent,
+	// once every check, until it's given up on
+	maxAnnouncementAttempts = 3
+)
+
+// WatchedPair is a pair announced to the opted-in chats
+// whenever a new rate is published for it
+type WatchedPair struct {
+	Base   fxrates.Currency
+	Target fxrates.Currency
+}
+
+// String returns the pair as "BASE/TARGET"
+func (p WatchedPair) String() string {
+	return p.Base.String() + "/" + p.Target.String()
+}
+
+// announcementAliases maps user input to whether new rate announcements are enabled
+var announcementAliases = map[string]bool{
+	"si":  true,
+	"sí":  true,
+	"sim": true,
+	"on":  true,
+	"yes": true,
+	"no":  false,
+	"não": false,
+	"nao": false,
+	"off": false,
+}
+
+// Announcements handles the /avisos command, opting the chat in or out of new rate announcements
+func (h *FxHandler) Announcements(ctx context.Context, b *bot.Bot, update *models.Update) {
+	var (
+		loc     = h.commandLocale(update)
+		message = updateMessage(update)
+		chatID  = message.Chat.ID
+	)
+
+	args := h.parseArgs(message.Text)
+	if len(args) < 1 {
+		h.reply(ctx, b, update, InvalidUsageMessage(translate(loc.Language, "usage.announcements", nil), loc))
+
+		return
+	}
+
+	enabled, ok := announcementAliases[strings.ToLower(args[0])]
+	if !ok {
+		h.reply(ctx, b, update, InvalidUsageMessage(translate(loc.Language, "usage.announcements", nil), loc))
+
+		return
+	}
+
+	if h.store == nil {
+		h.reply(ctx, b, update, ErrorMessage(errStoreNotConfigured, loc))
+
+		return
+	}
+
+	err := h.store.UpdateChat(chatID, func(chat *store.Chat) {
+		chat.Announcements = ""
+
+		if enabled {
+			chat.Announcements = string(loc.Language)
+		}
+	})
+	if err != nil {
+		h.logger.Error("unable to save announcements preference",
+			"chat_id", chatID,
+			"error", err,
+		)
+
+		h.reply(ctx, b, update, ErrorMessage(err, loc))
+
+		return
+	}
+
+	h.reply(ctx, b, update, AnnouncementsMessage(enabled, loc))
+}
+
+// AnnounceNewRates announces the watched pairs with a rate newer than the last one announced,
+// and retries the undelivered announcements of the latest ones.
+// The first rate seen for a pair is only recorded, so a fresh store doesn't announce old rates
+func (h *FxHandler) AnnounceNewRates(ctx context.Context, b *bot.Bot, pairs []WatchedPair) error {
+	if h.fxClient == nil {
+		return errFXClientNotConfigured
+	}
+
+	if h.store == nil {
+		return errStoreNotConfigured
+	}
+
+	var errs []error
+
+	for _, pair := range pairs {
+		if err := h.announceNewRate(ctx, b, pair); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", pair, err))
+		}
+	}
+
+	// The deliveries are persisted in batches
+	if err := h.store.Flush(); err != nil {
+		errs = append(errs, fmt.Errorf("unable to record announcements: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// announceNewRate announces the pair's rate to the opted-in chats, if it's new,
+// or retries the undelivered announcements, if it's the latest one announced
+func (h *FxHandler) announceNewRate(ctx context.Context, b *bot.Bot, pair WatchedPair) error {
+	preferences := h.settings().preferences
+
+	rates, err := h.fxClient.Rate(ctx, pair.Base.String(), pair.Target.String(), preferences.Source(pair.Base).String())
+	if err != nil {
+		return fmt.Errorf("unable to fetch rate: %w", err)
+	}
+
+	rate := preferences.Select(rates.Results)
+	if rate == nil {
+		return nil
+	}
+
+	announced, ok := h.store.Announced(pair.String())
+
+	switch {
+	case !ok:
+		// Only record the first rate seen
+		if err := h.store.SetAnnounced(pair.String(), store.AnnouncedRate{AsOf: rate.AsOf, Rate: rate.Rate}); err != nil {
+			return fmt.Errorf("unable to record announced rate: %w", err)
+		}
+
+		return nil
+	case rate.AsOf.After(announced.AsOf):
+		// Record the pending deliveries before sending them, so a restart never announces the rate twice
+		announced = h.newAnnouncement(*rate, announced.Rate)
+
+		if err := h.store.SetAnnounced(pair.String(), announced); err != nil {
+			return fmt.Errorf("unable to record announced rate: %w", err)
+		}
+
+		h.logger.Info("new rate published", "pair", pair.String(), "as_of", rate.AsOf, "rate", rate.Rate)
+	case !rate.AsOf.Equal(announced.AsOf):
+		// An older rate, the undelivered announcements are of another one
+		return nil
+	}
+
+	// Chats that opted out since are skipped
+	chatIDs := slices.DeleteFunc(undeliveredAnnouncements(announced), func(chatID int64) bool {
+		chat, ok := h.store.Chat(chatID)
+
+		return !ok || chat.Announcements == ""
+	})
+
+	return h.queue.Deliver(
+		ctx,
+		chatIDs,
+		func(ctx context.Context, chatID int64) error {
+			chat, _ := h.store.Chat(chatID)
+			loc := h.localeFor(chatID, Language(chat.Announcements))
+
+			_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID:    chatID,
+				Text:      NewRateMessage(*rate, announced.Previous, loc),
+				ParseMode: h.settings().markup.ParseMode(),
+			})
+
+			return err
+		},
+		func(chatID int64, status store.DeliveryStatus) error {
+			if status == store.DeliveryBlocked {
+				h.optOutBlocked(chatID)
+			}
+
+			return h.store.SetAnnouncedDelivery(pair.String(), chatID, status)
+		},
+	)
+}
+
+// newAnnouncement returns the announcement of the rate,
+// pending delivery to the opted-in chats that didn't block the bot
+func (h *FxHandler) newAnnouncement(rate fxrates.ExchangeRate, previous float64) store.AnnouncedRate {
+	deliveries := make(map[int64]store.DeliveryStatus)
+
+	for _, chat := range h.store.Chats() {
+		if chat.Announcements != "" && chat.BlockedAt.IsZero() {
+			deliveries[chat.ID] = store.DeliveryPending
+		}
+	}
+
+	return store.AnnouncedRate{
+		AsOf:       rate.AsOf,
+		Deliveries: deliveries,
+		Previous:   previous,
+		Rate:       rate.Rate,
+	}
+}
+
+// undeliveredAnnouncements returns the chats the announcement is still pending for,
+// or failed less than maxAnnouncementAttempts times, sorted by ID
+func undeliveredAnnouncements(announced store.AnnouncedRate) []int64 {
+	chatIDs := make([]int64, 0, len(announced.Deliveries))
+
+	for chatID, status := range announced.Deliveries {
+		switch status {
+		case store.DeliveryPending:
+		case store.DeliveryFailed:
+			if announced.Failures[chatID] >= maxAnnouncementAttempts {
+				continue
+			}
+		default:
+			continue
+		}
+
+		chatIDs = append(chatIDs, chatID)
+	}
+
+	slices.Sort(chatIDs)
+
+	return chatIDs
+}
+
+// optOutBlocked opts a chat that blocked or removed the bot out of new rate announcements
+func (h *FxHandler) optOutBlocked(chatID int64) {
+	h.logger.Info("unable to announce new rate, opting the chat out", "chat_id", chatID)
+
+	if err := h.store.UpdateChat(chatID, func(chat *store.Chat) {
+		chat.Announcements = ""
+	}); err != nil {
+		h.logger.Error("unable to opt the chat out of announcements", "chat_id", chatID, "error", err)
+	}
+}
@@ -0,0 +1,255 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/store"
+
+	"github.com/sig-0/fxrates/storage/types"
+)
+
+func TestAnnouncements_Preference(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name     string
+		command  string
+		expected string
+		reply    string
+	}{
+		{
+			name:     "opt in",
+			command:  "/avisos si",
+			expected: string(LanguageES),
+			reply:    "recibirá un aviso",
+		},
+		{
+			name:     "opt in in english",
+			command:  "/alerts ON",
+			expected: string(LanguageEN),
+			reply:    "will be notified",
+		},
+		{
+			name:     "opt out",
+			command:  "/alertas nao",
+			expected: "",
+			reply:    "não receberá mais avisos",
+		},
+		{
+			name:     "invalid",
+			command:  "/avisos talvez",
+			expected: string(LanguageEN),
+			reply:    "/avisos <si|no>",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := newTestHandler(t, nil, store.NewMemory(), Settings{})
+
+			require.NoError(t, h.store.UpdateChat(7, func(chat *store.Chat) {
+				chat.Announcements = string(LanguageEN)
+			}))
+
+			srv, messages := newMessageServer(t)
+			b := newTelegramBot(t, srv.URL)
+
+			h.Announcements(context.Background(), b, commandUpdate(7, testCase.command))
+
+			message := receiveMessage(t, messages)
+			assert.Contains(t, message.Text, testCase.reply)
+
+			chat, ok := h.store.Chat(7)
+			require.True(t, ok)
+			assert.Equal(t, testCase.expected, chat.Announcements)
+		})
+	}
+}
+
+// newAnnouncementFXServer starts a fake fxrates API serving the current USD/VES rate
+func newAnnouncementFXServer(t *testing.T) (*httptest.Server, func(fxrates.ExchangeRate)) {
+	t.Helper()
+
+	var (
+		mux     sync.Mutex
+		current fxrates.ExchangeRate
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/rates/USD/VES", r.URL.Path)
+
+		mux.Lock()
+		rate := current
+		mux.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(page(rate)))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, func(rate fxrates.ExchangeRate) {
+		mux.Lock()
+		defer mux.Unlock()
+
+		current = rate
+	}
+}
+
+func TestAnnounceNewRates(t *testing.T) {
+	t.Parallel()
+
+	var (
+		asOf = time.Date(2026, time.January, 2, 0, 0, 0, 0, time.UTC)
+		rate = fxrates.ExchangeRate{
+			Base:     types.CurrencyUSD,
+			Target:   types.CurrencyVES,
+			Rate:     36,
+			RateType: types.RateTypeMID,
+			Source:   types.SourceBCV,
+			AsOf:     asOf,
+		}
+		pairs = []WatchedPair{{Base: types.CurrencyUSD, Target: types.CurrencyVES}}
+	)
+
+	fxServer, setRate := newAnnouncementFXServer(t)
+	setRate(rate)
+
+	chatStore := store.NewMemory()
+
+	require.NoError(t, chatStore.UpdateChat(1, func(chat *store.Chat) {
+		chat.Announcements = string(LanguageEN)
+	}))
+
+	// Chats that didn't opt in, or blocked the bot, aren't announced to
+	require.NoError(t, chatStore.UpdateChat(2, func(chat *store.Chat) {}))
+	require.NoError(t, chatStore.UpdateChat(3, func(chat *store.Chat) {
+		chat.Announcements = string(LanguageES)
+		chat.BlockedAt = asOf
+	}))
+
+	h := newTestHandler(t, fxrates.NewClient(fxServer.URL, time.Second), chatStore, Settings{})
+
+	srv, messages := newMessageServer(t)
+	b := newTelegramBot(t, srv.URL)
+
+	// The first rate seen is only recorded
+	require.NoError(t, h.AnnounceNewRates(context.Background(), b, pairs))
+	assert.Empty(t, messages)
+
+	announced, ok := chatStore.Announced("USD/VES")
+	require.True(t, ok)
+	assert.Equal(t, store.AnnouncedRate{AsOf: asOf, Rate: 36}, announced)
+
+	// The same rate isn't announced again
+	require.NoError(t, h.AnnounceNewRates(context.Background(), b, pairs))
+	assert.Empty(t, messages)
+
+	// A new one is, with its change
+	rate.AsOf = asOf.AddDate(0, 0, 1)
+	rate.Rate = 36.9
+	setRate(rate)
+
+	require.NoError(t, h.AnnounceNewRates(context.Background(), b, pairs))
+
+	message := receiveMessage(t, messages)
+	assert.Equal(t, int64(1), message.ChatID)
+	assert.Contains(t, message.Text, "New BCV rate: USD/VES")
+	assert.Contains(t, message.Text, "+0.90 (+2.50%)")
+	assert.Empty(t, messages)
+
+	// Once
+	require.NoError(t, h.AnnounceNewRates(context.Background(), b, pairs))
+	assert.Empty(t, messages)
+}
+
+func TestAnnounceNewRates_RetriesFailed(t *testing.T) {
+	t.Parallel()
+
+	var (
+		asOf = time.Date(2026, time.January, 2, 0, 0, 0, 0, time.UTC)
+		rate = fxrates.ExchangeRate{
+			Base:     types.CurrencyUSD,
+			Target:   types.CurrencyVES,
+			Rate:     36.9,
+			RateType: types.RateTypeMID,
+			Source:   types.SourceBCV,
+			AsOf:     asOf.AddDate(0, 0, 1),
+		}
+		pairs = []WatchedPair{{Base: types.CurrencyUSD, Target: types.CurrencyVES}}
+	)
+
+	fxServer, setRate := newAnnouncementFXServer(t)
+	setRate(rate)
+
+	chatStore := store.NewMemory()
+
+	require.NoError(t, chatStore.SetAnnounced("USD/VES", store.AnnouncedRate{AsOf: asOf, Rate: 36}))
+
+	for _, chatID := range []int64{1, 2} {
+		require.NoError(t, chatStore.UpdateChat(chatID, func(chat *store.Chat) {
+			chat.Announcements = string(LanguageEN)
+		}))
+	}
+
+	h := newTestHandler(t, fxrates.NewClient(fxServer.URL, time.Second), chatStore, Settings{})
+
+	// Every delivery fails, so they're recorded to be retried
+	failing, _ := newMessageServer(t, apiFailure{Method: "sendMessage", Description: "Bad Request: chat not found"})
+
+	require.NoError(t, h.AnnounceNewRates(context.Background(), newTelegramBot(t, failing.URL), pairs))
+
+	announced, ok := chatStore.Announced("USD/VES")
+	require.True(t, ok)
+
+	assert.Equal(t, map[int64]store.DeliveryStatus{1: store.DeliveryFailed, 2: store.DeliveryFailed}, announced.Deliveries)
+	assert.Equal(t, map[int64]int{1: 1, 2: 1}, announced.Failures)
+
+	// Chats that opted out since aren't retried
+	require.NoError(t, chatStore.UpdateChat(2, func(chat *store.Chat) {
+		chat.Announcements = ""
+	}))
+
+	// The next check retries the failed ones, announcing the same change
+	srv, messages := newMessageServer(t)
+
+	require.NoError(t, h.AnnounceNewRates(context.Background(), newTelegramBot(t, srv.URL), pairs))
+
+	message := receiveMessage(t, messages)
+	assert.Equal(t, int64(1), message.ChatID)
+	assert.Contains(t, message.Text, "+0.90 (+2.50%)")
+	assert.Empty(t, messages)
+
+	// Once
+	require.NoError(t, h.AnnounceNewRates(context.Background(), newTelegramBot(t, srv.URL), pairs))
+	assert.Empty(t, messages)
+}
+
+func TestUndeliveredAnnouncements(t *testing.T) {
+	t.Parallel()
+
+	announced := store.AnnouncedRate{
+		Deliveries: map[int64]store.DeliveryStatus{
+			1: store.DeliverySent,
+			2: store.DeliveryBlocked,
+			3: store.DeliveryFailed,
+			4: store.DeliveryFailed,
+			5: store.DeliveryPending,
+		},
+		Failures: map[int64]int{3: 1, 4: maxAnnouncementAttempts},
+	}
+
+	// Failed too many times, the announcement is given up on
+	assert.Equal(t, []int64{3, 5}, undeliveredAnnouncements(announced))
+}
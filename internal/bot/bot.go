@@ -1,15 +1,27 @@
 package bot
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 
+	"github.com/sig-0/chigui-cifras/internal/analytics"
+	"github.com/sig-0/chigui-cifras/internal/broadcast"
+	"github.com/sig-0/chigui-cifras/internal/calendar"
+	"github.com/sig-0/chigui-cifras/internal/clock"
+	"github.com/sig-0/chigui-cifras/internal/freshness"
 	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/preference"
+	"github.com/sig-0/chigui-cifras/internal/store"
 )
 
 // Bot wraps the Telegram bot with handler
@@ -17,28 +29,150 @@ type Bot struct {
 	bot     *bot.Bot
 	handler *FxHandler
 	logger  *slog.Logger
+
+	// commands are the registered commands, written before the bot starts
+	commands map[string]struct{}
+
+	channelPosts []ChannelPost
+
+	liveRatesInterval time.Duration
+
+	watchedPairs  []WatchedPair
+	watchInterval time.Duration
 }
 
 // Settings contains optional Telegram bot settings
 type Settings struct {
 	WebhookSecretToken string
+
+	// ParseMode is the Telegram parse mode used for replies.
+	// If empty, replies are sent as plain text
+	ParseMode models.ParseMode
+
+	// InlineCache is the Telegram-side caching policy of inline answers
+	InlineCache InlineCacheSettings
+
+	// CurrencyCacheTTL is how long the supported currency list is cached.
+	// If zero, a default is used
+	CurrencyCacheTTL time.Duration
+
+	// Calendar is the banking calendar official rates apply by.
+	// If nil, only weekends are non-business days
+	Calendar *calendar.Calendar
+
+	// Freshness is the age after which rates are flagged as outdated in the replies.
+	// If nil, they never are
+	Freshness *freshness.Policy
+
+	// Preferences are the sources each currency is fetched from, and its preferred rate.
+	// If nil, preference.Default is used
+	Preferences *preference.Policy
+
+	// TimeZone is the time zone times are displayed in, unless the chat overrides it.
+	// If nil, Venezuela time is used
+	TimeZone *time.Location
+
+	// Clock tells the time messages, schedules and alerts are based on.
+	// If nil, the system clock is used
+	Clock clock.Clock
+
+	// AdminUserIDs are the Telegram users allowed to run admin commands
+	AdminUserIDs []int64
+
+	// BroadcastInterval is the wait between the messages of broadcasts
+	// and new rate announcements. If zero, a default is used
+	BroadcastInterval time.Duration
+
+	// Analytics records the usage counters and the chosen inline results.
+	// If nil, they're tracked in memory, but not exposed
+	Analytics *analytics.Tracker
+
+	// ChannelPosts are the rates posted to channels on a schedule
+	ChannelPosts []ChannelPost
+
+	// Shortcuts are the commands replying with the rate of a fixed pair.
+	// If nil, DefaultShortcuts are used; if empty, there are none
+	Shortcuts []Shortcut
+
+	// WatchedPairs are announced to the opted-in chats whenever a new rate is published
+	WatchedPairs []WatchedPair
+
+	// WatchInterval is how often the watched pairs are checked for new rates.
+	// If zero, a default is used
+	WatchInterval time.Duration
+
+	// LiveRatesInterval is how often the live rates summaries are checked for changes.
+	// If zero, a default is used
+	LiveRatesInterval time.Duration
+
+	// UsageStats rolls the usage up by day, for the /estadisticas admin command.
+	// If nil, the daily usage isn't recorded
+	UsageStats *analytics.Aggregator
+
+	// Reload re-reads the configuration for the /reload admin command.
+	// If nil, reloading is disabled
+	Reload ReloadFunc
+}
+
+// ReloadFunc re-reads the configuration, returning the updated settings.
+// Only the parse mode, inline cache, currency cache TTL, admins, calendar, freshness,
+// preferences and time zone apply while running
+type ReloadFunc func() (Settings, error)
+
+// InlineCacheSettings holds the caching policy of every kind of inline answer
+type InlineCacheSettings struct {
+	Help        InlineCachePolicy
+	Suggestions InlineCachePolicy
+	Rates       InlineCachePolicy
+	Listing     InlineCachePolicy
+	Errors      InlineCachePolicy
+}
+
+// InlineCachePolicy is how Telegram caches an inline answer
+type InlineCachePolicy struct {
+	// CacheTime is how long Telegram may serve the cached answer,
+	// rounded down to whole seconds. If zero, Telegram's default is used
+	CacheTime time.Duration
+
+	// Personal restricts the cached answer to the user who sent the query
+	Personal bool
 }
 
 // New creates a new Bot instance
 func New(
 	token string,
 	fxClient *fxrates.Client,
+	chatStore *store.Store,
 	logger *slog.Logger,
 	settings Settings,
 ) (*Bot, error) {
-	handlers := NewHandlers(fxClient, logger)
+	handlers, err := NewHandlers(fxClient, chatStore, logger, settings)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create handlers: %w", err)
+	}
+
+	tgBot := &Bot{
+		handler:      handlers,
+		logger:       logger,
+		commands:     make(map[string]struct{}),
+		channelPosts: settings.ChannelPosts,
+
+		liveRatesInterval: cmp.Or(settings.LiveRatesInterval, defaultLiveRatesInterval),
+
+		watchedPairs:  settings.WatchedPairs,
+		watchInterval: cmp.Or(settings.WatchInterval, defaultWatchInterval),
+	}
 
 	opts := []bot.Option{
 		bot.WithDefaultHandler(func(ctx context.Context, b *bot.Bot, update *models.Update) {
-			if update.InlineQuery != nil {
+			switch {
+			case update.InlineQuery != nil:
 				handlers.InlineQuery(ctx, b, update)
+			case update.ChosenInlineResult != nil:
+				handlers.ChosenInlineResult(ctx, b, update)
 			}
 		}),
+		bot.WithMiddlewares(tgBot.trackUsage),
 	}
 
 	if settings.WebhookSecretToken != "" {
@@ -50,41 +184,112 @@ func New(
 		return nil, fmt.Errorf("unable to create telegram bot: %w", err)
 	}
 
-	tgBot := &Bot{
-		bot:     b,
-		handler: handlers,
-		logger:  logger,
-	}
+	tgBot.bot = b
 
 	tgBot.registerHandlers()
 
 	return tgBot, nil
 }
 
+// registerCommand registers the handler for a command,
+// sent either to a chat or posted in a channel
+func (b *Bot) registerCommand(command string, handler bot.HandlerFunc) {
+	b.bot.RegisterHandlerMatchFunc(matchCommand(command), handler)
+	b.commands[command] = struct{}{}
+}
+
+// matchCommand matches the messages starting with the command
+func matchCommand(command string) bot.MatchFunc {
+	return func(update *models.Update) bool {
+		message := updateMessage(update)
+
+		return message != nil && strings.HasPrefix(message.Text, command)
+	}
+}
+
+// trackUsage is a middleware recording every update in the usage counters
+func (b *Bot) trackUsage(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, tgBot *bot.Bot, update *models.Update) {
+		b.handler.trackUpdate(update, b.commands)
+
+		next(ctx, tgBot, update)
+	}
+}
+
 func (b *Bot) registerHandlers() {
-	// Core commands
-	b.bot.RegisterHandler(bot.HandlerTypeMessageText, "/inicio", bot.MatchTypePrefix, b.handler.Start)
-	b.bot.RegisterHandler(bot.HandlerTypeMessageText, "/start", bot.MatchTypePrefix, b.handler.Start)
+	// Core commands. Handlers match by prefix, so longer
+	// commands are registered before the ones they start with
+	b.registerCommand("/inicio", b.handler.Start)
+	b.registerCommand("/iniciar", b.handler.Start)
+	b.registerCommand("/start", b.handler.Start)
+
+	b.registerCommand("/ayuda", b.handler.Help)
+	b.registerCommand("/ajuda", b.handler.Help)
+	b.registerCommand("/help", b.handler.Help)
+
+	b.registerCommand("/tasas", b.handler.Rates)
+	b.registerCommand("/taxas", b.handler.Rates)
+	b.registerCommand("/rates", b.handler.Rates)
+
+	b.registerCommand("/tasa", b.handler.Rate)
+	b.registerCommand("/taxa", b.handler.Rate)
+	b.registerCommand("/rate", b.handler.Rate)
+
+	b.registerCommand("/monedas", b.handler.Currencies)
+	b.registerCommand("/moedas", b.handler.Currencies)
+	b.registerCommand("/currencies", b.handler.Currencies)
+
+	// Chat preferences
+	b.registerCommand("/formato", b.handler.NumberFormat)
+	b.registerCommand("/format", b.handler.NumberFormat)
+	b.registerCommand("/zona", b.handler.TimeZone)
+	b.registerCommand("/fuso", b.handler.TimeZone)
+	b.registerCommand("/timezone", b.handler.TimeZone)
+
+	// Live rates summary, kept up to date
+	b.registerCommand("/fijar", b.handler.PinRates)
+	b.registerCommand("/fixar", b.handler.PinRates)
+	b.registerCommand("/pin", b.handler.PinRates)
+
+	// New official rate announcements
+	b.registerCommand("/avisos", b.handler.Announcements)
+	b.registerCommand("/alertas", b.handler.Announcements)
+	b.registerCommand("/alerts", b.handler.Announcements)
+
+	// Admin commands, ignored for everyone but the configured operators
+	b.registerCommand("/popular", b.handler.Popular)
+	b.registerCommand("/estadisticas", b.handler.UsageStats)
+	b.registerCommand("/stats", b.handler.Stats)
+	b.registerCommand("/upstream", b.handler.Upstream)
+	b.registerCommand("/broadcast", b.handler.Broadcast)
+	b.registerCommand("/reload", b.handler.Reload)
 
-	b.bot.RegisterHandler(bot.HandlerTypeMessageText, "/ayuda", bot.MatchTypePrefix, b.handler.Help)
-	b.bot.RegisterHandler(bot.HandlerTypeMessageText, "/help", bot.MatchTypePrefix, b.handler.Help)
+	// Configured shortcuts, after the built-in commands so they can't shadow them,
+	// and the longer ones first, like the built-in commands
+	shortcuts := slices.Clone(b.handler.shortcuts)
+	slices.SortStableFunc(shortcuts, func(first, second Shortcut) int {
+		return len(second.Command) - len(first.Command)
+	})
 
-	b.bot.RegisterHandler(bot.HandlerTypeMessageText, "/tasa", bot.MatchTypePrefix, b.handler.Rate)
-	b.bot.RegisterHandler(bot.HandlerTypeMessageText, "/rate", bot.MatchTypePrefix, b.handler.Rate)
+	for _, shortcut := range shortcuts {
+		if shadowing, ok := b.shadowingCommand(shortcut.Command); ok {
+			b.logger.Warn("shortcut shadowed by another command", "shortcut", shortcut.Command, "command", shadowing)
+		}
 
-	b.bot.RegisterHandler(bot.HandlerTypeMessageText, "/tasas", bot.MatchTypePrefix, b.handler.Rates)
-	b.bot.RegisterHandler(bot.HandlerTypeMessageText, "/rates", bot.MatchTypePrefix, b.handler.Rates)
+		b.registerCommand(shortcut.Command, b.handler.ShortcutHandler(shortcut))
+	}
+}
 
-	b.bot.RegisterHandler(bot.HandlerTypeMessageText, "/monedas", bot.MatchTypePrefix, b.handler.Currencies)
-	b.bot.RegisterHandler(bot.HandlerTypeMessageText, "/currencies", bot.MatchTypePrefix, b.handler.Currencies)
+// shadowingCommand returns the registered command the given one starts with, if any,
+// since it would match the messages meant for the given one first
+func (b *Bot) shadowingCommand(command string) (string, bool) {
+	for registered := range b.commands {
+		if strings.HasPrefix(command, registered) {
+			return registered, true
+		}
+	}
 
-	// VES shortcuts
-	b.bot.RegisterHandler(bot.HandlerTypeMessageText, "/dolar", bot.MatchTypePrefix, b.handler.Dolar)
-	b.bot.RegisterHandler(bot.HandlerTypeMessageText, "/euro", bot.MatchTypePrefix, b.handler.Euro)
-	b.bot.RegisterHandler(bot.HandlerTypeMessageText, "/usdt", bot.MatchTypePrefix, b.handler.USDT)
-	b.bot.RegisterHandler(bot.HandlerTypeMessageText, "/rublo", bot.MatchTypePrefix, b.handler.Rublo)
-	b.bot.RegisterHandler(bot.HandlerTypeMessageText, "/lira", bot.MatchTypePrefix, b.handler.Lira)
-	b.bot.RegisterHandler(bot.HandlerTypeMessageText, "/yuan", bot.MatchTypePrefix, b.handler.Yuan)
+	return "", false
 }
 
 // StartWebhook begins webhook mode dispatching for updates
@@ -117,6 +322,97 @@ func (b *Bot) DeleteWebhook(ctx context.Context, dropPendingUpdates bool) (bool,
 	})
 }
 
+// ResumeBroadcast delivers the broadcast interrupted by a restart, if any
+func (b *Bot) ResumeBroadcast(ctx context.Context) error {
+	if b.handler.broadcaster == nil || !b.handler.broadcaster.Pending() {
+		return nil
+	}
+
+	b.logger.Info("resuming interrupted broadcast")
+
+	_, err := b.handler.broadcaster.Run(ctx, broadcast.NewTelegramSender(b.bot))
+
+	return err
+}
+
+// RunLiveRates keeps the chats' live rates summaries up to date,
+// editing them whenever any of the rates changes, until the context is done
+func (b *Bot) RunLiveRates(ctx context.Context) {
+	ticker := time.NewTicker(b.liveRatesInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := b.handler.UpdateLiveRates(ctx, b.bot); err != nil && ctx.Err() == nil {
+				b.logger.Error("unable to update live rates", "error", err)
+			}
+		}
+	}
+}
+
+// RunRateWatcher announces the new rates of the watched pairs,
+// checking right away and then every interval, until the context is done
+func (b *Bot) RunRateWatcher(ctx context.Context) {
+	if len(b.watchedPairs) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(b.watchInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := b.handler.AnnounceNewRates(ctx, b.bot, b.watchedPairs); err != nil && ctx.Err() == nil {
+			b.logger.Error("unable to check for new rates", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunChannelPosts keeps the rates posted to channels up to date,
+// each on its own schedule, until the context is done
+func (b *Bot) RunChannelPosts(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for _, post := range b.channelPosts {
+		wg.Go(func() {
+			b.runChannelPost(ctx, post)
+		})
+	}
+
+	wg.Wait()
+}
+
+// runChannelPost updates the channel post right away, and then every interval
+func (b *Bot) runChannelPost(ctx context.Context, post ChannelPost) {
+	ticker := time.NewTicker(post.Interval)
+	defer ticker.Stop()
+
+	for {
+		err := b.handler.publishRate(ctx, b.bot, post.ChatID, post.Base, post.Target, post.Language, post.Pin)
+		if err != nil && ctx.Err() == nil {
+			b.logger.Error("unable to update channel post",
+				"chat_id", post.ChatID,
+				"pair", post.Base.String()+"/"+post.Target.String(),
+				"error", err,
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // SendMessage sends a message to a chat
 func (b *Bot) SendMessage(ctx context.Context, chatID int64, text string) error {
 	_, err := b.bot.SendMessage(ctx, &bot.SendMessageParams{
@@ -0,0 +1,208 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/sig-0/fxrates/provider/currencies"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/i18n"
+	"github.com/sig-0/chigui-cifras/internal/preference"
+)
+
+// crossResultSuffix ends the ID of the derived rate inline results
+const crossResultSuffix = "cross"
+
+// pivotCurrency is the currency missing pairs are derived through,
+// since every rate is published against it
+var pivotCurrency = currencies.VES
+
+var errNoCrossRate = errors.New("no rates to derive the pair from")
+
+// crossRate is a rate derived through the pivot currency,
+// from the rates of the base and the target against it
+type crossRate struct {
+	// Rate is the derived rate, with the source and rate type of both legs
+	Rate fxrates.ExchangeRate
+
+	// BaseLeg and TargetLeg are the base and target rates against the pivot currency
+	BaseLeg   fxrates.ExchangeRate
+	TargetLeg fxrates.ExchangeRate
+}
+
+// deriveCrossRates derives the base/target rates from the base/pivot and target/pivot ones,
+// pairing only the legs of the same source and rate type
+func deriveCrossRates(baseLegs, targetLegs []fxrates.ExchangeRate) []crossRate {
+	var derived []crossRate
+
+	for _, baseLeg := range baseLegs {
+		for _, targetLeg := range targetLegs {
+			if baseLeg.Source != targetLeg.Source || baseLeg.RateType != targetLeg.RateType || targetLeg.Rate == 0 {
+				continue
+			}
+
+			derived = append(derived, crossRate{
+				Rate: fxrates.ExchangeRate{
+					Base:     baseLeg.Base,
+					Target:   targetLeg.Base,
+					Rate:     baseLeg.Rate / targetLeg.Rate,
+					RateType: baseLeg.RateType,
+					Source:   baseLeg.Source,
+
+					// The derived rate is only as recent as its oldest leg
+					AsOf:      older(baseLeg.AsOf, targetLeg.AsOf),
+					FetchedAt: older(baseLeg.FetchedAt, targetLeg.FetchedAt),
+				},
+				BaseLeg:   baseLeg,
+				TargetLeg: targetLeg,
+			})
+		}
+	}
+
+	return derived
+}
+
+// older returns the earlier of the times, ignoring unset ones
+func older(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+
+	return a
+}
+
+// preferredCrossIndex returns the index of the derived rate with the source and rate type
+// preferred by the policy, or -1 if there's none
+func preferredCrossIndex(derived []crossRate, preferences preference.Policy) int {
+	rates := make([]fxrates.ExchangeRate, 0, len(derived))
+	for _, cross := range derived {
+		rates = append(rates, cross.Rate)
+	}
+
+	preferred := preferences.Select(rates)
+	if preferred == nil {
+		return -1
+	}
+
+	return slices.IndexFunc(derived, func(cross crossRate) bool {
+		return cross.Rate.Source == preferred.Source && cross.Rate.RateType == preferred.RateType
+	})
+}
+
+// fetchCrossRates fetches the rates of the base and target against the pivot currency,
+// and derives the pair from them. Pairs including the pivot can't be derived
+func (h *FxHandler) fetchCrossRates(ctx context.Context, base, target fxrates.Currency) ([]crossRate, error) {
+	if h.fxClient == nil {
+		return nil, errFXClientNotConfigured
+	}
+
+	if base == pivotCurrency || target == pivotCurrency || base == target {
+		return nil, errNoCrossRate
+	}
+
+	var (
+		legs      = make([][]fxrates.ExchangeRate, 2)
+		group, gc = errgroup.WithContext(ctx)
+	)
+
+	for i, currency := range []fxrates.Currency{base, target} {
+		group.Go(func() error {
+			rates, err := h.fxClient.Rate(gc, currency.String(), pivotCurrency.String(), "")
+			if err != nil {
+				return fmt.Errorf("unable to fetch %s/%s: %w", currency, pivotCurrency, err)
+			}
+
+			legs[i] = filterPair(rates.Results, currency, pivotCurrency)
+
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	derived := deriveCrossRates(legs[0], legs[1])
+	if len(derived) == 0 {
+		return nil, errNoCrossRate
+	}
+
+	return derived, nil
+}
+
+// preferredCrossRate derives the pair through the pivot currency, with the preferred source and rate type
+func (h *FxHandler) preferredCrossRate(ctx context.Context, base, target fxrates.Currency) (*crossRate, error) {
+	derived, err := h.fetchCrossRates(ctx, base, target)
+	if err != nil {
+		return nil, err
+	}
+
+	index := preferredCrossIndex(derived, h.settings().preferences)
+	if index == -1 {
+		return nil, errNoCrossRate
+	}
+
+	return &derived[index], nil
+}
+
+// FormatCrossRate formats a derived rate, labeled as such, along with the two rates it was derived from
+func FormatCrossRate(cross crossRate, loc Locale) string {
+	m := loc.markup()
+
+	var sb strings.Builder
+
+	sb.WriteString(FormatRate(cross.Rate, loc) + "\n\n")
+	sb.WriteString(loc.text("cross.derived", i18n.Params{"pivot": pivotCurrency}))
+
+	for _, leg := range []fxrates.ExchangeRate{cross.BaseLeg, cross.TargetLeg} {
+		sb.WriteString("\n" + loc.text("cross.leg", i18n.Params{
+			"base":   leg.Base,
+			"rate":   i18n.Raw(m.Code(formatAmount(leg.Rate, leg.Target, loc.Numbers))),
+			"target": leg.Target,
+		}))
+	}
+
+	return sb.String()
+}
+
+// crossResults builds the inline results for the derived rates, the preferred one first
+func crossResults(derived []crossRate, loc Locale) []models.InlineQueryResult {
+	var (
+		results   = make([]models.InlineQueryResult, 0, len(derived))
+		preferred = preferredCrossIndex(derived, loc.preferences())
+	)
+
+	if preferred != -1 {
+		results = append(results, crossArticle(derived[preferred], loc))
+	}
+
+	for i, cross := range derived {
+		if i != preferred {
+			results = append(results, crossArticle(cross, loc))
+		}
+	}
+
+	return results
+}
+
+// crossArticle builds the inline article for a derived rate
+func crossArticle(cross crossRate, loc Locale) *models.InlineQueryResultArticle {
+	article := rateArticle(cross.Rate, loc)
+
+	article.ID = rateResultID(cross.Rate) + "-" + crossResultSuffix
+	article.Description = translate(loc.Language, "cross.title", i18n.Params{"pivot": pivotCurrency}) +
+		" · " + article.Description
+	article.InputMessageContent = &models.InputTextMessageContent{
+		MessageText: FormatCrossRate(cross, loc),
+		ParseMode:   loc.markup().ParseMode(),
+	}
+
+	return article
+}
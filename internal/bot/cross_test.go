@@ -0,0 +1,212 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sig-0/chigui-cifras/internal/clock"
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/store"
+
+	"github.com/sig-0/fxrates/storage/types"
+)
+
+var (
+	crossAsOf = time.Date(2026, time.January, 2, 15, 4, 0, 0, time.UTC)
+
+	eurVES = fxrates.ExchangeRate{
+		Base:     types.CurrencyEUR,
+		Target:   types.CurrencyVES,
+		Rate:     50,
+		RateType: types.RateTypeMID,
+		Source:   types.SourceBCV,
+		AsOf:     crossAsOf,
+	}
+	usdVES = fxrates.ExchangeRate{
+		Base:     types.CurrencyUSD,
+		Target:   types.CurrencyVES,
+		Rate:     40,
+		RateType: types.RateTypeMID,
+		Source:   types.SourceBCV,
+		AsOf:     crossAsOf.Add(-time.Hour),
+	}
+)
+
+func TestDeriveCrossRates(t *testing.T) {
+	t.Parallel()
+
+	binanceUSD := usdVES
+	binanceUSD.Source = types.SourceBinance
+
+	buyUSD := usdVES
+	buyUSD.RateType = types.RateTypeBUY
+
+	zeroUSD := usdVES
+	zeroUSD.Rate = 0
+
+	testTable := []struct {
+		name       string
+		baseLegs   []fxrates.ExchangeRate
+		targetLegs []fxrates.ExchangeRate
+		expected   []crossRate
+	}{
+		{
+			name:       "same source and rate type",
+			baseLegs:   []fxrates.ExchangeRate{eurVES},
+			targetLegs: []fxrates.ExchangeRate{usdVES},
+			expected: []crossRate{
+				{
+					Rate: fxrates.ExchangeRate{
+						Base:     types.CurrencyEUR,
+						Target:   types.CurrencyUSD,
+						Rate:     1.25,
+						RateType: types.RateTypeMID,
+						Source:   types.SourceBCV,
+						AsOf:     usdVES.AsOf,
+					},
+					BaseLeg:   eurVES,
+					TargetLeg: usdVES,
+				},
+			},
+		},
+		{
+			name:       "different source",
+			baseLegs:   []fxrates.ExchangeRate{eurVES},
+			targetLegs: []fxrates.ExchangeRate{binanceUSD},
+		},
+		{
+			name:       "different rate type",
+			baseLegs:   []fxrates.ExchangeRate{eurVES},
+			targetLegs: []fxrates.ExchangeRate{buyUSD},
+		},
+		{
+			name:       "zero target rate",
+			baseLegs:   []fxrates.ExchangeRate{eurVES},
+			targetLegs: []fxrates.ExchangeRate{zeroUSD},
+		},
+		{
+			name:       "missing leg",
+			baseLegs:   []fxrates.ExchangeRate{eurVES},
+			targetLegs: nil,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expected, deriveCrossRates(testCase.baseLegs, testCase.targetLegs))
+		})
+	}
+}
+
+func TestFormatCrossRate(t *testing.T) {
+	t.Parallel()
+
+	derived := deriveCrossRates([]fxrates.ExchangeRate{eurVES}, []fxrates.ExchangeRate{usdVES})
+	require.Len(t, derived, 1)
+
+	loc := NewLocale(LanguageEN)
+	loc.Clock = clock.Fixed(crossAsOf)
+
+	message := FormatCrossRate(derived[0], loc)
+
+	assert.Contains(t, message, FormatRate(derived[0].Rate, loc))
+	assert.Contains(t, message, "🔀 Cross rate, derived via VES:")
+	assert.Contains(t, message, "• 1 EUR = 50.00 VES")
+	assert.Contains(t, message, "• 1 USD = 40.00 VES")
+}
+
+func TestHandler_RateCrossFallback(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name      string
+		responses map[string]any
+		expected  string
+	}{
+		{
+			name: "pair not published",
+			responses: map[string]any{
+				"/v1/rates/EUR/USD": page(),
+				"/v1/rates/EUR/VES": page(eurVES),
+				"/v1/rates/USD/VES": page(usdVES),
+			},
+			expected: "🔀 Tasa cruzada, derivada vía VES:",
+		},
+		{
+			name: "pair unavailable",
+			responses: map[string]any{
+				"/v1/rates/EUR/VES": page(eurVES),
+				"/v1/rates/USD/VES": page(usdVES),
+			},
+			expected: "🔀 Tasa cruzada, derivada vía VES:",
+		},
+		{
+			name: "no legs",
+			responses: map[string]any{
+				"/v1/rates/EUR/USD": page(),
+				"/v1/rates/EUR/VES": page(eurVES),
+				"/v1/rates/USD/VES": page(),
+			},
+			expected: "No se encontraron tasas para EUR/USD",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fxServer, _ := newInlineFXServer(t, testCase.responses)
+			h := newTestHandler(t, fxrates.NewClient(fxServer.URL, time.Second), store.NewMemory(), Settings{})
+
+			srv, messages := newMessageServer(t)
+			b := newTelegramBot(t, srv.URL)
+
+			h.Rate(context.Background(), b, commandUpdate(1, "/tasa EUR USD"))
+
+			assert.Contains(t, receiveMessage(t, messages).Text, testCase.expected)
+		})
+	}
+}
+
+func TestInlineQuery_CrossFallback(t *testing.T) {
+	t.Parallel()
+
+	fxServer, _ := newInlineFXServer(t, map[string]any{
+		"/v1/rates/EUR/USD": page(),
+		"/v1/rates/USD/EUR": page(),
+		"/v1/rates/EUR":     page(),
+		"/v1/rates/EUR/VES": page(eurVES),
+		"/v1/rates/USD/VES": page(usdVES),
+	})
+
+	tgServer, requests := newInlineServer(t)
+	t.Cleanup(tgServer.Close)
+
+	h := newTestHandler(t, fxrates.NewClient(fxServer.URL, time.Second), store.NewMemory(), Settings{})
+	b := newTelegramBot(t, tgServer.URL)
+
+	h.InlineQuery(context.Background(), b, &models.Update{
+		InlineQuery: &models.InlineQuery{
+			ID:    "inline-1",
+			Query: "EUR USD",
+			From:  &models.User{LanguageCode: "en-US"},
+		},
+	})
+
+	request := awaitInlineRequest(t, requests)
+	require.Len(t, request.Results, 1)
+
+	result := request.Results[0]
+
+	assert.Equal(t, "eur-usd-bcv-mid-cross", resultString(result, "id"))
+	assert.Equal(t, "EUR/USD", resultString(result, "title"))
+	assert.Contains(t, resultString(result, "description"), "Cross rate via VES · ")
+	assert.Contains(t, resultMessageText(t, result), "• 1 USD = 40.00 VES")
+}
@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"github.com/sig-0/fxrates/provider/currencies"
+
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+)
+
+// genericCurrencyEmoji is shown for the currencies without their own emoji
+const genericCurrencyEmoji = "\U0001F4B1" // currency exchange
+
+// currencyInfo is the presentation metadata of a currency.
+// Its localized name lives in the catalogs, as "currencies.names.<code>"
+type currencyInfo struct {
+	// Emoji is the flag of the currency's country or union, or a coin for crypto
+	Emoji string
+
+	// Symbol is the sign amounts are written with, like "$" or "Bs."
+	Symbol string
+
+	// Precision is the number of decimals amounts are shown with,
+	// the ISO 4217 minor units for fiat currencies
+	Precision int
+}
+
+// currencyRegistry holds the metadata of the currencies known to the bot:
+// every currency returned by fxrates, and the other ones the BCV publishes.
+// Currencies missing from it are shown by their code, with the defaults
+var currencyRegistry = map[fxrates.Currency]currencyInfo{
+	// Returned by fxrates
+	currencies.USD:  {Emoji: "\U0001F1FA\U0001F1F8", Symbol: "$", Precision: 2},
+	currencies.EUR:  {Emoji: "\U0001F1EA\U0001F1FA", Symbol: "€", Precision: 2},
+	currencies.VES:  {Emoji: "\U0001F1FB\U0001F1EA", Symbol: "Bs.", Precision: 2},
+	currencies.RUB:  {Emoji: "\U0001F1F7\U0001F1FA", Symbol: "₽", Precision: 2},
+	currencies.TRY:  {Emoji: "\U0001F1F9\U0001F1F7", Symbol: "₺", Precision: 2},
+	currencies.CNY:  {Emoji: "\U0001F1E8\U0001F1F3", Symbol: "¥", Precision: 2},
+	currencies.USDT: {Emoji: "\U0001F4B2", Symbol: "₮", Precision: cryptoPrecision}, // heavy dollar sign
+
+	// Fiat
+	"COP": {Emoji: "\U0001F1E8\U0001F1F4", Symbol: "$", Precision: 2},
+	"BRL": {Emoji: "\U0001F1E7\U0001F1F7", Symbol: "R$", Precision: 2},
+	"ARS": {Emoji: "\U0001F1E6\U0001F1F7", Symbol: "$", Precision: 2},
+	"CLP": {Emoji: "\U0001F1E8\U0001F1F1", Symbol: "$", Precision: 0},
+	"PEN": {Emoji: "\U0001F1F5\U0001F1EA", Symbol: "S/", Precision: 2},
+	"MXN": {Emoji: "\U0001F1F2\U0001F1FD", Symbol: "$", Precision: 2},
+	"DOP": {Emoji: "\U0001F1E9\U0001F1F4", Symbol: "RD$", Precision: 2},
+	"GBP": {Emoji: "\U0001F1EC\U0001F1E7", Symbol: "£", Precision: 2},
+	"JPY": {Emoji: "\U0001F1EF\U0001F1F5", Symbol: "¥", Precision: 0},
+	"CAD": {Emoji: "\U0001F1E8\U0001F1E6", Symbol: "$", Precision: 2},
+	"CHF": {Emoji: "\U0001F1E8\U0001F1ED", Symbol: "Fr.", Precision: 2},
+
+	// Crypto
+	"USDC": {Emoji: "\U0001FA99", Symbol: "USDC", Precision: cryptoPrecision}, // coin
+	"BTC":  {Emoji: "\U0001FA99", Symbol: "₿", Precision: cryptoPrecision},
+	"ETH":  {Emoji: "\U0001FA99", Symbol: "Ξ", Precision: cryptoPrecision},
+}
+
+// getEmoji returns the emoji shown along the currency
+func getEmoji(currency fxrates.Currency) string {
+	if info, ok := currencyRegistry[currency]; ok {
+		return info.Emoji
+	}
+
+	return genericCurrencyEmoji
+}
+
+// currencySymbol returns the sign amounts in the currency are written with, or its code if unknown
+func currencySymbol(currency fxrates.Currency) string {
+	if info, ok := currencyRegistry[currency]; ok {
+		return info.Symbol
+	}
+
+	return currency.String()
+}
+
+// currencyName returns the localized name of the currency, or its code if unknown
+func currencyName(currency fxrates.Currency, lang Language) string {
+	if _, ok := currencyRegistry[currency]; !ok {
+		return currency.String()
+	}
+
+	return translate(lang, "currencies.names."+currency.String(), nil)
+}
+
+// precisionFor returns the number of decimals to display for amounts in the given currency
+func precisionFor(currency fxrates.Currency) int {
+	if info, ok := currencyRegistry[currency]; ok {
+		return info.Precision
+	}
+
+	return defaultPrecision
+}
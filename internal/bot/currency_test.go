@@ -0,0 +1,125 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+
+	"github.com/sig-0/fxrates/provider/currencies"
+	"github.com/sig-0/fxrates/storage/types"
+)
+
+func TestCurrency_RegistryNames(t *testing.T) {
+	t.Parallel()
+
+	for currency := range currencyRegistry {
+		for _, lang := range Languages {
+			name := currencyName(currency, lang)
+
+			assert.NotEqual(t, "currencies.names."+currency.String(), name, "%s has no %s name", currency, lang)
+			assert.NotEmpty(t, name)
+		}
+	}
+}
+
+func TestCurrency_RegistryCoversFXRates(t *testing.T) {
+	t.Parallel()
+
+	// Every currency the fxrates providers return
+	fxratesCurrencies := []fxrates.Currency{
+		currencies.USD,
+		currencies.EUR,
+		currencies.VES,
+		currencies.USDT,
+		currencies.RUB,
+		currencies.TRY,
+		currencies.CNY,
+	}
+
+	for _, currency := range fxratesCurrencies {
+		assert.Contains(t, currencyRegistry, currency, "%s has no registry entry", currency)
+	}
+}
+
+func TestCurrency_Metadata(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name      string
+		currency  fxrates.Currency
+		lang      Language
+		label     string
+		emoji     string
+		precision int
+	}{
+		{
+			name:      "fiat",
+			currency:  types.CurrencyUSD,
+			lang:      LanguageES,
+			label:     "Dólar estadounidense ($)",
+			emoji:     "\U0001F1FA\U0001F1F8",
+			precision: 2,
+		},
+		{
+			name:      "bolivar",
+			currency:  types.CurrencyVES,
+			lang:      LanguageEN,
+			label:     "Venezuelan bolívar (Bs.)",
+			emoji:     "\U0001F1FB\U0001F1EA",
+			precision: 2,
+		},
+		{
+			name:      "flag",
+			currency:  "COP",
+			lang:      LanguagePT,
+			label:     "Peso colombiano ($)",
+			emoji:     "\U0001F1E8\U0001F1F4",
+			precision: 2,
+		},
+		{
+			name:      "no minor units",
+			currency:  "CLP",
+			lang:      LanguageES,
+			label:     "Peso chileno ($)",
+			emoji:     "\U0001F1E8\U0001F1F1",
+			precision: 0,
+		},
+		{
+			name:      "symbol matching the code",
+			currency:  "USDC",
+			lang:      LanguageEN,
+			label:     "USD Coin",
+			emoji:     "\U0001FA99",
+			precision: cryptoPrecision,
+		},
+		{
+			name:      "unknown",
+			currency:  "XYZ",
+			lang:      LanguageES,
+			label:     "",
+			emoji:     genericCurrencyEmoji,
+			precision: defaultPrecision,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.label, currencyLabel(testCase.currency, testCase.lang))
+			assert.Equal(t, testCase.emoji, getEmoji(testCase.currency))
+			assert.Equal(t, testCase.precision, precisionFor(testCase.currency))
+		})
+	}
+
+	// Unknown currencies are shown by their code
+	assert.Equal(t, "XYZ", currencyName("XYZ", LanguageEN))
+	assert.Equal(t, "XYZ", currencySymbol("XYZ"))
+	assert.Equal(
+		t,
+		"💱 Monedas soportadas\n\n💱 XYZ",
+		FormatCurrencies([]fxrates.Currency{"XYZ"}, NewLocale(LanguageES)),
+	)
+}
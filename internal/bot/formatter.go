@@ -2,12 +2,24 @@ package bot
 
 import (
 	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+	_ "time/tzdata" // the display time zones don't depend on the host's tz database
 
-	"github.com/sig-0/fxrates/provider/currencies"
+	"github.com/sig-0/fxrates/storage/types"
 
+	"github.com/sig-0/chigui-cifras/internal/analytics"
+	"github.com/sig-0/chigui-cifras/internal/broadcast"
+	"github.com/sig-0/chigui-cifras/internal/calendar"
+	"github.com/sig-0/chigui-cifras/internal/clock"
+	"github.com/sig-0/chigui-cifras/internal/freshness"
 	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/i18n"
+	"github.com/sig-0/chigui-cifras/internal/preference"
 )
 
 // Language indicates the output language for user-facing messages
@@ -16,201 +28,749 @@ type Language string
 const (
 	LanguageES Language = "es"
 	LanguageEN Language = "en"
+	LanguagePT Language = "pt"
 )
 
-// currencyEmoji maps currency codes to emoji representations
-var currencyEmoji = map[fxrates.Currency]string{
-	currencies.USD:  "\U0001F4B5", // dollar
-	currencies.EUR:  "\U0001F4B6", // euro
-	currencies.VES:  "\U0001F1FB\U0001F1EA",
-	currencies.USDT: "\U0001F4B2",
-	currencies.RUB:  "\U0001F1F7\U0001F1FA",
-	currencies.TRY:  "\U0001F1F9\U0001F1F7",
-	currencies.CNY:  "\U0001F1E8\U0001F1F3",
+// Languages is the list of supported languages
+var Languages = []Language{LanguageES, LanguageEN, LanguagePT}
+
+// Locale holds the presentation settings used when formatting messages
+type Locale struct {
+	// Markup is the rich text markup, plain text if nil
+	Markup Markup
+
+	// Calendar is the banking calendar official rates apply by.
+	// If nil, their value date isn't shown
+	Calendar *calendar.Calendar
+
+	// Freshness is the age after which rates are flagged as outdated.
+	// If nil, they never are
+	Freshness *freshness.Policy
+
+	// Preferences select the rate shown first among a pair's rates.
+	// If nil, preference.Default is used
+	Preferences *preference.Policy
+
+	// Clock tells the time relative times are rendered against.
+	// If nil, the system clock is used
+	Clock clock.Clock
+
+	// Location is the time zone times are displayed in.
+	// If nil, Venezuela time is used
+	Location *time.Location
+
+	Numbers  NumberStyle
+	Language Language
 }
 
-var caracasLocation = time.FixedZone("VET", -4*60*60)
+// NewLocale returns the default plain text locale for the given language
+func NewLocale(lang Language) Locale {
+	return Locale{
+		Language: lang,
+		Numbers:  numberStyleFor(lang),
+	}
+}
 
-func getEmoji(currency fxrates.Currency) string {
-	if e, ok := currencyEmoji[currency]; ok {
-		return e
+// preferences returns the locale rate preferences, defaulting to the built-in ones
+func (loc Locale) preferences() preference.Policy {
+	if loc.Preferences == nil {
+		return preference.Default()
 	}
 
-	return "\U0001F4B1" // generic currency
+	return *loc.Preferences
 }
 
-// formatTime formats the time to display VET (Venezuela time)
-func formatTime(value time.Time) string {
-	return value.In(caracasLocation).Format("2006-01-02 15:04 MST")
+// markup returns the locale markup, defaulting to plain text
+func (loc Locale) markup() Markup {
+	if loc.Markup == nil {
+		return plainMarkup{}
+	}
+
+	return loc.Markup
+}
+
+// now returns the current time of the locale clock
+func (loc Locale) now() time.Time {
+	return clock.Or(loc.Clock).Now()
+}
+
+// location returns the locale time zone, defaulting to Venezuela time
+func (loc Locale) location() *time.Location {
+	if loc.Location == nil {
+		return defaultLocation
+	}
+
+	return loc.Location
+}
+
+// text renders the catalog message for the locale, escaped for its markup
+func (loc Locale) text(key string, params i18n.Params) string {
+	return catalog.RenderEscaped(string(loc.Language), key, params, loc.markup().Escape)
+}
+
+// numberFormatExample is the sample amount shown when changing the number format
+const numberFormatExample = 1234567.89
+
+// percentPrecision is the number of decimals of the rate changes, in percent
+const percentPrecision = 2
+
+// maxUsageTopCounts is the number of commands and pairs listed in the usage statistics
+const maxUsageTopCounts = 5
+
+// DefaultTimeZone is the IANA name of the time zone times are displayed in by default
+const DefaultTimeZone = clock.CaracasTimeZone
+
+// defaultLocation is the default display time zone
+var defaultLocation = clock.Caracas
+
+// zoneAbbreviations are the abbreviations shown for the time zones
+// the tz database only names by their offset, like "-04"
+var zoneAbbreviations = map[string]string{
+	DefaultTimeZone: "VET",
+}
+
+// formatTime formats the time in the locale time zone, like "2026-01-02 11:04 VET"
+func (loc Locale) formatTime(value time.Time) string {
+	var (
+		location = loc.location()
+		zone, _  = value.In(location).Zone()
+	)
+
+	if abbreviation, ok := zoneAbbreviations[location.String()]; ok {
+		zone = abbreviation
+	}
+
+	return value.In(location).Format("2006-01-02 15:04") + " " + zone
+}
+
+// formatTimestamp formats the time in full, followed by how long ago it was,
+// like "2026-01-02 15:04 VET (hace 5 minutos)"
+func formatTimestamp(value time.Time, loc Locale) i18n.Raw {
+	m := loc.markup()
+
+	return i18n.Raw(m.Italic(loc.formatTime(value)) + " " + m.Escape("("+formatRelativeTime(value, loc.now(), loc)+")"))
+}
+
+// formatRelativeTime formats the time relative to now, in whole minutes, hours or calendar days,
+// like "hace 5 minutos", "ayer a las 9:00" or "in 2 hours". Rates can be effective in the future.
+// The text isn't escaped for the markup
+func formatRelativeTime(value, now time.Time, loc Locale) string {
+	var (
+		elapsed = now.Sub(value)
+		days    = calendarDays(value, now, loc.location())
+		lang    = loc.Language
+	)
+
+	switch {
+	case elapsed > -time.Minute && elapsed < time.Minute:
+		return translate(lang, "relative.just_now", nil)
+	case elapsed > 0 && elapsed < time.Hour:
+		return translate(lang, "relative.minutes_ago", i18n.Params{i18n.CountParam: int(elapsed / time.Minute)})
+	case elapsed < 0 && -elapsed < time.Hour:
+		return translate(lang, "relative.in_minutes", i18n.Params{i18n.CountParam: int(-elapsed / time.Minute)})
+	case days == 0 && elapsed > 0:
+		return translate(lang, "relative.hours_ago", i18n.Params{i18n.CountParam: int(elapsed / time.Hour)})
+	case days == 0:
+		return translate(lang, "relative.in_hours", i18n.Params{i18n.CountParam: int(-elapsed / time.Hour)})
+	case days == 1:
+		return translate(lang, "relative.yesterday", i18n.Params{"time": loc.formatClock(value)})
+	case days == -1:
+		return translate(lang, "relative.tomorrow", i18n.Params{"time": loc.formatClock(value)})
+	case days > 0:
+		return translate(lang, "relative.days_ago", i18n.Params{i18n.CountParam: days})
+	default:
+		return translate(lang, "relative.in_days", i18n.Params{i18n.CountParam: -days})
+	}
+}
+
+// calendarDays returns the number of calendar days from the time to now, in the time zone,
+// negative if the time is on a later day
+func calendarDays(value, now time.Time, location *time.Location) int {
+	day := func(t time.Time) time.Time {
+		year, month, date := t.In(location).Date()
+
+		return time.Date(year, month, date, 0, 0, 0, 0, time.UTC)
+	}
+
+	return int(day(now).Sub(day(value)) / (24 * time.Hour))
+}
+
+// formatClock formats the time of day in the locale time zone, like "9:00"
+func (loc Locale) formatClock(value time.Time) string {
+	value = value.In(loc.location())
+
+	return fmt.Sprintf("%d:%02d", value.Hour(), value.Minute())
+}
+
+// timestampLines returns when the rate became effective and, if known,
+// when it was fetched from its source, both in full and relative to now
+func timestampLines(rate fxrates.ExchangeRate, loc Locale) string {
+	lines := loc.text("rate.effective", i18n.Params{"time": formatTimestamp(rate.AsOf, loc)})
+
+	if !rate.FetchedAt.IsZero() {
+		lines += "\n" + loc.text("rate.fetched", i18n.Params{"time": formatTimestamp(rate.FetchedAt, loc)})
+	}
+
+	return lines
 }
 
 // FormatRate formats a single exchange rate for display
-func FormatRate(rate fxrates.ExchangeRate, lang Language) string {
-	emoji := getEmoji(rate.Base)
+func FormatRate(rate fxrates.ExchangeRate, loc Locale) string {
+	return formatRate(rate, nil, loc)
+}
+
+// formatRate formats a single exchange rate for display,
+// marking it as inverted if it was computed from the published rate
+func formatRate(rate fxrates.ExchangeRate, published *fxrates.ExchangeRate, loc Locale) string {
+	m := loc.markup()
 
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("%s %s → %s\n\n", emoji, rate.Base, rate.Target))
 
-	if lang == LanguageEN {
-		sb.WriteString(fmt.Sprintf("Rate: %.2f\n", rate.Rate))
-		sb.WriteString(fmt.Sprintf("Source: %s\n", rate.Source))
-		sb.WriteString(fmt.Sprintf("Type: %s\n\n", rate.RateType))
-		sb.WriteString(fmt.Sprintf("📅 Effective: %s", formatTime(rate.AsOf)))
-	} else {
-		sb.WriteString(fmt.Sprintf("Tasa: %.2f\n", rate.Rate))
-		sb.WriteString(fmt.Sprintf("Fuente: %s\n", rate.Source))
-		sb.WriteString(fmt.Sprintf("Tipo: %s\n\n", rate.RateType))
-		sb.WriteString(fmt.Sprintf("📅 Efectivo: %s", formatTime(rate.AsOf)))
+	header := fmt.Sprintf("%s → %s", rate.Base, rate.Target)
+	sb.WriteString(m.Escape(getEmoji(rate.Base)) + " " + m.Bold(header) + "\n")
+	sb.WriteString(m.Italic(pairNames(rate.Base, rate.Target, loc.Language)) + "\n\n")
+
+	value := formatAmount(rate.Rate, rate.Target, loc.Numbers)
+
+	sb.WriteString(loc.text("rate.value", i18n.Params{"rate": i18n.Raw(m.Bold(value))}) + "\n")
+	sb.WriteString(loc.text("rate.source", i18n.Params{"source": rate.Source}) + "\n")
+	sb.WriteString(loc.text("rate.type", i18n.Params{"type": rate.RateType}) + "\n")
+
+	if published != nil {
+		sb.WriteString(loc.text("inverse.inverted", i18n.Params{
+			"base":   published.Base,
+			"rate":   i18n.Raw(m.Code(formatAmount(published.Rate, published.Target, loc.Numbers))),
+			"target": published.Target,
+		}) + "\n")
 	}
 
+	sb.WriteString("\n")
+	sb.WriteString(timestampLines(rate, loc))
+	sb.WriteString(valueDateLines([]fxrates.ExchangeRate{rate}, loc.now(), loc))
+	sb.WriteString(staleLine([]fxrates.ExchangeRate{rate}, loc.now(), loc))
+
 	return sb.String()
 }
 
-// FormatRates formats multiple exchange rates for display
-func FormatRates(rates []fxrates.ExchangeRate, lang Language) string {
-	if len(rates) == 0 {
-		if lang == LanguageEN {
-			return "No rates found"
+// staleLine warns about the oldest of the rates past its source's freshness threshold,
+// or returns nothing if they're all fresh
+func staleLine(rates []fxrates.ExchangeRate, now time.Time, loc Locale) string {
+	if loc.Freshness == nil {
+		return ""
+	}
+
+	var oldest time.Duration
+
+	for _, rate := range rates {
+		if age, stale := loc.Freshness.Stale(rate, now); stale {
+			oldest = max(oldest, age)
 		}
+	}
 
-		return "No se encontraron tasas"
+	if oldest == 0 {
+		return ""
 	}
 
-	base := rates[0].Base
-	emoji := getEmoji(base)
+	return "\n" + loc.text("freshness.stale", i18n.Params{"age": formatAge(oldest)})
+}
 
-	var sb strings.Builder
+// formatAge formats the age in its largest whole unit, like "45 min", "3 h" or "2 d"
+func formatAge(age time.Duration) string {
+	const day = 24 * time.Hour
+
+	switch {
+	case age < time.Hour:
+		return fmt.Sprintf("%d min", max(int(age/time.Minute), 1))
+	case age < 2*day:
+		return fmt.Sprintf("%d h", int(age/time.Hour))
+	default:
+		return fmt.Sprintf("%d d", int(age/day))
+	}
+}
 
-	if lang == LanguageEN {
-		sb.WriteString(fmt.Sprintf("%s Rates for %s\n\n", emoji, base))
-	} else {
-		sb.WriteString(fmt.Sprintf("%s Tasas de %s\n\n", emoji, base))
+// valueDateLines returns the business day the first official rate applies to,
+// warning if a newer one should already be in effect, or nothing if there's none
+func valueDateLines(rates []fxrates.ExchangeRate, now time.Time, loc Locale) string {
+	if loc.Calendar == nil {
+		return ""
+	}
+
+	index := slices.IndexFunc(rates, func(rate fxrates.ExchangeRate) bool {
+		return rate.Source == types.SourceBCV
+	})
+	if index == -1 {
+		return ""
 	}
 
+	var (
+		publishedAt = rates[index].AsOf
+		valueDate   = loc.Calendar.ValueDate(publishedAt)
+	)
+
+	lines := "\n" + loc.text("calendar.value_date", i18n.Params{
+		"weekday": translate(loc.Language, "calendar.weekdays."+strings.ToLower(valueDate.Weekday().String()), nil),
+		"day":     valueDate.Day(),
+	})
+
+	if loc.Calendar.IsOutdated(publishedAt, now) {
+		lines += "\n" + loc.text("calendar.outdated", nil)
+	}
+
+	return lines
+}
+
+// PinnedRateMessage formats a rate kept up to date in a chat, along with when it was last updated
+func PinnedRateMessage(rate fxrates.ExchangeRate, updatedAt time.Time, loc Locale) string {
+	updated := loc.text("pinned.updated", i18n.Params{"time": i18n.Raw(loc.markup().Italic(loc.formatTime(updatedAt)))})
+
+	return FormatRate(rate, loc) + "\n" + updated
+}
+
+// LiveRatesMessage formats the live rates summary, along with when it was last updated
+func LiveRatesMessage(rates []fxrates.ExchangeRate, updatedAt time.Time, loc Locale) string {
+	m := loc.markup()
+
+	var sb strings.Builder
+
+	sb.WriteString(m.Bold(translate(loc.Language, "live.header", nil)) + "\n\n")
+
 	for _, rate := range rates {
-		sb.WriteString(fmt.Sprintf("• %s: %.2f (%s, %s)\n", rate.Target, rate.Rate, rate.Source, rate.RateType))
+		sb.WriteString(fmt.Sprintf(
+			"%s %s: %s %s\n",
+			m.Escape(getEmoji(rate.Base)),
+			m.Escape(rate.Base.String()+"/"+rate.Target.String()),
+			m.Code(formatAmount(rate.Rate, rate.Target, loc.Numbers)),
+			m.Escape(fmt.Sprintf("(%s, %s)", rate.Source, rate.RateType)),
+		))
 	}
 
-	if lang == LanguageEN {
-		sb.WriteString(fmt.Sprintf("\n📅 Effective: %s", formatTime(rates[0].AsOf)))
-	} else {
-		sb.WriteString(fmt.Sprintf("\n📅 Efectivo: %s", formatTime(rates[0].AsOf)))
+	sb.WriteString("\n" + loc.text("pinned.updated", i18n.Params{"time": i18n.Raw(m.Italic(loc.formatTime(updatedAt)))}))
+
+	return sb.String()
+}
+
+// NewRateMessage formats the announcement of a newly published rate,
+// along with its change from the previous one
+func NewRateMessage(rate fxrates.ExchangeRate, previous float64, loc Locale) string {
+	m := loc.markup()
+
+	var sb strings.Builder
+
+	sb.WriteString(m.Bold(translate(loc.Language, "announcement.header", i18n.Params{
+		"emoji":  getEmoji(rate.Base),
+		"source": rate.Source,
+		"pair":   rate.Base.String() + "/" + rate.Target.String(),
+	})) + "\n\n")
+
+	value := formatAmount(rate.Rate, rate.Target, loc.Numbers)
+
+	sb.WriteString(loc.text("rate.value", i18n.Params{"rate": i18n.Raw(m.Bold(value))}) + "\n")
+	sb.WriteString(loc.text("announcement.previous", i18n.Params{
+		"rate": formatAmount(previous, rate.Target, loc.Numbers),
+	}) + "\n")
+
+	if previous != 0 {
+		var (
+			delta   = rate.Rate - previous
+			percent = delta / previous * 100
+		)
+
+		sb.WriteString(loc.text("announcement.change", i18n.Params{
+			"delta":   signed(delta, formatNumber(math.Abs(delta), amountPrecision(rate.Rate, rate.Target), loc.Numbers)),
+			"percent": signed(delta, formatNumber(math.Abs(percent), percentPrecision, loc.Numbers)+"%"),
+		}) + "\n")
 	}
 
+	sb.WriteString("\n" + timestampLines(rate, loc))
+	sb.WriteString(valueDateLines([]fxrates.ExchangeRate{rate}, loc.now(), loc))
+
 	return sb.String()
 }
 
-// FormatCurrencies formats the list of currencies for display
-func FormatCurrencies(currencyList []fxrates.Currency, lang Language) string {
+// signed prefixes the formatted absolute value with the sign of the value
+func signed(value float64, formatted string) string {
+	switch {
+	case value > 0:
+		return "+" + formatted
+	case value < 0:
+		return "-" + formatted
+	default:
+		return formatted
+	}
+}
+
+// AnnouncementsMessage returns the confirmation for an updated announcements preference
+func AnnouncementsMessage(enabled bool, loc Locale) string {
+	if enabled {
+		return loc.text("announcement.enabled", nil)
+	}
+
+	return loc.text("announcement.disabled", nil)
+}
+
+// FormatRates formats multiple exchange rates for display
+func FormatRates(rates []fxrates.ExchangeRate, loc Locale) string {
+	if len(rates) == 0 {
+		return loc.text("rates.empty", nil)
+	}
+
+	m := loc.markup()
+	base := rates[0].Base
+
 	var sb strings.Builder
-	if lang == LanguageEN {
-		sb.WriteString("💱 Supported currencies\n\n")
-	} else {
-		sb.WriteString("💱 Monedas soportadas\n\n")
+
+	sb.WriteString(m.Bold(translate(loc.Language, "rates.header", i18n.Params{
+		"emoji": getEmoji(base),
+		"base":  base,
+	})) + "\n\n")
+
+	for _, rate := range rates {
+		sb.WriteString(fmt.Sprintf(
+			"%s %s: %s %s\n",
+			m.Escape("•"),
+			m.Escape(rate.Target.String()),
+			m.Code(formatAmount(rate.Rate, rate.Target, loc.Numbers)),
+			m.Escape(fmt.Sprintf("(%s, %s)", rate.Source, rate.RateType)),
+		))
 	}
 
+	sb.WriteString("\n" + timestampLines(rates[0], loc))
+	sb.WriteString(valueDateLines(rates, loc.now(), loc))
+	sb.WriteString(staleLine(rates, loc.now(), loc))
+
+	return sb.String()
+}
+
+// FormatCurrencies formats the list of currencies for display
+func FormatCurrencies(currencyList []fxrates.Currency, loc Locale) string {
+	m := loc.markup()
+
+	var sb strings.Builder
+
+	sb.WriteString(m.Bold(translate(loc.Language, "currencies.header", nil)) + "\n\n")
+
 	for _, currency := range currencyList {
-		sb.WriteString(fmt.Sprintf("%s %s\n", getEmoji(currency), currency))
+		sb.WriteString(m.Escape(getEmoji(currency)) + " " + m.Code(currency.String()))
+
+		if label := currencyLabel(currency, loc.Language); label != "" {
+			sb.WriteString(" " + m.Escape(label))
+		}
+
+		sb.WriteString("\n")
 	}
 
 	return strings.TrimSuffix(sb.String(), "\n")
 }
 
+// currencyLabel returns the localized name and symbol of the currency, like "Dólar estadounidense ($)",
+// or nothing if it's unknown, since its code is already shown
+func currencyLabel(currency fxrates.Currency, lang Language) string {
+	if _, ok := currencyRegistry[currency]; !ok {
+		return ""
+	}
+
+	name := currencyName(currency, lang)
+
+	if symbol := currencySymbol(currency); symbol != currency.String() {
+		name += " (" + symbol + ")"
+	}
+
+	return name
+}
+
+// pairNames returns the localized names of the pair, like "Dólar estadounidense → Bolívar"
+func pairNames(base, target fxrates.Currency, lang Language) string {
+	return currencyName(base, lang) + " → " + currencyName(target, lang)
+}
+
 // StartMessage returns the welcome message
-func StartMessage(lang Language) string {
-	if lang == LanguageEN {
-		var sb strings.Builder
-		sb.WriteString("👋 Hello!\n\n")
-		sb.WriteString("I provide real-time exchange rates for VES (Venezuelan Bolivar).\n\n")
-		sb.WriteString("Quick commands:\n")
-		sb.WriteString("• /dolar - USD/VES rate\n")
-		sb.WriteString("• /euro - EUR/VES rate\n")
-		sb.WriteString("• /usdt - USDT/VES rate\n")
-		sb.WriteString("\nMore options:\n")
-		sb.WriteString("• /rate <base> [target] - Get a specific rate\n")
-		sb.WriteString("• /rates <base> - All rates for a currency\n")
-		sb.WriteString("• /currencies - List available currencies\n")
-		sb.WriteString("\nType /help to see all commands.")
+func StartMessage(loc Locale) string {
+	return loc.text("start", nil)
+}
+
+// HelpMessage returns the help message, listing the shortcuts
+func HelpMessage(shortcuts []Shortcut, loc Locale) string {
+	return loc.text("help", i18n.Params{"shortcuts": i18n.Raw(shortcutsHelp(shortcuts, loc))})
+}
+
+// ErrorMessage formats an error message
+func ErrorMessage(err error, loc Locale) string {
+	return loc.text("error.generic", i18n.Params{"error": err})
+}
+
+// InvalidUsageMessage returns an invalid usage message
+func InvalidUsageMessage(usage string, loc Locale) string {
+	return loc.text("usage.invalid", i18n.Params{"usage": i18n.Raw(loc.markup().Code(usage))})
+}
+
+// NoRatesForPairMessage returns the message for a pair without rates
+func NoRatesForPairMessage(base, target fxrates.Currency, loc Locale) string {
+	return loc.text("rate.not_found", i18n.Params{"pair": base.String() + "/" + target.String()})
+}
+
+// NoRatesForBaseMessage returns the message for a base currency without rates
+func NoRatesForBaseMessage(base fxrates.Currency, loc Locale) string {
+	return loc.text("rates.not_found", i18n.Params{"base": base})
+}
+
+// UnknownCurrencyMessage returns the message for an unrecognized currency,
+// including "did you mean" suggestions, if any
+func UnknownCurrencyMessage(input string, suggestions []fxrates.Currency, loc Locale) string {
+	m := loc.markup()
+
+	var sb strings.Builder
+
+	sb.WriteString(loc.text("currencies.unknown", i18n.Params{"input": i18n.Raw(m.Code(input))}) + "\n\n")
+
+	if len(suggestions) == 0 {
+		sb.WriteString(loc.text("currencies.unknown_hint", nil))
 
 		return sb.String()
 	}
 
-	var sb strings.Builder
-	sb.WriteString("👋 ¡Hola!\n\n")
-	sb.WriteString("Ofrezco tasas de cambio en tiempo real para VES (Bolívar venezolano).\n\n")
-	sb.WriteString("Comandos rápidos:\n") //nolint:misspell // Spanish copy
-	sb.WriteString("• /dolar - Tasa USD/VES\n")
-	sb.WriteString("• /euro - Tasa EUR/VES\n")
-	sb.WriteString("• /usdt - Tasa USDT/VES\n")
-	sb.WriteString("\nMás opciones:\n")
-	sb.WriteString("• /tasa <base> [destino] - Obtener una tasa específica\n")
-	sb.WriteString("• /tasas <base> - Todas las tasas de una moneda\n")
-	sb.WriteString("• /monedas - Listar monedas disponibles\n")
-	sb.WriteString("\nEscribe /ayuda para ver todos los comandos.") //nolint:misspell // Spanish copy
+	codes := make([]string, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		codes = append(codes, m.Code(suggestion.String()))
+	}
+
+	sb.WriteString(loc.text("currencies.suggestions", i18n.Params{
+		"suggestions":   i18n.Raw(strings.Join(codes, m.Escape(", "))),
+		i18n.CountParam: len(codes),
+	}))
 
 	return sb.String()
 }
 
-// HelpMessage returns the help message
-func HelpMessage(lang Language) string {
-	if lang == LanguageEN {
-		var sb strings.Builder
+// TimeZoneMessage returns the confirmation for an updated time zone, with the current time in it
+func TimeZoneMessage(loc Locale) string {
+	return loc.text("time_zone.updated", i18n.Params{
+		"zone": loc.location().String(),
+		"time": i18n.Raw(loc.markup().Code(loc.formatTime(loc.now()))),
+	})
+}
+
+// NumberFormatMessage returns the confirmation for an updated number format
+func NumberFormatMessage(loc Locale) string {
+	example := formatAmount(numberFormatExample, types.CurrencyVES, loc.Numbers)
+
+	return loc.text("format.updated", i18n.Params{"example": i18n.Raw(loc.markup().Code(example))})
+}
+
+// PopularPairsMessage formats the pairs most shared through inline mode, for operators
+func PopularPairsMessage(popular []analytics.PairPopularity, loc Locale) string {
+	if len(popular) == 0 {
+		return loc.text("admin.popular.empty", nil)
+	}
+
+	m := loc.markup()
+
+	var sb strings.Builder
+
+	sb.WriteString(m.Bold(translate(loc.Language, "admin.popular.header", nil)) + "\n\n")
+
+	for i, pair := range popular {
+		sb.WriteString(loc.text("admin.popular.row", i18n.Params{
+			"rank":          i + 1,
+			"pair":          i18n.Raw(m.Code(pair.Base + "/" + pair.Target)),
+			"time":          loc.formatTime(pair.LastChosenAt),
+			i18n.CountParam: pair.Count,
+		}) + "\n")
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// StatsMessage formats the usage counters since the bot started, for operators
+func StatsMessage(usage analytics.Usage, chats int, loc Locale) string {
+	m := loc.markup()
 
-		sb.WriteString("📖 ChiguiCifras Commands\n\n")
-		sb.WriteString("Rate queries:\n")
-		sb.WriteString("• /rate <base> [target] - Get an exchange rate\n")
-		sb.WriteString("• /rates <base> - List all rates for a currency\n")
-		sb.WriteString("• /currencies - List available currencies\n")
+	var sb strings.Builder
 
-		sb.WriteString("\nVES shortcuts:\n")
-		sb.WriteString("• /dolar - USD/VES\n")
-		sb.WriteString("• /euro - EUR/VES\n")
-		sb.WriteString("• /usdt - USDT/VES\n")
-		sb.WriteString("• /rublo - RUB/VES\n")
-		sb.WriteString("• /lira - TRY/VES\n")
-		sb.WriteString("• /yuan - CNY/VES\n")
+	sb.WriteString(m.Bold(translate(loc.Language, "admin.stats.header", nil)) + "\n\n")
+	sb.WriteString(loc.text("admin.stats.started", i18n.Params{"time": loc.formatTime(usage.StartedAt)}) + "\n")
+	sb.WriteString(loc.text("admin.stats.chats", i18n.Params{i18n.CountParam: chats}) + "\n")
+	sb.WriteString(loc.text("admin.stats.inline", i18n.Params{
+		"queries": usage.InlineQueries,
+		"chosen":  usage.InlineChosen,
+	}) + "\n\n")
 
-		sb.WriteString("\nExamples:\n")
-		sb.WriteString("• /rate USD VES")
+	if len(usage.Commands) == 0 {
+		sb.WriteString(loc.text("admin.stats.no_commands", nil))
 
 		return sb.String()
 	}
 
+	commands := make([]string, 0, len(usage.Commands))
+	for command := range usage.Commands {
+		commands = append(commands, command)
+	}
+
+	// Most used first, ties sorted by name
+	sort.Slice(commands, func(i, j int) bool {
+		if usage.Commands[commands[i]] != usage.Commands[commands[j]] {
+			return usage.Commands[commands[i]] > usage.Commands[commands[j]]
+		}
+
+		return commands[i] < commands[j]
+	})
+
+	sb.WriteString(loc.text("admin.stats.commands", nil) + "\n")
+
+	for _, command := range commands {
+		sb.WriteString(m.Escape(command+": ") + m.Code(strconv.Itoa(usage.Commands[command])) + "\n")
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// UsageStatsMessage formats the daily usage summary, for operators
+func UsageStatsMessage(summary analytics.Summary, loc Locale) string {
+	if summary.Updates == 0 {
+		return loc.text("admin.usage.empty", i18n.Params{i18n.CountParam: summary.Days})
+	}
+
+	m := loc.markup()
+
 	var sb strings.Builder
-	sb.WriteString("📖 Comandos de ChiguiCifras\n\n") //nolint:misspell // Spanish copy
-	sb.WriteString("Consultas de tasas:\n")
-	sb.WriteString("• /tasa <base> [destino] - Obtener una tasa de cambio\n")
-	sb.WriteString("• /tasas <base> - Listar todas las tasas de una moneda\n")
-	sb.WriteString("• /monedas - Listar monedas disponibles\n")
-
-	sb.WriteString("\nAtajos VES:\n")
-	sb.WriteString("• /dolar - USD/VES\n")
-	sb.WriteString("• /euro - EUR/VES\n")
-	sb.WriteString("• /usdt - USDT/VES\n")
-	sb.WriteString("• /rublo - RUB/VES\n")
-	sb.WriteString("• /lira - TRY/VES\n")
-	sb.WriteString("• /yuan - CNY/VES\n")
-
-	sb.WriteString("\nEjemplos:\n")
-	sb.WriteString("• /tasa USD VES")
+
+	sb.WriteString(m.Bold(translate(loc.Language, "admin.usage.header", i18n.Params{
+		i18n.CountParam: summary.Days,
+	})) + "\n\n")
+
+	sb.WriteString(loc.text("admin.usage.chats", i18n.Params{
+		"average":       formatNumber(summary.AverageActiveChats(), 1, loc.Numbers),
+		i18n.CountParam: summary.ActiveChats,
+	}) + "\n")
+
+	if summary.Busiest.Count > 0 {
+		sb.WriteString(loc.text("admin.usage.busiest", i18n.Params{
+			"day":           summary.Busiest.Key,
+			i18n.CountParam: summary.Busiest.Count,
+		}) + "\n")
+	}
+
+	sb.WriteString(loc.text("admin.usage.updates", i18n.Params{i18n.CountParam: summary.Updates}))
+
+	sections := []struct {
+		key    string
+		counts []analytics.Count
+		limit  int
+	}{
+		{key: "admin.usage.commands", counts: summary.Commands, limit: maxUsageTopCounts},
+		{key: "admin.usage.pairs", counts: summary.Pairs, limit: maxUsageTopCounts},
+		{key: "admin.usage.languages", counts: summary.Languages},
+		{key: "admin.usage.chat_types", counts: summary.ChatTypes},
+	}
+
+	for _, section := range sections {
+		if len(section.counts) == 0 {
+			continue
+		}
+
+		counts := section.counts
+		if section.limit > 0 && len(counts) > section.limit {
+			counts = counts[:section.limit]
+		}
+
+		sb.WriteString("\n\n" + loc.text(section.key, nil))
+
+		for _, count := range counts {
+			sb.WriteString("\n" + m.Escape(count.Key+": ") + m.Code(strconv.Itoa(count.Count)))
+		}
+	}
 
 	return sb.String()
 }
 
-// ErrorMessage formats an error message
-func ErrorMessage(err error, lang Language) string {
-	if lang == LanguageEN {
-		return fmt.Sprintf("❌ Error: %v", err)
+// UpstreamMessage formats the fxrates API health check
+// and the client circuit breaker state, for operators
+func UpstreamMessage(healthErr error, latency time.Duration, circuit fxrates.CircuitStatus, loc Locale) string {
+	m := loc.markup()
+
+	var sb strings.Builder
+
+	sb.WriteString(m.Bold(translate(loc.Language, "admin.upstream.header", nil)) + "\n\n")
+
+	latencyParam := i18n.Raw(m.Code(latency.Round(time.Millisecond).String()))
+
+	if healthErr != nil {
+		sb.WriteString(loc.text("admin.upstream.unhealthy", i18n.Params{
+			"error":   healthErr,
+			"latency": latencyParam,
+		}) + "\n")
+	} else {
+		sb.WriteString(loc.text("admin.upstream.healthy", i18n.Params{"latency": latencyParam}) + "\n")
+	}
+
+	state := translate(loc.Language, "admin.upstream.state."+circuitStateKey(circuit.State), nil)
+
+	sb.WriteString(loc.text("admin.upstream.circuit", i18n.Params{"state": state}) + "\n")
+	sb.WriteString(loc.text("admin.upstream.failures", i18n.Params{i18n.CountParam: circuit.ConsecutiveFailures}))
+
+	if !circuit.OpenedAt.IsZero() {
+		sb.WriteString("\n" + loc.text("admin.upstream.opened", i18n.Params{"time": loc.formatTime(circuit.OpenedAt)}))
 	}
 
-	return fmt.Sprintf("❌ Error: %v", err)
+	return sb.String()
 }
 
-// InvalidUsageMessage returns an invalid usage message
-func InvalidUsageMessage(usage string, lang Language) string {
-	if lang == LanguageEN {
-		return fmt.Sprintf("❌ Invalid usage.\n\nUsage: %s", usage)
+// circuitStateKey returns the catalog key suffix for a circuit breaker state
+func circuitStateKey(state fxrates.CircuitState) string {
+	switch state {
+	case fxrates.CircuitOpen:
+		return "open"
+	case fxrates.CircuitHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// BroadcastStartedMessage returns the confirmation for a started broadcast
+func BroadcastStartedMessage(report broadcast.Report, loc Locale) string {
+	return loc.text("admin.broadcast.started", i18n.Params{i18n.CountParam: report.Total()})
+}
+
+// BroadcastDryRunMessage returns who a broadcast would be sent to, without sending it
+func BroadcastDryRunMessage(report broadcast.Report, loc Locale) string {
+	return loc.text("admin.broadcast.dry_run", i18n.Params{
+		"blocked":       report.Blocked,
+		i18n.CountParam: report.Pending,
+	})
+}
+
+// BroadcastDoneMessage returns the summary of a finished broadcast
+func BroadcastDoneMessage(report broadcast.Report, loc Locale) string {
+	return loc.text("admin.broadcast.done", broadcastParams(report))
+}
+
+// BroadcastInterruptedMessage returns the progress of an interrupted broadcast
+func BroadcastInterruptedMessage(report broadcast.Report, err error, loc Locale) string {
+	params := broadcastParams(report)
+	params["error"] = err
+
+	return loc.text("admin.broadcast.interrupted", params)
+}
+
+// BroadcastStatusMessage returns the progress of the latest broadcast, if any
+func BroadcastStatusMessage(report broadcast.Report, ok bool, loc Locale) string {
+	if !ok {
+		return loc.text("admin.broadcast.none", nil)
 	}
 
-	return fmt.Sprintf("❌ Uso inválido.\n\nUso: %s", usage)
+	params := broadcastParams(report)
+	params["time"] = loc.formatTime(report.StartedAt)
+
+	return loc.text("admin.broadcast.status", params)
+}
+
+// broadcastParams returns the delivery counters of a broadcast report
+func broadcastParams(report broadcast.Report) i18n.Params {
+	return i18n.Params{
+		"sent":    report.Sent,
+		"failed":  report.Failed,
+		"blocked": report.Blocked,
+		"pending": report.Pending,
+		"total":   report.Total(),
+	}
 }
@@ -2,17 +2,30 @@ package bot
 
 import (
 	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-telegram/bot/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/sig-0/fxrates/storage/types"
 
+	"github.com/sig-0/chigui-cifras/internal/analytics"
+	"github.com/sig-0/chigui-cifras/internal/broadcast"
+	"github.com/sig-0/chigui-cifras/internal/calendar"
+	"github.com/sig-0/chigui-cifras/internal/clock"
+	"github.com/sig-0/chigui-cifras/internal/freshness"
 	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/i18n"
 )
 
+var updateGolden = flag.Bool("update", false, "update the formatter golden files")
+
 func TestFormatter_FormatRate(t *testing.T) {
 	t.Parallel()
 
@@ -29,7 +42,7 @@ func TestFormatter_FormatRate(t *testing.T) {
 	t.Run("english", func(t *testing.T) {
 		t.Parallel()
 
-		message := FormatRate(rate, LanguageEN)
+		message := FormatRate(rate, NewLocale(LanguageEN))
 
 		assert.Contains(t, message, "USD")
 		assert.Contains(t, message, "VES")
@@ -41,18 +54,18 @@ func TestFormatter_FormatRate(t *testing.T) {
 		assert.Contains(t, message, "MID")
 		assert.Contains(t, message, "Effective:")
 		assert.Contains(t, message, "2026-01-02 11:04 VET")
-		assert.NotContains(t, message, "Fetched:")
+		assert.Contains(t, message, "Fetched: 2026-01-02 11:05 VET")
 	})
 
 	t.Run("spanish", func(t *testing.T) {
 		t.Parallel()
 
-		message := FormatRate(rate, LanguageES)
+		message := FormatRate(rate, NewLocale(LanguageES))
 
 		assert.Contains(t, message, "USD")
 		assert.Contains(t, message, "VES")
 		assert.Contains(t, message, "Tasa:")
-		assert.Contains(t, message, "42.00")
+		assert.Contains(t, message, "42,00")
 		assert.Contains(t, message, "Fuente:")
 		assert.Contains(t, message, "BCV")
 		assert.Contains(t, message, "Tipo:")
@@ -93,25 +106,25 @@ func TestFormatter_FormatRates(t *testing.T) {
 	t.Run("empty english", func(t *testing.T) {
 		t.Parallel()
 
-		assert.Equal(t, "No rates found", FormatRates(nil, LanguageEN))
+		assert.Equal(t, "No rates found", FormatRates(nil, NewLocale(LanguageEN)))
 	})
 
 	t.Run("empty spanish", func(t *testing.T) {
 		t.Parallel()
 
-		assert.Equal(t, "No se encontraron tasas", FormatRates(nil, LanguageES))
+		assert.Equal(t, "No se encontraron tasas", FormatRates(nil, NewLocale(LanguageES)))
 	})
 
 	t.Run("english", func(t *testing.T) {
 		t.Parallel()
 
-		message := FormatRates(rates, LanguageEN)
+		message := FormatRates(rates, NewLocale(LanguageEN))
 
 		assert.Contains(t, message, "Rates for USD")
 		assert.Contains(t, message, "VES")
 		assert.Contains(t, message, "40.00")
 		assert.Contains(t, message, "EUR")
-		assert.Contains(t, message, "0.90")
+		assert.Contains(t, message, "0.9000")
 		assert.Contains(t, message, "BCV")
 		assert.Contains(t, message, "MID")
 		assert.Contains(t, message, "Effective:")
@@ -121,13 +134,13 @@ func TestFormatter_FormatRates(t *testing.T) {
 	t.Run("spanish", func(t *testing.T) {
 		t.Parallel()
 
-		message := FormatRates(rates, LanguageES)
+		message := FormatRates(rates, NewLocale(LanguageES))
 
 		assert.Contains(t, message, "Tasas de USD")
 		assert.Contains(t, message, "VES")
-		assert.Contains(t, message, "40.00")
+		assert.Contains(t, message, "40,00")
 		assert.Contains(t, message, "EUR")
-		assert.Contains(t, message, "0.90")
+		assert.Contains(t, message, "0,9000")
 		assert.Contains(t, message, "BCV")
 		assert.Contains(t, message, "MID")
 		assert.Contains(t, message, "Efectivo:")
@@ -147,7 +160,7 @@ func TestFormatter_FormatCurrencies(t *testing.T) {
 	t.Run("english", func(t *testing.T) {
 		t.Parallel()
 
-		message := FormatCurrencies(currencies, LanguageEN)
+		message := FormatCurrencies(currencies, NewLocale(LanguageEN))
 
 		assert.Contains(t, message, "Supported currencies")
 		assert.Contains(t, message, "USD")
@@ -158,7 +171,7 @@ func TestFormatter_FormatCurrencies(t *testing.T) {
 	t.Run("spanish", func(t *testing.T) {
 		t.Parallel()
 
-		message := FormatCurrencies(currencies, LanguageES)
+		message := FormatCurrencies(currencies, NewLocale(LanguageES))
 
 		assert.Contains(t, message, "Monedas soportadas")
 		assert.Contains(t, message, "USD")
@@ -170,15 +183,60 @@ func TestFormatter_FormatCurrencies(t *testing.T) {
 func TestFormatter_StartMessage(t *testing.T) {
 	t.Parallel()
 
-	assert.Contains(t, StartMessage(LanguageEN), "Hello")
-	assert.Contains(t, StartMessage(LanguageES), "Hola")
+	assert.Contains(t, StartMessage(NewLocale(LanguageEN)), "Hello")
+	assert.Contains(t, StartMessage(NewLocale(LanguageES)), "Hola")
+	assert.Contains(t, StartMessage(NewLocale(LanguagePT)), "Olá")
 }
 
 func TestFormatter_HelpMessage(t *testing.T) {
 	t.Parallel()
 
-	assert.Contains(t, HelpMessage(LanguageEN), "Commands")
-	assert.Contains(t, HelpMessage(LanguageES), "Comandos") //nolint:misspell // Spanish copy
+	shortcuts := DefaultShortcuts()
+
+	assert.Contains(t, HelpMessage(shortcuts, NewLocale(LanguageEN)), "Commands")
+	assert.Contains(t, HelpMessage(shortcuts, NewLocale(LanguageES)), "Comandos") //nolint:misspell // Spanish copy
+	assert.Contains(t, HelpMessage(shortcuts, NewLocale(LanguagePT)), "/taxa")
+}
+
+func TestFormatter_HelpMessageShortcuts(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name      string
+		shortcuts []Shortcut
+		expected  string
+	}{
+		{
+			name:      "default",
+			shortcuts: DefaultShortcuts(),
+			expected: "Shortcuts:\n" +
+				"• /dolar - USD/VES\n" +
+				"• /euro - EUR/VES\n" +
+				"• /usdt - USDT/VES\n" +
+				"• /rublo - RUB/VES\n" +
+				"• /lira - TRY/VES\n" +
+				"• /yuan - CNY/VES\n\n" +
+				"Preferences:",
+		},
+		{
+			name:      "configured",
+			shortcuts: []Shortcut{{Command: "/peso", Base: "COP"}, {Command: "/real", Base: "BRL", Target: "USD"}},
+			expected:  "Shortcuts:\n• /peso - COP/VES\n• /real - BRL/USD\n\nPreferences:",
+		},
+		{
+			name:      "none",
+			shortcuts: nil,
+			expected:  "• /currencies - List available currencies\n\nPreferences:",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Contains(t, HelpMessage(testCase.shortcuts, NewLocale(LanguageEN)), testCase.expected)
+		})
+	}
 }
 
 func TestFormatter_ErrorMessage(t *testing.T) {
@@ -186,15 +244,16 @@ func TestFormatter_ErrorMessage(t *testing.T) {
 
 	err := errors.New("boom")
 
-	assert.Contains(t, ErrorMessage(err, LanguageEN), "Error: boom")
-	assert.Contains(t, ErrorMessage(err, LanguageES), "Error: boom")
+	assert.Contains(t, ErrorMessage(err, NewLocale(LanguageEN)), "Error: boom")
+	assert.Contains(t, ErrorMessage(err, NewLocale(LanguageES)), "Error: boom")
 }
 
 func TestFormatter_InvalidUsageMessage(t *testing.T) {
 	t.Parallel()
 
-	assert.Contains(t, InvalidUsageMessage("/rate <base>", LanguageEN), "Usage: /rate <base>")
-	assert.Contains(t, InvalidUsageMessage("/tasa <base>", LanguageES), "Uso: /tasa <base>")
+	assert.Contains(t, InvalidUsageMessage("/rate <base>", NewLocale(LanguageEN)), "Usage: /rate <base>")
+	assert.Contains(t, InvalidUsageMessage("/tasa <base>", NewLocale(LanguageES)), "Uso: /tasa <base>")
+	assert.Contains(t, InvalidUsageMessage("/taxa <base>", NewLocale(LanguagePT)), "Uso: /taxa <base>")
 }
 
 func TestFormatter_GetEmoji(t *testing.T) {
@@ -204,3 +263,638 @@ func TestFormatter_GetEmoji(t *testing.T) {
 
 	require.NotEmpty(t, emoji)
 }
+
+func TestFormatter_UnknownCurrencyMessage(t *testing.T) {
+	t.Parallel()
+
+	var (
+		suggestions = []fxrates.Currency{types.CurrencyUSD, types.CurrencyUSDT}
+
+		en = NewLocale(LanguageEN)
+		es = NewLocale(LanguageES)
+		pt = NewLocale(LanguagePT)
+	)
+
+	assert.Contains(t, UnknownCurrencyMessage("UDS", suggestions, en), "Did you mean one of: USD, USDT?")
+	assert.Contains(t, UnknownCurrencyMessage("UDS", suggestions[:1], en), "Did you mean USD?")
+	assert.Contains(t, UnknownCurrencyMessage("UDS", suggestions, es), "¿Quisiste decir alguna de estas: USD, USDT?")
+	assert.Contains(t, UnknownCurrencyMessage("UDS", suggestions[:1], pt), "Você quis dizer USD?")
+	assert.Contains(t, UnknownCurrencyMessage("XYZ", nil, en), "/currencies")
+	assert.Contains(t, UnknownCurrencyMessage("XYZ", nil, es), "/monedas")
+}
+
+func TestFormatter_NumberFormatMessage(t *testing.T) {
+	t.Parallel()
+
+	assert.Contains(t, NumberFormatMessage(NewLocale(LanguageES)), "1.234.567,89")
+	assert.Contains(t, NumberFormatMessage(NewLocale(LanguageEN)), "1,234,567.89")
+	assert.Contains(t, NumberFormatMessage(Locale{Language: LanguageES, Numbers: NumberStylePoint}), "1,234,567.89")
+}
+
+func TestFormatter_NewRateMessage(t *testing.T) {
+	t.Parallel()
+
+	rate := fxrates.ExchangeRate{
+		Base:     types.CurrencyUSD,
+		Target:   types.CurrencyVES,
+		Rate:     36,
+		RateType: types.RateTypeMID,
+		Source:   types.SourceBCV,
+	}
+
+	testTable := []struct {
+		name     string
+		previous float64
+		expected string
+	}{
+		{
+			name:     "increase",
+			previous: 32,
+			expected: "Change: +4.00 (+12.50%)",
+		},
+		{
+			name:     "decrease",
+			previous: 40,
+			expected: "Change: -4.00 (-10.00%)",
+		},
+		{
+			name:     "unchanged",
+			previous: 36,
+			expected: "Change: 0.00 (0.00%)",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			message := NewRateMessage(rate, testCase.previous, NewLocale(LanguageEN))
+
+			assert.Contains(t, message, "New BCV rate: USD/VES")
+			assert.Contains(t, message, testCase.expected)
+		})
+	}
+}
+
+func TestFormatter_ValueDate(t *testing.T) {
+	t.Parallel()
+
+	caracas := time.FixedZone("VET", -4*60*60)
+
+	bankingCalendar, err := calendar.New(nil)
+	require.NoError(t, err)
+
+	var (
+		// Published on Friday January 9, 2026
+		publishedAt = time.Date(2026, time.January, 9, 16, 0, 0, 0, caracas)
+
+		bcv = fxrates.ExchangeRate{
+			Base:     types.CurrencyUSD,
+			Target:   types.CurrencyVES,
+			Rate:     36,
+			RateType: types.RateTypeMID,
+			Source:   types.SourceBCV,
+			AsOf:     publishedAt,
+		}
+		p2p = fxrates.ExchangeRate{
+			Base:     types.CurrencyUSDT,
+			Target:   types.CurrencyVES,
+			Rate:     40,
+			RateType: types.RateTypeBUY,
+			Source:   types.SourceBinance,
+			AsOf:     publishedAt,
+		}
+	)
+
+	testTable := []struct {
+		name     string
+		rates    []fxrates.ExchangeRate
+		lang     Language
+		now      time.Time
+		expected string
+	}{
+		{
+			name:     "over the weekend",
+			rates:    []fxrates.ExchangeRate{bcv},
+			lang:     LanguageES,
+			now:      time.Date(2026, time.January, 10, 12, 0, 0, 0, caracas),
+			expected: "\n🗓 Vigente para el lunes 12",
+		},
+		{
+			name:     "outdated",
+			rates:    []fxrates.ExchangeRate{p2p, bcv},
+			lang:     LanguageEN,
+			now:      time.Date(2026, time.January, 13, 12, 0, 0, 0, caracas),
+			expected: "\n🗓 Applies to Monday 12\n⚠️ Outdated rate: a newer one should already be in effect.",
+		},
+		{
+			name:     "no official rate",
+			rates:    []fxrates.ExchangeRate{p2p},
+			lang:     LanguageES,
+			now:      time.Date(2026, time.January, 13, 12, 0, 0, 0, caracas),
+			expected: "",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			loc := NewLocale(testCase.lang)
+			loc.Calendar = bankingCalendar
+
+			assert.Equal(t, testCase.expected, valueDateLines(testCase.rates, testCase.now, loc))
+		})
+	}
+
+	// Without a calendar, the value date isn't shown
+	assert.Empty(t, valueDateLines([]fxrates.ExchangeRate{bcv}, publishedAt, NewLocale(LanguageES)))
+}
+
+func TestFormatter_Stale(t *testing.T) {
+	t.Parallel()
+
+	var (
+		now = time.Date(2026, time.January, 9, 16, 0, 0, 0, time.UTC)
+
+		bcv = fxrates.ExchangeRate{
+			Base:      types.CurrencyUSD,
+			Target:    types.CurrencyVES,
+			Rate:      36,
+			RateType:  types.RateTypeMID,
+			Source:    types.SourceBCV,
+			AsOf:      now.Add(-30 * time.Hour),
+			FetchedAt: now.Add(-3 * time.Hour),
+		}
+		p2p = fxrates.ExchangeRate{
+			Base:     types.CurrencyUSDT,
+			Target:   types.CurrencyVES,
+			Rate:     40,
+			RateType: types.RateTypeBUY,
+			Source:   types.SourceBinance,
+			AsOf:     now.Add(-45 * time.Minute),
+		}
+
+		policy = &freshness.Policy{
+			Sources: map[string]time.Duration{"BCV": 2 * time.Hour},
+			Default: 30 * time.Minute,
+		}
+	)
+
+	testTable := []struct {
+		name     string
+		rates    []fxrates.ExchangeRate
+		lang     Language
+		expected string
+	}{
+		{
+			name:     "stale official rate",
+			rates:    []fxrates.ExchangeRate{bcv},
+			lang:     LanguageES,
+			expected: "\n⚠️ dato desactualizado (hace 3 h)",
+		},
+		{
+			name:     "oldest stale rate",
+			rates:    []fxrates.ExchangeRate{p2p, bcv},
+			lang:     LanguageEN,
+			expected: "\n⚠️ outdated data (3 h ago)",
+		},
+		{
+			name:     "stale by default threshold",
+			rates:    []fxrates.ExchangeRate{p2p},
+			lang:     LanguagePT,
+			expected: "\n⚠️ dado desatualizado (há 45 min)",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			loc := NewLocale(testCase.lang)
+			loc.Freshness = policy
+
+			assert.Equal(t, testCase.expected, staleLine(testCase.rates, now, loc))
+		})
+	}
+
+	// Fresh rates, or no policy, aren't flagged
+	loc := NewLocale(LanguageES)
+	loc.Freshness = &freshness.Policy{Default: 4 * time.Hour}
+
+	assert.Empty(t, staleLine([]fxrates.ExchangeRate{bcv, p2p}, now, loc))
+	assert.Empty(t, staleLine([]fxrates.ExchangeRate{bcv}, now, NewLocale(LanguageES)))
+}
+
+func TestFormatter_RelativeTime(t *testing.T) {
+	t.Parallel()
+
+	caracas := time.FixedZone("VET", -4*60*60)
+
+	// Friday January 9, 2026, at noon in Caracas
+	now := time.Date(2026, time.January, 9, 12, 0, 0, 0, caracas)
+
+	testTable := []struct {
+		name     string
+		value    time.Time
+		lang     Language
+		expected string
+	}{
+		{
+			name:     "seconds ago",
+			value:    now.Add(-30 * time.Second),
+			lang:     LanguageES,
+			expected: "hace un momento",
+		},
+		{
+			name:     "one minute ago",
+			value:    now.Add(-time.Minute),
+			lang:     LanguageEN,
+			expected: "1 minute ago",
+		},
+		{
+			name:     "minutes ago",
+			value:    now.Add(-5*time.Minute - 20*time.Second),
+			lang:     LanguageES,
+			expected: "hace 5 minutos",
+		},
+		{
+			name:     "hours ago",
+			value:    now.Add(-3 * time.Hour),
+			lang:     LanguageEN,
+			expected: "3 hours ago",
+		},
+		{
+			name:     "yesterday",
+			value:    time.Date(2026, time.January, 8, 9, 0, 0, 0, caracas),
+			lang:     LanguageES,
+			expected: "ayer a las 9:00",
+		},
+		{
+			name:     "yesterday in another zone",
+			value:    time.Date(2026, time.January, 9, 2, 30, 0, 0, time.UTC),
+			lang:     LanguageEN,
+			expected: "yesterday at 22:30",
+		},
+		{
+			name:     "days ago",
+			value:    time.Date(2026, time.January, 6, 16, 0, 0, 0, caracas),
+			lang:     LanguagePT,
+			expected: "há 3 dias",
+		},
+		{
+			name:     "in hours",
+			value:    now.Add(2 * time.Hour),
+			lang:     LanguagePT,
+			expected: "em 2 horas",
+		},
+		{
+			name:     "tomorrow",
+			value:    time.Date(2026, time.January, 10, 0, 0, 0, 0, caracas),
+			lang:     LanguageES,
+			expected: "mañana a las 0:00",
+		},
+		{
+			name:     "in days",
+			value:    time.Date(2026, time.January, 12, 0, 0, 0, 0, caracas),
+			lang:     LanguageEN,
+			expected: "in 3 days",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expected, formatRelativeTime(testCase.value, now, NewLocale(testCase.lang)))
+		})
+	}
+}
+
+func TestFormatter_TimeZone(t *testing.T) {
+	t.Parallel()
+
+	// 23:30 in Caracas is already the next day in Madrid
+	value := time.Date(2026, time.January, 9, 3, 30, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name     string
+		zone     string
+		expected string
+		relative string
+	}{
+		{
+			name:     "default",
+			expected: "2026-01-08 23:30 VET",
+			relative: "ayer a las 23:30",
+		},
+		{
+			name:     "madrid",
+			zone:     "Europe/Madrid",
+			expected: "2026-01-09 04:30 CET",
+			relative: "hace 8 horas",
+		},
+		{
+			name:     "miami",
+			zone:     "America/New_York",
+			expected: "2026-01-08 22:30 EST",
+			relative: "ayer a las 22:30",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			loc := NewLocale(LanguageES)
+			loc.Clock = clock.Fixed(value.Add(8 * time.Hour))
+
+			if testCase.zone != "" {
+				location, err := time.LoadLocation(testCase.zone)
+				require.NoError(t, err)
+
+				loc.Location = location
+			}
+
+			assert.Equal(t, testCase.expected, loc.formatTime(value))
+			assert.Equal(t, testCase.relative, formatRelativeTime(value, loc.now(), loc))
+		})
+	}
+}
+
+func TestFormatter_Timestamps(t *testing.T) {
+	t.Parallel()
+
+	var (
+		asOf = time.Date(2026, time.January, 8, 13, 0, 0, 0, time.UTC)
+
+		rate = fxrates.ExchangeRate{
+			Base:      types.CurrencyUSD,
+			Target:    types.CurrencyVES,
+			Rate:      36,
+			RateType:  types.RateTypeMID,
+			Source:    types.SourceBCV,
+			AsOf:      asOf,
+			FetchedAt: asOf.Add(26 * time.Hour),
+		}
+	)
+
+	loc := NewLocale(LanguageES)
+	loc.Clock = clock.Fixed(asOf.Add(26*time.Hour + 5*time.Minute))
+
+	message := FormatRate(rate, loc)
+
+	assert.Contains(t, message, "📅 Efectivo: 2026-01-08 09:00 VET (ayer a las 9:00)")
+	assert.Contains(t, message, "📥 Obtenida: 2026-01-09 11:00 VET (hace 5 minutos)")
+
+	// The fetch time is only shown when known
+	rate.FetchedAt = time.Time{}
+
+	assert.NotContains(t, FormatRate(rate, loc), "📥")
+
+	// The relative time is escaped once, along its parentheses
+	loc.Markup = markdownMarkup{}
+
+	assert.Equal(
+		t,
+		i18n.Raw(`_2026\-01\-08 09:00 VET_ \(ayer a las 9:00\)`),
+		formatTimestamp(rate.AsOf, loc),
+	)
+}
+
+func TestFormatter_Golden(t *testing.T) {
+	t.Parallel()
+
+	var (
+		rateTime = time.Date(2026, time.January, 2, 15, 4, 0, 0, time.UTC)
+
+		rate = fxrates.ExchangeRate{
+			Base:      types.CurrencyUSD,
+			Target:    types.CurrencyVES,
+			Rate:      1234.5678,
+			RateType:  types.RateTypeMID,
+			Source:    types.SourceBCV,
+			AsOf:      rateTime,
+			FetchedAt: rateTime,
+		}
+
+		rates = []fxrates.ExchangeRate{
+			rate,
+			{
+				Base:      types.CurrencyUSD,
+				Target:    types.CurrencyEUR,
+				Rate:      0.9234,
+				RateType:  types.RateTypeMID,
+				Source:    types.SourceBCV,
+				AsOf:      rateTime,
+				FetchedAt: rateTime,
+			},
+		}
+	)
+
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	require.NoError(t, err)
+
+	// EUR/USD, derived from the EUR/VES and USD/VES rates
+	eurVES := rate
+	eurVES.Base = types.CurrencyEUR
+	eurVES.Rate = 1345.6789
+
+	crossRates := deriveCrossRates([]fxrates.ExchangeRate{eurVES}, []fxrates.ExchangeRate{rate})
+	require.Len(t, crossRates, 1)
+
+	// USDT/VES buy and sell quotes of Binance, along the BCV MID rate
+	quotes := []fxrates.ExchangeRate{
+		{
+			Base:      types.CurrencyUSDT,
+			Target:    types.CurrencyVES,
+			Rate:      1240.5,
+			RateType:  types.RateTypeBUY,
+			Source:    types.SourceBinance,
+			AsOf:      rateTime,
+			FetchedAt: rateTime,
+		},
+		{
+			Base:      types.CurrencyUSDT,
+			Target:    types.CurrencyVES,
+			Rate:      1251.25,
+			RateType:  types.RateTypeSELL,
+			Source:    types.SourceBinance,
+			AsOf:      rateTime,
+			FetchedAt: rateTime,
+		},
+		{
+			Base:      types.CurrencyUSDT,
+			Target:    types.CurrencyVES,
+			Rate:      1234.5678,
+			RateType:  types.RateTypeMID,
+			Source:    types.SourceBCV,
+			AsOf:      rateTime,
+			FetchedAt: rateTime,
+		},
+	}
+
+	// VES/USD, inverted from the published USD/VES rate
+	inverted, ok := invertRate(rate)
+	require.True(t, ok)
+
+	// Every user-facing message, with inputs that exercise the escaping rules
+	messages := []struct {
+		name   string
+		render func(Locale) string
+	}{
+		{"FormatRate", func(loc Locale) string { return FormatRate(rate, loc) }},
+		{"FormatRates", func(loc Locale) string { return FormatRates(rates, loc) }},
+		{"FormatRates empty", func(loc Locale) string { return FormatRates(nil, loc) }},
+		{"FormatCurrencies", func(loc Locale) string {
+			return FormatCurrencies([]fxrates.Currency{types.CurrencyUSD, types.CurrencyVES}, loc)
+		}},
+		{"StartMessage", StartMessage},
+		{"HelpMessage", func(loc Locale) string { return HelpMessage(DefaultShortcuts(), loc) }},
+		{"ErrorMessage", func(loc Locale) string {
+			return ErrorMessage(errors.New("unexpected <status> code: 500 (*_*)"), loc)
+		}},
+		{"InvalidUsageMessage", func(loc Locale) string {
+			return InvalidUsageMessage(translate(loc.Language, "usage.rate", nil), loc)
+		}},
+		{"NoRatesForPairMessage", func(loc Locale) string {
+			return NoRatesForPairMessage(types.CurrencyUSD, types.CurrencyVES, loc)
+		}},
+		{"NoRatesForBaseMessage", func(loc Locale) string {
+			return NoRatesForBaseMessage(types.CurrencyUSD, loc)
+		}},
+		{"UnknownCurrencyMessage", func(loc Locale) string {
+			return UnknownCurrencyMessage("U$D", []fxrates.Currency{types.CurrencyUSD, types.CurrencyUSDT}, loc)
+		}},
+		{"UnknownCurrencyMessage no suggestions", func(loc Locale) string {
+			return UnknownCurrencyMessage("`x`", nil, loc)
+		}},
+		{"NumberFormatMessage", NumberFormatMessage},
+		{"NewRateMessage", func(loc Locale) string { return NewRateMessage(rate, 1200, loc) }},
+		{"PopularPairsMessage", func(loc Locale) string {
+			return PopularPairsMessage([]analytics.PairPopularity{
+				{Base: "USD", Target: "VES", Count: 3, LastChosenAt: rateTime},
+				{Base: "USD_T", Target: "VES", Count: 1, LastChosenAt: rateTime},
+			}, loc)
+		}},
+		{"PopularPairsMessage empty", func(loc Locale) string { return PopularPairsMessage(nil, loc) }},
+		{"StatsMessage", func(loc Locale) string {
+			return StatsMessage(analytics.Usage{
+				StartedAt:     rateTime,
+				Commands:      map[string]int{"/tasa": 5, "/tasa_bcv": 2},
+				InlineQueries: 7,
+				InlineChosen:  3,
+			}, 2, loc)
+		}},
+		{"StatsMessage no commands", func(loc Locale) string {
+			return StatsMessage(analytics.Usage{StartedAt: rateTime}, 0, loc)
+		}},
+		{"UpstreamMessage healthy", func(loc Locale) string {
+			return UpstreamMessage(nil, 123*time.Millisecond, fxrates.CircuitStatus{State: fxrates.CircuitClosed}, loc)
+		}},
+		{"UpstreamMessage unhealthy", func(loc Locale) string {
+			return UpstreamMessage(
+				errors.New("dial tcp: i/o timeout (*_*)"),
+				1500*time.Millisecond,
+				fxrates.CircuitStatus{State: fxrates.CircuitOpen, ConsecutiveFailures: 5, OpenedAt: rateTime},
+				loc,
+			)
+		}},
+		{"BroadcastStartedMessage", func(loc Locale) string {
+			return BroadcastStartedMessage(broadcast.Report{Pending: 12}, loc)
+		}},
+		{"BroadcastDoneMessage", func(loc Locale) string {
+			return BroadcastDoneMessage(broadcast.Report{Sent: 10, Failed: 1, Blocked: 1}, loc)
+		}},
+		{"BroadcastDryRunMessage", func(loc Locale) string {
+			return BroadcastDryRunMessage(broadcast.Report{Pending: 12, Blocked: 2}, loc)
+		}},
+		{"BroadcastInterruptedMessage", func(loc Locale) string {
+			return BroadcastInterruptedMessage(
+				broadcast.Report{Sent: 4, Pending: 8},
+				errors.New("context canceled (shutdown_now)"),
+				loc,
+			)
+		}},
+		{"BroadcastStatusMessage", func(loc Locale) string {
+			return BroadcastStatusMessage(broadcast.Report{StartedAt: rateTime, Sent: 4, Failed: 1, Pending: 7}, true, loc)
+		}},
+		{"BroadcastStatusMessage none", func(loc Locale) string {
+			return BroadcastStatusMessage(broadcast.Report{}, false, loc)
+		}},
+		{"UsageStatsMessage", func(loc Locale) string {
+			return UsageStatsMessage(analytics.Summary{
+				Busiest:          analytics.Count{Key: "2026-01-02", Count: 4},
+				Commands:         []analytics.Count{{Key: "/tasa", Count: 12}, {Key: "/tasa_bcv", Count: 3}},
+				Pairs:            []analytics.Count{{Key: "USD/VES", Count: 9}, {Key: "USD_T/VES", Count: 2}},
+				Languages:        []analytics.Count{{Key: "es", Count: 5}},
+				ChatTypes:        []analytics.Count{{Key: "private", Count: 4}, {Key: "super-group", Count: 1}},
+				Days:             7,
+				ActiveChats:      5,
+				DailyActiveChats: 12,
+				Updates:          21,
+			}, loc)
+		}},
+		{"UsageStatsMessage empty", func(loc Locale) string {
+			return UsageStatsMessage(analytics.Summary{Days: 7}, loc)
+		}},
+		{"PinnedRateMessage", func(loc Locale) string {
+			return PinnedRateMessage(rate, rateTime.Add(time.Minute), loc)
+		}},
+		{"LiveRatesMessage", func(loc Locale) string {
+			return LiveRatesMessage(rates, rateTime.Add(time.Minute), loc)
+		}},
+		{"AnnouncementsMessage enabled", func(loc Locale) string { return AnnouncementsMessage(true, loc) }},
+		{"AnnouncementsMessage disabled", func(loc Locale) string { return AnnouncementsMessage(false, loc) }},
+		{"TimeZoneMessage", TimeZoneMessage},
+		{"TimeZoneMessage madrid", func(loc Locale) string {
+			loc.Location = madrid
+
+			return TimeZoneMessage(loc)
+		}},
+		{"FormatCrossRate", func(loc Locale) string { return FormatCrossRate(crossRates[0], loc) }},
+		{"FormatInvertedRate", func(loc Locale) string { return FormatInvertedRate(inverted, loc) }},
+		{"FormatQuotes", func(loc Locale) string { return FormatQuotes(quotes, loc) }},
+		{"FormatQuotes empty", func(loc Locale) string { return FormatQuotes(nil, loc) }},
+	}
+
+	modes := []struct {
+		name string
+		mode models.ParseMode
+	}{
+		{"plain", ""},
+		{"markdownv2", models.ParseModeMarkdown},
+		{"html", models.ParseModeHTML},
+	}
+
+	for _, mode := range modes {
+		t.Run(mode.name, func(t *testing.T) {
+			t.Parallel()
+
+			markup, err := MarkupFor(mode.mode)
+			require.NoError(t, err)
+
+			loc := NewLocale(LanguageES)
+			loc.Markup = markup
+			loc.Clock = clock.Fixed(rateTime.Add(5 * time.Minute))
+
+			var sb strings.Builder
+
+			for _, message := range messages {
+				sb.WriteString("=== " + message.name + "\n")
+				sb.WriteString(message.render(loc) + "\n\n")
+			}
+
+			path := filepath.Join("testdata", "messages."+mode.name+".golden")
+
+			if *updateGolden {
+				require.NoError(t, os.WriteFile(path, []byte(sb.String()), 0o600))
+			}
+
+			expected, err := os.ReadFile(path)
+			require.NoError(t, err)
+
+			assert.Equal(t, string(expected), sb.String())
+		})
+	}
+}
@@ -1,191 +1,358 @@
 package bot
 
 import (
+	"cmp"
 	"context"
-	"fmt"
+	"errors"
 	"log/slog"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"github.com/sig-0/fxrates/provider/currencies"
-	"github.com/sig-0/fxrates/provider/ves"
-	"github.com/sig-0/fxrates/storage/types"
 
+	"github.com/sig-0/chigui-cifras/internal/analytics"
+	"github.com/sig-0/chigui-cifras/internal/broadcast"
+	"github.com/sig-0/chigui-cifras/internal/calendar"
+	"github.com/sig-0/chigui-cifras/internal/clock"
+	"github.com/sig-0/chigui-cifras/internal/freshness"
 	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/preference"
+	"github.com/sig-0/chigui-cifras/internal/store"
 )
 
 // FxHandler holds command handler and their dependencies
 type FxHandler struct {
-	fxClient *fxrates.Client
-	logger   *slog.Logger
+	runtime     atomic.Pointer[runtimeSettings]
+	fxClient    *fxrates.Client
+	store       *store.Store
+	resolver    *currencyResolver
+	tracker     *analytics.Tracker
+	stats       *analytics.Aggregator
+	broadcaster *broadcast.Broadcaster
+	queue       *broadcast.Queue
+	reload      ReloadFunc
+	shortcuts   []Shortcut
+	clock       clock.Clock
+	logger      *slog.Logger
+}
+
+// runtimeSettings holds the handler settings that can be reloaded while running
+type runtimeSettings struct {
+	markup      Markup
+	admins      map[int64]struct{}
+	inlineCache InlineCacheSettings
+	calendar    *calendar.Calendar
+	freshness   *freshness.Policy
+	preferences preference.Policy
+	location    *time.Location
+}
+
+// newRuntimeSettings validates and prepares the reloadable settings
+func newRuntimeSettings(settings Settings) (*runtimeSettings, error) {
+	markup, err := MarkupFor(settings.ParseMode)
+	if err != nil {
+		return nil, err
+	}
+
+	admins := make(map[int64]struct{}, len(settings.AdminUserIDs))
+	for _, userID := range settings.AdminUserIDs {
+		admins[userID] = struct{}{}
+	}
+
+	// Without a holidays file, only weekends are non-business days
+	bankingCalendar := settings.Calendar
+	if bankingCalendar == nil {
+		bankingCalendar, _ = calendar.New(nil)
+	}
+
+	preferences := preference.Default()
+	if settings.Preferences != nil {
+		preferences = *settings.Preferences
+	}
+
+	return &runtimeSettings{
+		markup:      markup,
+		admins:      admins,
+		inlineCache: settings.InlineCache,
+		calendar:    bankingCalendar,
+		freshness:   settings.Freshness,
+		preferences: preferences,
+		location:    cmp.Or(settings.TimeZone, defaultLocation),
+	}, nil
 }
 
 // NewHandlers creates a new FxHandler instance
-func NewHandlers(fxClient *fxrates.Client, logger *slog.Logger) *FxHandler {
-	return &FxHandler{
-		fxClient: fxClient,
-		logger:   logger,
+func NewHandlers(
+	fxClient *fxrates.Client,
+	chatStore *store.Store,
+	logger *slog.Logger,
+	settings Settings,
+) (*FxHandler, error) {
+	runtime, err := newRuntimeSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	clk := clock.Or(settings.Clock)
+
+	resolver := newCurrencyResolver(fxClient, logger, clk)
+	resolver.setTTL(settings.CurrencyCacheTTL)
+
+	tracker := settings.Analytics
+	if tracker == nil {
+		tracker = analytics.NewTracker(clk)
 	}
+
+	// Broadcasts and new rate announcements share the pacing, to respect Telegram's limits together
+	queue := broadcast.NewQueue(logger, settings.BroadcastInterval, clk)
+
+	// Broadcasts go to the chats in the store, so there's nothing to send without one
+	var broadcaster *broadcast.Broadcaster
+	if chatStore != nil {
+		broadcaster = broadcast.New(chatStore, logger, queue, clk)
+	}
+
+	// An empty list disables the shortcuts, unlike a nil one
+	shortcuts := settings.Shortcuts
+	if shortcuts == nil {
+		shortcuts = DefaultShortcuts()
+	}
+
+	h := &FxHandler{
+		fxClient:    fxClient,
+		store:       chatStore,
+		resolver:    resolver,
+		tracker:     tracker,
+		stats:       settings.UsageStats,
+		broadcaster: broadcaster,
+		queue:       queue,
+		reload:      settings.Reload,
+		shortcuts:   shortcuts,
+		clock:       clk,
+		logger:      logger,
+	}
+
+	h.runtime.Store(runtime)
+
+	return h, nil
+}
+
+// settings returns the current reloadable settings
+func (h *FxHandler) settings() *runtimeSettings {
+	return h.runtime.Load()
 }
 
 // Start handles the /inicio command
 func (h *FxHandler) Start(ctx context.Context, b *bot.Bot, update *models.Update) {
-	lang := h.languageForCommand(update.Message.Text)
+	loc := h.commandLocale(update)
 
-	h.reply(ctx, b, update, StartMessage(lang))
+	h.reply(ctx, b, update, StartMessage(loc))
 }
 
 // Help handles the /ayuda command
 func (h *FxHandler) Help(ctx context.Context, b *bot.Bot, update *models.Update) {
-	lang := h.languageForCommand(update.Message.Text)
+	loc := h.commandLocale(update)
 
-	h.reply(ctx, b, update, HelpMessage(lang))
+	h.reply(ctx, b, update, HelpMessage(h.shortcuts, loc))
 }
 
 // Rate handles the /tasa command
 func (h *FxHandler) Rate(ctx context.Context, b *bot.Bot, update *models.Update) {
-	lang := h.languageForCommand(update.Message.Text)
+	loc := h.commandLocale(update)
 
-	args := h.parseArgs(update.Message.Text)
+	args := h.parseArgs(updateMessage(update).Text)
 
 	if len(args) < 1 {
-		usage := "/tasa <base> [destino]"
-		if lang == LanguageEN {
-			usage = "/rate <base> [target]"
-		}
+		h.reply(ctx, b, update, InvalidUsageMessage(translate(loc.Language, "usage.rate", nil), loc))
 
-		h.reply(ctx, b, update, InvalidUsageMessage(usage, lang))
+		return
+	}
 
+	base, ok := h.resolveCurrency(ctx, b, update, args[0], loc)
+	if !ok {
 		return
 	}
 
-	base := strings.ToUpper(args[0])
-	target := currencies.VES.String()
+	target := currencies.VES
 
 	if len(args) >= 2 {
-		target = strings.ToUpper(args[1])
+		if target, ok = h.resolveCurrency(ctx, b, update, args[1], loc); !ok {
+			return
+		}
 	}
 
-	source := sourceForCurrency(fxrates.Currency(base))
+	h.recordEvent(analytics.Event{Base: base.String(), Target: target.String()})
 
-	rates, err := h.fxClient.Rate(ctx, base, target, source.String())
-	if err != nil {
-		h.reply(ctx, b, update, ErrorMessage(err, lang))
+	source := h.settings().preferences.Source(base)
 
-		return
+	rates, err := h.fxClient.Rate(ctx, base.String(), target.String(), source.String())
+	if err == nil {
+		if h.replyRates(ctx, b, update, rates.Results, loc) {
+			return
+		}
 	}
 
-	rate := selectPreferredRate(rates.Results)
-	if rate == nil {
-		if lang == LanguageEN {
-			h.reply(ctx, b, update, "No rates found for "+base+"/"+target)
-		} else {
-			h.reply(ctx, b, update, "No se encontraron tasas para "+base+"/"+target)
-		}
+	// Without a direct rate, invert the pair published the other way around
+	if inverted, invertErr := h.preferredInvertedRate(ctx, base, target); invertErr == nil {
+		h.reply(ctx, b, update, FormatInvertedRate(*inverted, loc))
 
 		return
 	}
 
-	h.reply(ctx, b, update, FormatRate(*rate, lang))
+	// Otherwise, derive the pair through VES
+	cross, crossErr := h.preferredCrossRate(ctx, base, target)
+
+	switch {
+	case crossErr == nil:
+		h.reply(ctx, b, update, FormatCrossRate(*cross, loc))
+	case err != nil:
+		h.reply(ctx, b, update, ErrorMessage(err, loc))
+	default:
+		h.reply(ctx, b, update, NoRatesForPairMessage(base, target, loc))
+	}
 }
 
 // Rates handles the /tasas command
 func (h *FxHandler) Rates(ctx context.Context, b *bot.Bot, update *models.Update) {
-	lang := h.languageForCommand(update.Message.Text)
+	loc := h.commandLocale(update)
 
-	args := h.parseArgs(update.Message.Text)
+	args := h.parseArgs(updateMessage(update).Text)
 
 	if len(args) < 1 {
-		usage := "/tasas <base>"
-		if lang == LanguageEN {
-			usage = "/rates <base>"
-		}
-
-		h.reply(ctx, b, update, InvalidUsageMessage(usage, lang))
+		h.reply(ctx, b, update, InvalidUsageMessage(translate(loc.Language, "usage.rates", nil), loc))
 
 		return
 	}
 
-	base := strings.ToUpper(args[0])
+	base, ok := h.resolveCurrency(ctx, b, update, args[0], loc)
+	if !ok {
+		return
+	}
 
-	rates, err := h.fxClient.Rates(ctx, base)
+	rates, err := h.fxClient.Rates(ctx, base.String())
 	if err != nil {
-		h.reply(ctx, b, update, ErrorMessage(err, lang))
+		h.reply(ctx, b, update, ErrorMessage(err, loc))
 
 		return
 	}
 
 	if len(rates.Results) == 0 {
-		if lang == LanguageEN {
-			h.reply(ctx, b, update, "No rates found for "+base)
-		} else {
-			h.reply(ctx, b, update, "No se encontraron tasas para "+base)
-		}
+		h.reply(ctx, b, update, NoRatesForBaseMessage(base, loc))
 
 		return
 	}
 
-	h.reply(ctx, b, update, FormatRates(rates.Results, lang))
+	h.reply(ctx, b, update, FormatRates(rates.Results, loc))
 }
 
 // Currencies handles the /monedas command
 func (h *FxHandler) Currencies(ctx context.Context, b *bot.Bot, update *models.Update) {
-	lang := h.languageForCommand(update.Message.Text)
+	loc := h.commandLocale(update)
 
 	availableCurrencies, err := h.fxClient.Currencies(ctx)
 	if err != nil {
-		h.reply(ctx, b, update, ErrorMessage(err, lang))
+		h.reply(ctx, b, update, ErrorMessage(err, loc))
 
 		return
 	}
 
-	h.reply(ctx, b, update, FormatCurrencies(availableCurrencies.Results, lang))
+	h.reply(ctx, b, update, FormatCurrencies(availableCurrencies.Results, loc))
 }
 
-// Dolar handles the /dolar shortcut
-func (h *FxHandler) Dolar(ctx context.Context, b *bot.Bot, update *models.Update) {
-	h.rateShortcut(ctx, b, update, currencies.USD.String())
-}
+// NumberFormat handles the /formato command, overriding the number format for the chat
+func (h *FxHandler) NumberFormat(ctx context.Context, b *bot.Bot, update *models.Update) {
+	loc := h.commandLocale(update)
+	message := updateMessage(update)
+	chatID := message.Chat.ID
 
-// Euro handles the /euro shortcut
-func (h *FxHandler) Euro(ctx context.Context, b *bot.Bot, update *models.Update) {
-	h.rateShortcut(ctx, b, update, currencies.EUR.String())
-}
+	args := h.parseArgs(message.Text)
+	if len(args) < 1 {
+		h.reply(ctx, b, update, InvalidUsageMessage(translate(loc.Language, "usage.format", nil), loc))
 
-// USDT handles the /usdt shortcut, showing both BUY and SELL rates
-func (h *FxHandler) USDT(ctx context.Context, b *bot.Bot, update *models.Update) {
-	target := currencies.VES.String()
+		return
+	}
 
-	rates, err := h.fxClient.Rate(ctx, currencies.USDT.String(), target, "")
-	if err != nil {
-		h.reply(ctx, b, update, ErrorMessage(err, LanguageES))
+	input := strings.ToLower(args[0])
+
+	name, ok := numberFormatAliases[input]
+	if !ok && !isFormatResetArgument(input) {
+		h.reply(ctx, b, update, InvalidUsageMessage(translate(loc.Language, "usage.format", nil), loc))
 
 		return
 	}
 
-	if len(rates.Results) == 0 {
-		h.reply(ctx, b, update, "No se encontraron tasas para USDT/"+target)
+	if h.store == nil {
+		h.reply(ctx, b, update, ErrorMessage(errStoreNotConfigured, loc))
 
 		return
 	}
 
-	h.reply(ctx, b, update, FormatRates(rates.Results, LanguageES))
-}
+	err := h.store.UpdateChat(chatID, func(chat *store.Chat) {
+		chat.NumberFormat = name
+	})
+	if err != nil {
+		h.logger.Error("unable to save number format",
+			"chat_id", chatID,
+			"error", err,
+		)
 
-// Rublo handles the /rublo shortcut
-func (h *FxHandler) Rublo(ctx context.Context, b *bot.Bot, update *models.Update) {
-	h.rateShortcut(ctx, b, update, currencies.RUB.String())
-}
+		h.reply(ctx, b, update, ErrorMessage(err, loc))
 
-// Lira handles the /lira shortcut
-func (h *FxHandler) Lira(ctx context.Context, b *bot.Bot, update *models.Update) {
-	h.rateShortcut(ctx, b, update, currencies.TRY.String())
+		return
+	}
+
+	h.reply(ctx, b, update, NumberFormatMessage(h.localeFor(chatID, loc.Language)))
 }
 
-// Yuan handles the /yuan shortcut
-func (h *FxHandler) Yuan(ctx context.Context, b *bot.Bot, update *models.Update) {
-	h.rateShortcut(ctx, b, update, currencies.CNY.String())
+// TimeZone handles the /zona command, overriding the time zone times are displayed in for the chat
+func (h *FxHandler) TimeZone(ctx context.Context, b *bot.Bot, update *models.Update) {
+	loc := h.commandLocale(update)
+	message := updateMessage(update)
+	chatID := message.Chat.ID
+
+	args := h.parseArgs(message.Text)
+	if len(args) < 1 {
+		h.reply(ctx, b, update, InvalidUsageMessage(translate(loc.Language, "usage.time_zone", nil), loc))
+
+		return
+	}
+
+	// IANA names are case sensitive, like "America/Madrid"
+	name := args[0]
+	if isTimeZoneResetArgument(strings.ToLower(name)) {
+		name = ""
+	}
+
+	if _, err := time.LoadLocation(name); err != nil || name == "Local" {
+		h.reply(ctx, b, update, InvalidUsageMessage(translate(loc.Language, "usage.time_zone", nil), loc))
+
+		return
+	}
+
+	if h.store == nil {
+		h.reply(ctx, b, update, ErrorMessage(errStoreNotConfigured, loc))
+
+		return
+	}
+
+	err := h.store.UpdateChat(chatID, func(chat *store.Chat) {
+		chat.TimeZone = name
+	})
+	if err != nil {
+		h.logger.Error("unable to save time zone",
+			"chat_id", chatID,
+			"error", err,
+		)
+
+		h.reply(ctx, b, update, ErrorMessage(err, loc))
+
+		return
+	}
+
+	h.reply(ctx, b, update, TimeZoneMessage(h.localeFor(chatID, loc.Language)))
 }
 
 // InlineQuery handles inline mode requests
@@ -199,62 +366,254 @@ func (h *FxHandler) InlineQuery(ctx context.Context, b *bot.Bot, update *models.
 
 	base, target, ok := parseInlineQuery(inlineQuery.Query)
 	if !ok {
-		h.answerInlineHelp(ctx, b, inlineQuery, lang)
+		h.answerInlinePairs(ctx, b, inlineQuery, lang, h.suggestedPairs())
 
 		return
 	}
 
-	source := sourceForCurrency(fxrates.Currency(base))
+	if target == inlineWildcard {
+		h.answerInlineListing(ctx, b, inlineQuery, lang, base)
+
+		return
+	}
+
+	pairs, exact := h.completePairs(ctx, base, target)
+	if !exact {
+		h.answerInlinePairs(ctx, b, inlineQuery, lang, pairs)
+
+		return
+	}
+
+	pair := pairs[0]
+
+	// Inline queries come from a user, whose private chat shares their ID
+	loc := h.localeFor(inlineQuery.From.ID, lang)
+
+	data, err := h.fetchInlineRates(ctx, pair.base, pair.target)
+	if err != nil || (len(data.pair) == 0 && len(data.inverse) == 0) {
+		// Without a direct or inverse rate, derive the pair through VES
+		if derived, crossErr := h.fetchCrossRates(ctx, pair.base, pair.target); crossErr == nil {
+			h.answerInlineResults(ctx, b, inlineQuery, h.settings().inlineCache.Rates, crossResults(derived, loc))
+
+			return
+		}
+	}
 
-	rates, err := h.fxClient.Rate(ctx, base, target, source.String())
 	if err != nil {
 		h.answerInlineError(ctx, b, inlineQuery, lang)
 
 		return
 	}
 
-	rate := selectPreferredRate(rates.Results)
-	if rate == nil {
-		h.answerInlineEmpty(ctx, b, inlineQuery, lang, base, target)
+	if len(data.pair) == 0 && len(data.inverse) == 0 {
+		h.answerInlineEmpty(ctx, b, inlineQuery, lang, NoRatesForPairMessage(pair.base, pair.target, NewLocale(lang)))
 
 		return
 	}
 
-	title := fmt.Sprintf("%s/%s", rate.Base, rate.Target)
-	description := fmt.Sprintf("%.4f (%s, %s)", rate.Rate, rate.Source, rate.RateType)
-	message := FormatRate(*rate, lang)
+	h.answerInlineResults(ctx, b, inlineQuery, h.settings().inlineCache.Rates, inlineResults(data, loc))
+}
 
-	h.answerInlineResults(ctx, b, inlineQuery, []models.InlineQueryResult{
-		&models.InlineQueryResultArticle{
-			ID:          inlineResultID(title),
-			Title:       title,
-			Description: description,
-			InputMessageContent: &models.InputTextMessageContent{
-				MessageText: message,
-			},
-		},
+// ChosenInlineResult records the inline results users share in their chats.
+// Telegram only sends them if inline feedback is enabled for the bot
+func (h *FxHandler) ChosenInlineResult(_ context.Context, _ *bot.Bot, update *models.Update) {
+	chosen := update.ChosenInlineResult
+	if chosen == nil {
+		return
+	}
+
+	base, target, ok := parseResultID(chosen.ResultID)
+	if !ok {
+		return
+	}
+
+	h.tracker.RecordInlineChoice(analytics.InlineChoice{
+		ChosenAt: h.clock.Now(),
+		Base:     base.String(),
+		Target:   target.String(),
+		Language: string(h.languageForUser(&chosen.From)),
 	})
-}
 
-func (h *FxHandler) rateShortcut(ctx context.Context, b *bot.Bot, update *models.Update, base string) {
-	target := currencies.VES.String()
-	source := sourceForCurrency(fxrates.Currency(base))
+	// Listings have no single target
+	if target != inlineWildcard {
+		h.recordEvent(analytics.Event{Base: base.String(), Target: target.String()})
+	}
+}
 
-	rates, err := h.fxClient.Rate(ctx, base, target, source.String())
-	if err != nil {
-		h.reply(ctx, b, update, ErrorMessage(err, LanguageES))
+// replyRates replies with the pair's preferred rate, or with every source's BUY and SELL rates
+// if there are any. It reports false if there are no rates to reply with
+func (h *FxHandler) replyRates(
+	ctx context.Context,
+	b *bot.Bot,
+	update *models.Update,
+	rates []fxrates.ExchangeRate,
+	loc Locale,
+) bool {
+	if hasBuyAndSell(rates) {
+		h.reply(ctx, b, update, FormatQuotes(rates, loc))
 
-		return
+		return true
 	}
 
-	rate := selectPreferredRate(rates.Results)
+	rate := loc.preferences().Select(rates)
 	if rate == nil {
-		h.reply(ctx, b, update, "No se encontraron tasas para "+base+"/"+target)
+		return false
+	}
+
+	h.reply(ctx, b, update, FormatRate(*rate, loc))
+
+	return true
+}
+
+// resolveCurrency resolves a user-provided currency argument,
+// replying with suggestions if it can't be resolved
+func (h *FxHandler) resolveCurrency(
+	ctx context.Context,
+	b *bot.Bot,
+	update *models.Update,
+	input string,
+	loc Locale,
+) (fxrates.Currency, bool) {
+	currency, err := h.resolver.Resolve(ctx, input)
+	if err == nil {
+		return currency, true
+	}
 
+	var unknownErr *UnknownCurrencyError
+	if errors.As(err, &unknownErr) {
+		h.reply(ctx, b, update, UnknownCurrencyMessage(unknownErr.Input, unknownErr.Suggestions, loc))
+	} else {
+		h.reply(ctx, b, update, ErrorMessage(err, loc))
+	}
+
+	return "", false
+}
+
+// commandLocale returns the locale for a command message,
+// based on the command language and the chat settings
+func (h *FxHandler) commandLocale(update *models.Update) Locale {
+	message := updateMessage(update)
+
+	return h.localeFor(message.Chat.ID, h.languageForCommand(message.Text))
+}
+
+// localeFor returns the locale for the chat, applying any persisted overrides
+func (h *FxHandler) localeFor(chatID int64, lang Language) Locale {
+	loc := NewLocale(lang)
+	loc.Markup = h.settings().markup
+	loc.Calendar = h.settings().calendar
+	loc.Freshness = h.settings().freshness
+	loc.Preferences = &h.settings().preferences
+	loc.Location = h.settings().location
+	loc.Clock = h.clock
+
+	if h.store == nil {
+		return loc
+	}
+
+	chat, ok := h.store.Chat(chatID)
+	if !ok {
+		return loc
+	}
+
+	if style, ok := numberStyleByName(chat.NumberFormat); ok {
+		loc.Numbers = style
+	}
+
+	if chat.TimeZone != "" {
+		if location, err := time.LoadLocation(chat.TimeZone); err == nil {
+			loc.Location = location
+		}
+	}
+
+	return loc
+}
+
+// trackUpdate records the update in the usage counters, counting only
+// the registered commands, and registers the chat the first time it's seen
+func (h *FxHandler) trackUpdate(update *models.Update, commands map[string]struct{}) {
+	message := updateMessage(update)
+
+	switch {
+	case message != nil:
+		chat := message.Chat
+		event := analytics.Event{
+			ChatType: string(chat.Type),
+			ChatID:   chat.ID,
+			Update:   true,
+		}
+
+		if command := h.commandName(message.Text); command != "" {
+			if _, ok := commands[command]; ok {
+				h.tracker.RecordCommand(command)
+
+				event.Command = command
+				event.Language = string(h.languageForCommand(message.Text))
+			}
+		}
+
+		h.recordEvent(event)
+
+		if h.store == nil {
+			return
+		}
+
+		registered, err := h.store.RegisterChat(chat.ID, string(chat.Type), h.clock.Now())
+		if err != nil {
+			h.logger.Error("unable to register chat", "chat_id", chat.ID, "error", err)
+
+			return
+		}
+
+		if registered {
+			h.logger.Info("registered new chat", "chat_id", chat.ID, "type", chat.Type)
+		}
+	case update.MyChatMember != nil:
+		h.registerMembership(update.MyChatMember)
+	case update.InlineQuery != nil:
+		h.tracker.RecordInlineQuery()
+
+		// Inline queries come from users, not chats
+		h.recordEvent(analytics.Event{
+			Language: string(h.languageForInline(update.InlineQuery)),
+			ChatType: inlineChatType,
+			Update:   true,
+		})
+	}
+}
+
+// recordEvent records the event in the daily usage statistics, if enabled
+func (h *FxHandler) recordEvent(event analytics.Event) {
+	if h.stats == nil {
 		return
 	}
 
-	h.reply(ctx, b, update, FormatRate(*rate, LanguageES))
+	if event.At.IsZero() {
+		event.At = h.clock.Now()
+	}
+
+	h.stats.Record(event)
+}
+
+// isFormatResetArgument checks if the argument resets the chat number format,
+// back to the one of its language
+func isFormatResetArgument(arg string) bool {
+	switch arg {
+	case "auto", "idioma", "language", "reset":
+		return true
+	default:
+		return false
+	}
+}
+
+// isTimeZoneResetArgument checks if the argument resets the chat time zone to the default one
+func isTimeZoneResetArgument(arg string) bool {
+	switch arg {
+	case "auto", "reset":
+		return true
+	default:
+		return false
+	}
 }
 
 func (h *FxHandler) parseArgs(text string) []string {
@@ -282,91 +641,162 @@ func (h *FxHandler) commandName(text string) string {
 
 func (h *FxHandler) languageForCommand(text string) Language {
 	switch h.commandName(text) {
-	case "/start", "/help", "/rate", "/rates", "/currencies":
+	case "/start", "/help", "/rate", "/rates", "/currencies", "/format", "/timezone", "/pin", "/alerts":
 		return LanguageEN
+	case "/iniciar", "/ajuda", "/taxa", "/taxas", "/moedas", "/fuso", "/fixar", "/alertas":
+		return LanguagePT
 	default:
 		return LanguageES
 	}
 }
 
 func (h *FxHandler) languageForInline(query *models.InlineQuery) Language {
-	if query == nil || query.From == nil {
+	if query == nil {
 		return LanguageES
 	}
 
-	if strings.HasPrefix(strings.ToLower(query.From.LanguageCode), "en") {
-		return LanguageEN
+	return h.languageForUser(query.From)
+}
+
+// languageForUser returns the language matching the user's Telegram client
+func (h *FxHandler) languageForUser(user *models.User) Language {
+	if user == nil {
+		return LanguageES
 	}
 
-	return LanguageES
+	languageCode := strings.ToLower(user.LanguageCode)
+
+	switch {
+	case strings.HasPrefix(languageCode, "en"):
+		return LanguageEN
+	case strings.HasPrefix(languageCode, "pt"):
+		return LanguagePT
+	default:
+		return LanguageES
+	}
 }
 
 func (h *FxHandler) reply(ctx context.Context, b *bot.Bot, update *models.Update, text string) {
+	message := updateMessage(update)
+	if message == nil {
+		return
+	}
+
 	h.logger.Debug("sending reply",
-		"chat_id", update.Message.Chat.ID,
+		"chat_id", message.Chat.ID,
 		"text_length", len(text),
 	)
 
 	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID: update.Message.Chat.ID,
-		Text:   text,
+		ChatID:    message.Chat.ID,
+		Text:      text,
+		ParseMode: h.settings().markup.ParseMode(),
 	})
 	if err != nil {
 		h.logger.Error("failed to send message",
-			"chat_id", update.Message.Chat.ID,
+			"chat_id", message.Chat.ID,
 			"error", err,
 		)
 	}
 }
 
+// updateMessage returns the message of the update, either sent to a chat
+// or posted in a channel, if any
+func updateMessage(update *models.Update) *models.Message {
+	switch {
+	case update.Message != nil:
+		return update.Message
+	case update.ChannelPost != nil:
+		return update.ChannelPost
+	default:
+		return nil
+	}
+}
+
 func (h *FxHandler) answerInlineHelp(
 	ctx context.Context,
 	b *bot.Bot,
 	query *models.InlineQuery,
 	lang Language,
 ) {
-	title := "Ayuda"
-	description := "Escribe: USD VES (destino VES por defecto)"
-	message := "Usa: USD VES o solo USD"
-
-	if lang == LanguageEN {
-		title = "Help"
-		description = "Type: USD VES (default target VES)"
-		message = "Use: USD VES or just USD"
-	}
-
-	h.answerInlineResults(ctx, b, query, []models.InlineQueryResult{
+	h.answerInlineResults(ctx, b, query, h.settings().inlineCache.Help, []models.InlineQueryResult{
 		&models.InlineQueryResultArticle{
 			ID:          "help",
-			Title:       title,
-			Description: description,
+			Title:       translate(lang, "inline.help.title", nil),
+			Description: translate(lang, "inline.help.description", nil),
 			InputMessageContent: &models.InputTextMessageContent{
-				MessageText: message,
+				MessageText: translate(lang, "inline.help.message", nil),
 			},
 		},
 	})
 }
 
-func (h *FxHandler) answerInlineEmpty(
+// answerInlinePairs answers with the preferred rate of every pair,
+// falling back to the usage help if none is available
+func (h *FxHandler) answerInlinePairs(
 	ctx context.Context,
 	b *bot.Bot,
 	query *models.InlineQuery,
 	lang Language,
-	base string,
-	target string,
+	pairs []currencyPair,
 ) {
-	title := "Sin resultados"
-	message := "No se encontraron tasas para " + base + "/" + target
+	// Inline queries come from a user, whose private chat shares their ID
+	results := h.pairResults(ctx, pairs, h.localeFor(query.From.ID, lang))
+	if len(results) == 0 {
+		h.answerInlineHelp(ctx, b, query, lang)
+
+		return
+	}
+
+	h.answerInlineResults(ctx, b, query, h.settings().inlineCache.Suggestions, results)
+}
+
+// answerInlineListing answers with every rate for the base, a page at a time
+func (h *FxHandler) answerInlineListing(
+	ctx context.Context,
+	b *bot.Bot,
+	query *models.InlineQuery,
+	lang Language,
+	input string,
+) {
+	base, err := h.resolver.Resolve(ctx, input)
+	if err != nil {
+		h.answerInlineHelp(ctx, b, query, lang)
+
+		return
+	}
 
-	if lang == LanguageEN {
-		title = "No results"
-		message = "No rates found for " + base + "/" + target
+	rates, err := h.fxClient.Rates(ctx, base.String())
+	if err != nil {
+		h.answerInlineError(ctx, b, query, lang)
+
+		return
 	}
 
-	h.answerInlineResults(ctx, b, query, []models.InlineQueryResult{
+	listing := filterBase(rates.Results, base)
+	if len(listing) == 0 {
+		h.answerInlineEmpty(ctx, b, query, lang, NoRatesForBaseMessage(base, NewLocale(lang)))
+
+		return
+	}
+
+	// Inline queries come from a user, whose private chat shares their ID
+	results, nextOffset := paginate(listingResults(listing, h.localeFor(query.From.ID, lang)), query.Offset)
+
+	h.answerInlinePage(ctx, b, query, h.settings().inlineCache.Listing, results, nextOffset)
+}
+
+func (h *FxHandler) answerInlineEmpty(
+	ctx context.Context,
+	b *bot.Bot,
+	query *models.InlineQuery,
+	lang Language,
+	message string,
+) {
+	h.answerInlineResults(ctx, b, query, h.settings().inlineCache.Errors, []models.InlineQueryResult{
 		&models.InlineQueryResultArticle{
 			ID:    "empty",
-			Title: title,
+			Title: translate(lang, "inline.empty.title", nil),
 			InputMessageContent: &models.InputTextMessageContent{
 				MessageText: message,
 			},
@@ -380,19 +810,12 @@ func (h *FxHandler) answerInlineError(
 	query *models.InlineQuery,
 	lang Language,
 ) {
-	title := "Error"
-	message := "No se pudo obtener la tasa"
-
-	if lang == LanguageEN {
-		message = "Unable to fetch the rate"
-	}
-
-	h.answerInlineResults(ctx, b, query, []models.InlineQueryResult{
+	h.answerInlineResults(ctx, b, query, h.settings().inlineCache.Errors, []models.InlineQueryResult{
 		&models.InlineQueryResultArticle{
 			ID:    "error",
-			Title: title,
+			Title: translate(lang, "inline.error.title", nil),
 			InputMessageContent: &models.InputTextMessageContent{
-				MessageText: message,
+				MessageText: translate(lang, "inline.error.message", nil),
 			},
 		},
 	})
@@ -402,7 +825,21 @@ func (h *FxHandler) answerInlineResults(
 	ctx context.Context,
 	b *bot.Bot,
 	query *models.InlineQuery,
+	cache InlineCachePolicy,
 	results []models.InlineQueryResult,
+) {
+	h.answerInlinePage(ctx, b, query, cache, results, "")
+}
+
+// answerInlinePage answers with a page of results. A non-empty next offset
+// makes Telegram request the following page once the user scrolls to the end
+func (h *FxHandler) answerInlinePage(
+	ctx context.Context,
+	b *bot.Bot,
+	query *models.InlineQuery,
+	cache InlineCachePolicy,
+	results []models.InlineQueryResult,
+	nextOffset string,
 ) {
 	if query == nil {
 		return
@@ -411,68 +848,15 @@ func (h *FxHandler) answerInlineResults(
 	_, err := b.AnswerInlineQuery(ctx, &bot.AnswerInlineQueryParams{
 		InlineQueryID: query.ID,
 		Results:       results,
-		CacheTime:     5,
-		IsPersonal:    true,
+		NextOffset:    nextOffset,
+		CacheTime:     int(cache.CacheTime / time.Second),
+		IsPersonal:    cache.Personal,
 	})
 	if err != nil {
 		return
 	}
 }
 
-func inlineResultID(title string) string {
-	return strings.ReplaceAll(strings.ToLower(title), "/", "-")
-}
-
-// sourceForCurrency returns the preferred source for a given base currency.
-// For fiat currencies, we use BCV
-func sourceForCurrency(base fxrates.Currency) fxrates.Source {
-	switch base {
-	case currencies.USD, currencies.EUR, currencies.RUB, currencies.TRY, currencies.CNY:
-		return ves.BCVSource
-	default:
-		return ""
-	}
-}
-
-// selectPreferredRate selects the best rate from the results based on the currency pair.
-// For fiat currencies (USD, EUR, etc.), it prefers MID rate from BCV.
-// For crypto (USDT), it prefers whatever is available (typically P2P)
-func selectPreferredRate(rates []fxrates.ExchangeRate) *fxrates.ExchangeRate {
-	if len(rates) == 0 {
-		return nil
-	}
-
-	// For single result, just return it
-	if len(rates) == 1 {
-		return &rates[0]
-	}
-
-	base := rates[0].Base
-
-	// For fiat currencies, prefer BCV MID rate
-	isFiat := base == currencies.USD || base == currencies.EUR ||
-		base == currencies.RUB || base == currencies.TRY || base == currencies.CNY
-
-	if isFiat {
-		// First try: BCV + MID
-		for i := range rates {
-			if rates[i].Source == types.SourceBCV && rates[i].RateType == types.RateTypeMID {
-				return &rates[i]
-			}
-		}
-
-		// Second try: any MID rate
-		for i := range rates {
-			if rates[i].RateType == types.RateTypeMID {
-				return &rates[i]
-			}
-		}
-	}
-
-	// Default: return first result
-	return &rates[0]
-}
-
 func parseInlineQuery(query string) (string, string, bool) {
 	normalized := strings.ToUpper(strings.TrimSpace(query))
 	if normalized == "" {
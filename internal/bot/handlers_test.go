@@ -1,16 +1,126 @@
 package bot
 
 import (
+	"context"
+	"encoding/json"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sig-0/chigui-cifras/internal/clock"
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/preference"
+	"github.com/sig-0/chigui-cifras/internal/store"
+
+	"github.com/sig-0/fxrates/storage/types"
 )
 
+// newTestHandler creates a handler with the given dependencies and settings
+func newTestHandler(t *testing.T, client *fxrates.Client, chatStore *store.Store, settings Settings) *FxHandler {
+	t.Helper()
+
+	h, err := NewHandlers(client, chatStore, slog.Default(), settings)
+	require.NoError(t, err)
+
+	return h
+}
+
+// sentMessage is a request to the fake Telegram API
+type sentMessage struct {
+	Method    string
+	Text      string
+	ParseMode string
+	MessageID string
+	ChatID    int64
+}
+
+// apiFailure makes the fake Telegram API reject the method with the description
+type apiFailure struct {
+	Method      string
+	Description string
+}
+
+// newMessageServer starts a fake Telegram API recording the requests.
+// The methods of the failures with a description are rejected
+func newMessageServer(t *testing.T, failures ...apiFailure) (*httptest.Server, <-chan sentMessage) {
+	t.Helper()
+
+	messages := make(chan sentMessage, 16)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(2 << 20); err != nil {
+			t.Errorf("parse multipart: %v", err)
+		}
+
+		var (
+			method    = strings.TrimPrefix(r.URL.Path, "/bot"+"test-token"+"/")
+			chatID, _ = strconv.ParseInt(r.FormValue("chat_id"), 10, 64)
+		)
+
+		messages <- sentMessage{
+			Method:    method,
+			Text:      r.FormValue("text"),
+			ParseMode: r.FormValue("parse_mode"),
+			MessageID: r.FormValue("message_id"),
+			ChatID:    chatID,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		response := map[string]any{
+			"ok":     true,
+			"result": map[string]any{"message_id": 10, "date": 0, "chat": map[string]any{"id": chatID}},
+		}
+
+		if method == "pinChatMessage" {
+			response = map[string]any{"ok": true, "result": true}
+		}
+
+		for _, failure := range failures {
+			if failure.Method == method && failure.Description != "" {
+				w.WriteHeader(http.StatusBadRequest)
+
+				response = map[string]any{
+					"ok":          false,
+					"error_code":  http.StatusBadRequest,
+					"description": failure.Description,
+				}
+			}
+		}
+
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("write response: %v", err)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, messages
+}
+
+// commandUpdate returns an update for a command sent by the user in their private chat
+func commandUpdate(userID int64, text string) *models.Update {
+	return &models.Update{
+		Message: &models.Message{
+			Text: text,
+			Chat: models.Chat{ID: userID, Type: models.ChatTypePrivate},
+			From: &models.User{ID: userID},
+		},
+	}
+}
+
 func TestHandler_ParseArgs(t *testing.T) {
 	t.Parallel()
 
-	h := NewHandlers(nil, slog.Default())
+	h := newTestHandler(t, nil, store.NewMemory(), Settings{})
 
 	assert.Nil(t, h.parseArgs("/rate"))
 	assert.Equal(t, []string{"USD", "VES"}, h.parseArgs("/rate USD VES"))
@@ -20,7 +130,7 @@ func TestHandler_ParseArgs(t *testing.T) {
 func TestHandler_CommandName(t *testing.T) {
 	t.Parallel()
 
-	h := NewHandlers(nil, slog.Default())
+	h := newTestHandler(t, nil, store.NewMemory(), Settings{})
 
 	assert.Equal(t, "/start", h.commandName("/start@ChiguiBot"))
 	assert.Equal(t, "/start", h.commandName("/START extra"))
@@ -30,11 +140,202 @@ func TestHandler_CommandName(t *testing.T) {
 func TestHandler_LanguageForCommand(t *testing.T) {
 	t.Parallel()
 
-	h := NewHandlers(nil, slog.Default())
+	h := newTestHandler(t, nil, store.NewMemory(), Settings{})
 
 	assert.Equal(t, LanguageEN, h.languageForCommand("/start"))
 	assert.Equal(t, LanguageEN, h.languageForCommand("/help@bot"))
 	assert.Equal(t, LanguageEN, h.languageForCommand("/rate USD VES"))
 	assert.Equal(t, LanguageES, h.languageForCommand("/tasa USD VES"))
+	assert.Equal(t, LanguagePT, h.languageForCommand("/taxa USD VES"))
+	assert.Equal(t, LanguagePT, h.languageForCommand("/ajuda"))
 	assert.Equal(t, LanguageES, h.languageForCommand("/whatever"))
 }
+
+func TestHandler_LocaleFor(t *testing.T) {
+	t.Parallel()
+
+	chatStore := store.NewMemory()
+	h := newTestHandler(t, nil, chatStore, Settings{})
+
+	assert.Equal(t, NumberStyleComma, h.localeFor(1, LanguageES).Numbers)
+
+	require.NoError(t, chatStore.UpdateChat(1, func(chat *store.Chat) {
+		chat.NumberFormat = numberFormatPoint
+	}))
+
+	loc := h.localeFor(1, LanguageES)
+
+	assert.Equal(t, LanguageES, loc.Language)
+	assert.Equal(t, NumberStylePoint, loc.Numbers)
+
+	// Other chats keep the language default
+	assert.Equal(t, NumberStyleComma, h.localeFor(2, LanguageES).Numbers)
+}
+
+func TestHandler_WithoutStore(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name    string
+		command string
+		handle  func(*FxHandler, context.Context, *bot.Bot, *models.Update)
+	}{
+		{
+			name:    "number format",
+			command: "/formato punto",
+			handle:  (*FxHandler).NumberFormat,
+		},
+		{
+			name:    "announcements",
+			command: "/avisos si",
+			handle:  (*FxHandler).Announcements,
+		},
+		{
+			name:    "time zone",
+			command: "/zona Europe/Madrid",
+			handle:  (*FxHandler).TimeZone,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := newTestHandler(t, nil, nil, Settings{})
+
+			srv, messages := newMessageServer(t)
+			b := newTelegramBot(t, srv.URL)
+
+			testCase.handle(h, context.Background(), b, commandUpdate(7, testCase.command))
+
+			assert.Contains(t, receiveMessage(t, messages).Text, errStoreNotConfigured.Error())
+		})
+	}
+}
+
+func TestHandler_TimeZone(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.January, 2, 15, 4, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name     string
+		command  string
+		expected string
+		reply    string
+	}{
+		{
+			name:     "madrid",
+			command:  "/zona Europe/Madrid",
+			expected: "Europe/Madrid",
+			reply:    "Zona horaria actualizada a Europe/Madrid. Hora actual: 2026-01-02 16:04 CET",
+		},
+		{
+			name:     "miami in english",
+			command:  "/timezone America/New_York",
+			expected: "America/New_York",
+			reply:    "Current time: 2026-01-02 10:04 EST",
+		},
+		{
+			name:     "reset",
+			command:  "/fuso auto",
+			expected: "",
+			reply:    "Hora atual: 2026-01-02 11:04 VET",
+		},
+		{
+			name:     "number format reset word",
+			command:  "/zona idioma",
+			expected: "America/Bogota",
+			reply:    "/zona <zona|auto>",
+		},
+		{
+			name:     "unknown zone",
+			command:  "/zona Marte/Olympus",
+			expected: "America/Bogota",
+			reply:    "/zona <zona|auto>",
+		},
+		{
+			name:     "host zone",
+			command:  "/zona Local",
+			expected: "America/Bogota",
+			reply:    "/zona <zona|auto>",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := newTestHandler(t, nil, store.NewMemory(), Settings{Clock: clock.Fixed(now)})
+
+			require.NoError(t, h.store.UpdateChat(7, func(chat *store.Chat) {
+				chat.TimeZone = "America/Bogota"
+			}))
+
+			srv, messages := newMessageServer(t)
+			b := newTelegramBot(t, srv.URL)
+
+			h.TimeZone(context.Background(), b, commandUpdate(7, testCase.command))
+
+			message := receiveMessage(t, messages)
+			assert.Contains(t, message.Text, testCase.reply)
+
+			chat, ok := h.store.Chat(7)
+			require.True(t, ok)
+			assert.Equal(t, testCase.expected, chat.TimeZone)
+		})
+	}
+}
+
+func TestHandler_RatePreferences(t *testing.T) {
+	t.Parallel()
+
+	parallel := usdVES
+	parallel.Rate = 45
+	parallel.Source = types.SourceBinance
+
+	testTable := []struct {
+		name        string
+		preferences *preference.Policy
+		expected    string
+	}{
+		{
+			name:        "default",
+			preferences: nil,
+			expected:    "Tasa: 40,00",
+		},
+		{
+			name: "configured",
+			preferences: &preference.Policy{
+				Currencies: map[fxrates.Currency]preference.Rule{
+					types.CurrencyUSD: {Preferences: []preference.Preference{{Source: types.SourceBinance}}},
+				},
+			},
+			expected: "Tasa: 45,00",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fxServer, _ := newInlineFXServer(t, map[string]any{
+				"/v1/rates/USD/VES": page(parallel, usdVES),
+			})
+
+			h := newTestHandler(
+				t,
+				fxrates.NewClient(fxServer.URL, time.Second),
+				store.NewMemory(),
+				Settings{Preferences: testCase.preferences},
+			)
+
+			srv, messages := newMessageServer(t)
+			b := newTelegramBot(t, srv.URL)
+
+			h.Rate(context.Background(), b, commandUpdate(1, "/tasa USD"))
+
+			assert.Contains(t, receiveMessage(t, messages).Text, testCase.expected)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package bot
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"github.com/sig-0/chigui-cifras/internal/i18n"
+)
+
+//go:embed locales/*.toml
+var localeFiles embed.FS
+
+// catalog holds the user-facing messages for every supported language
+var catalog = mustLoadCatalog()
+
+func mustLoadCatalog() *i18n.Catalog {
+	locales, err := fs.Sub(localeFiles, "locales")
+	if err != nil {
+		panic(fmt.Sprintf("unable to open locales: %v", err))
+	}
+
+	c, err := i18n.Load(locales, string(LanguageES))
+	if err != nil {
+		panic(fmt.Sprintf("unable to load message catalog: %v", err))
+	}
+
+	return c
+}
+
+// translate renders the catalog message for the given language and key
+func translate(lang Language, key string, params i18n.Params) string {
+	return catalog.Render(string(lang), key, params)
+}
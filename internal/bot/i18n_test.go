@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sig-0/chigui-cifras/internal/i18n"
+)
+
+func TestI18n_AllLanguagesLoaded(t *testing.T) {
+	t.Parallel()
+
+	locales := catalog.Locales()
+
+	for _, lang := range Languages {
+		assert.Contains(t, locales, string(lang))
+	}
+
+	assert.Len(t, locales, len(Languages))
+}
+
+func TestI18n_EveryKeyInEveryLocale(t *testing.T) {
+	t.Parallel()
+
+	reference := catalog.Keys(string(LanguageES))
+	require.NotEmpty(t, reference)
+
+	for _, lang := range Languages {
+		t.Run(string(lang), func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, reference, catalog.Keys(string(lang)))
+		})
+	}
+}
+
+func TestI18n_PlaceholdersMatch(t *testing.T) {
+	t.Parallel()
+
+	placeholder := regexp.MustCompile(`\{[a-z_]+\}`)
+
+	for _, key := range catalog.Keys(string(LanguageES)) {
+		// Rendering without params leaves the placeholders in place
+		expected := placeholder.FindAllString(catalog.Render(string(LanguageES), key, nil), -1)
+
+		for _, lang := range Languages {
+			actual := placeholder.FindAllString(catalog.Render(string(lang), key, nil), -1)
+
+			assert.ElementsMatch(t, expected, actual, "placeholders for %q in %q", key, lang)
+		}
+	}
+}
+
+func TestI18n_Translate(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Tasa: 42", translate(LanguageES, "rate.value", i18n.Params{"rate": 42}))
+	assert.Equal(t, "Rate: 42", translate(LanguageEN, "rate.value", i18n.Params{"rate": 42}))
+	assert.Equal(t, "Taxa: 42", translate(LanguagePT, "rate.value", i18n.Params{"rate": 42}))
+
+	// Unknown languages fall back to Spanish
+	assert.Equal(t, "Tasa: 42", translate(Language("de"), "rate.value", i18n.Params{"rate": 42}))
+}
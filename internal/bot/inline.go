@@ -0,0 +1,479 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/sig-0/fxrates/provider/currencies"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/i18n"
+	"github.com/sig-0/chigui-cifras/internal/preference"
+)
+
+const (
+	// emojiThumbnailBaseURL serves the Twemoji PNG assets, named by emoji code points
+	emojiThumbnailBaseURL = "https://cdn.jsdelivr.net/gh/jdecked/twemoji@15.1.0/assets/72x72/"
+
+	// emojiThumbnailSize is the size, in pixels, of the Twemoji PNG assets
+	emojiThumbnailSize = 72
+
+	// variationSelector is the emoji presentation selector, omitted from Twemoji file names
+	variationSelector = 0xFE0F
+
+	// maxInlineSuggestions is the maximum number of pairs suggested for partial input
+	maxInlineSuggestions = 6
+
+	// maxInlineResults is the maximum number of results Telegram accepts per inline answer
+	maxInlineResults = 50
+
+	// inlineWildcard is the inline query target that lists every rate for the base
+	inlineWildcard = "*"
+
+	// inlineChatType is the chat type inline queries are counted under in the usage statistics
+	inlineChatType = "inline"
+
+	// summaryResultSuffix ends the ID of the base summary inline result
+	summaryResultSuffix = "summary"
+)
+
+// currencyPair is a base and target currency
+type currencyPair struct {
+	base   fxrates.Currency
+	target fxrates.Currency
+}
+
+// popularPairs are the pairs suggested for an empty inline query until inline results are chosen,
+// the most popular first
+var popularPairs = []currencyPair{
+	{base: currencies.USD, target: currencies.VES},
+	{base: currencies.EUR, target: currencies.VES},
+	{base: currencies.USDT, target: currencies.VES},
+	{base: currencies.CNY, target: currencies.VES},
+	{base: currencies.TRY, target: currencies.VES},
+	{base: currencies.RUB, target: currencies.VES},
+}
+
+// popularityRank returns the position of the currency among the popular pair bases,
+// ranking every other currency last
+func popularityRank(currency fxrates.Currency) int {
+	for i, pair := range popularPairs {
+		if pair.base == currency {
+			return i
+		}
+	}
+
+	return len(popularPairs)
+}
+
+// suggestedPairs returns the pairs suggested for an empty inline query, ranked by how often
+// their inline results are chosen, or the static popular pairs when nothing was chosen yet
+func (h *FxHandler) suggestedPairs() []currencyPair {
+	popular := h.tracker.Popular(maxInlineSuggestions)
+	if len(popular) == 0 {
+		return popularPairs
+	}
+
+	pairs := make([]currencyPair, 0, len(popular))
+	for _, pair := range popular {
+		pairs = append(pairs, currencyPair{
+			base:   fxrates.Currency(pair.Base),
+			target: fxrates.Currency(pair.Target),
+		})
+	}
+
+	return pairs
+}
+
+// inlineRates holds the rates backing the inline results for a pair
+type inlineRates struct {
+	// pair holds every rate for the queried pair
+	pair []fxrates.ExchangeRate
+
+	// inverse holds every rate for the inverse pair, if any
+	inverse []fxrates.ExchangeRate
+
+	// summary holds every rate for the queried base, if any
+	summary []fxrates.ExchangeRate
+}
+
+// completePairs resolves the inline query currencies, completing partial input
+// into the matching pairs. It reports whether both currencies resolved exactly,
+// in which case the single returned pair is the queried one
+func (h *FxHandler) completePairs(ctx context.Context, base, target string) ([]currencyPair, bool) {
+	bases, baseExact := h.completeCurrency(ctx, base)
+	targets, targetExact := h.completeCurrency(ctx, target)
+
+	if baseExact && targetExact {
+		return []currencyPair{{base: bases[0], target: targets[0]}}, true
+	}
+
+	pairs := make([]currencyPair, 0, maxInlineSuggestions)
+
+	for _, b := range bases {
+		for _, t := range targets {
+			if b == t {
+				continue
+			}
+
+			if len(pairs) == maxInlineSuggestions {
+				return pairs, false
+			}
+
+			pairs = append(pairs, currencyPair{base: b, target: t})
+		}
+	}
+
+	return pairs, false
+}
+
+// completeCurrency resolves the currency input, falling back to prefix completion.
+// It reports whether the input resolved exactly
+func (h *FxHandler) completeCurrency(ctx context.Context, input string) ([]fxrates.Currency, bool) {
+	currency, err := h.resolver.Resolve(ctx, input)
+	if err == nil {
+		return []fxrates.Currency{currency}, true
+	}
+
+	return h.resolver.Complete(ctx, input), false
+}
+
+// pairResults builds an inline article with the preferred rate of every pair,
+// skipping the pairs whose rates can't be fetched
+func (h *FxHandler) pairResults(ctx context.Context, pairs []currencyPair, loc Locale) []models.InlineQueryResult {
+	if h.fxClient == nil {
+		return nil
+	}
+
+	var (
+		rates       = make([]*fxrates.ExchangeRate, len(pairs))
+		preferences = h.settings().preferences
+		group       errgroup.Group
+	)
+
+	for i, pair := range pairs {
+		group.Go(func() error {
+			source := preferences.Source(pair.base)
+
+			response, err := h.fxClient.Rate(ctx, pair.base.String(), pair.target.String(), source.String())
+			if err != nil {
+				h.logger.Debug("unable to fetch pair", "base", pair.base, "target", pair.target, "error", err)
+
+				return nil
+			}
+
+			rates[i] = preferences.Select(filterPair(response.Results, pair.base, pair.target))
+
+			return nil
+		})
+	}
+
+	_ = group.Wait()
+
+	results := make([]models.InlineQueryResult, 0, len(pairs))
+
+	for _, rate := range rates {
+		if rate != nil {
+			results = append(results, rateArticle(*rate, loc))
+		}
+	}
+
+	return results
+}
+
+// fetchInlineRates fetches the rates for the pair, its inverse and the base summary.
+// Only the pair itself is required, the rest is best-effort
+func (h *FxHandler) fetchInlineRates(ctx context.Context, base, target fxrates.Currency) (*inlineRates, error) {
+	var (
+		data      = &inlineRates{}
+		group, gc = errgroup.WithContext(ctx)
+	)
+
+	group.Go(func() error {
+		rates, err := h.fxClient.Rate(gc, base.String(), target.String(), "")
+		if err != nil {
+			return err
+		}
+
+		data.pair = filterPair(rates.Results, base, target)
+
+		return nil
+	})
+
+	if base != target {
+		group.Go(func() error {
+			rates, err := h.fxClient.Rate(gc, target.String(), base.String(), "")
+			if err != nil {
+				h.logger.Debug("unable to fetch inverse pair", "base", target, "target", base, "error", err)
+
+				return nil
+			}
+
+			data.inverse = filterPair(rates.Results, target, base)
+
+			return nil
+		})
+	}
+
+	group.Go(func() error {
+		rates, err := h.fxClient.Rates(gc, base.String())
+		if err != nil {
+			h.logger.Debug("unable to fetch base summary", "base", base, "error", err)
+
+			return nil
+		}
+
+		data.summary = filterBase(rates.Results, base)
+
+		return nil
+	})
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// inlineResults builds the ranked inline results: the preferred rate for the pair,
+// every other source and rate type for it, their BUY and SELL quotes, the inverse pair and the base summary
+func inlineResults(data *inlineRates, loc Locale) []models.InlineQueryResult {
+	var (
+		results = make([]models.InlineQueryResult, 0, len(data.pair)+2)
+		seen    = make(map[string]struct{})
+	)
+
+	add := func(article *models.InlineQueryResultArticle) {
+		if _, ok := seen[article.ID]; ok {
+			return
+		}
+
+		seen[article.ID] = struct{}{}
+		results = append(results, article)
+	}
+
+	for _, rate := range rankRates(data.pair, loc.preferences()) {
+		add(rateArticle(rate, loc))
+	}
+
+	if hasBuyAndSell(data.pair) {
+		add(quotesArticle(data.pair, loc))
+	}
+
+	// Without a direct rate, the inverted ones take its place
+	if len(data.pair) == 0 {
+		for _, article := range invertedArticles(data.inverse, loc) {
+			add(article)
+		}
+	}
+
+	if inverse := loc.preferences().Select(data.inverse); inverse != nil {
+		add(rateArticle(*inverse, loc))
+	}
+
+	if len(data.summary) > 0 {
+		add(summaryArticle(data.summary, loc))
+	}
+
+	// Telegram rejects the whole answer above the limit, so the lowest ranked results are dropped
+	return results[:min(len(results), maxInlineResults)]
+}
+
+// listingResults builds an article for every rate of a base,
+// sorted by target, source and rate type
+func listingResults(rates []fxrates.ExchangeRate, loc Locale) []models.InlineQueryResult {
+	sorted := slices.Clone(rates)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Target != sorted[j].Target {
+			return sorted[i].Target < sorted[j].Target
+		}
+
+		if sorted[i].Source != sorted[j].Source {
+			return sorted[i].Source < sorted[j].Source
+		}
+
+		return sorted[i].RateType < sorted[j].RateType
+	})
+
+	results := make([]models.InlineQueryResult, 0, len(sorted))
+	for _, rate := range sorted {
+		results = append(results, rateArticle(rate, loc))
+	}
+
+	return results
+}
+
+// paginate returns the page of results starting at the given inline query offset,
+// along with the offset of the next page, empty if it's the last one
+func paginate(results []models.InlineQueryResult, offset string) ([]models.InlineQueryResult, string) {
+	start, err := strconv.Atoi(offset)
+	if err != nil || start < 0 {
+		start = 0
+	}
+
+	if start >= len(results) {
+		return []models.InlineQueryResult{}, ""
+	}
+
+	end := min(start+maxInlineResults, len(results))
+	if end == len(results) {
+		return results[start:end], ""
+	}
+
+	return results[start:end], strconv.Itoa(end)
+}
+
+// rankRates orders the rates with the one preferred by the policy first,
+// followed by the rest sorted by source and rate type
+func rankRates(rates []fxrates.ExchangeRate, preferences preference.Policy) []fxrates.ExchangeRate {
+	preferred := preferences.Select(rates)
+	if preferred == nil {
+		return nil
+	}
+
+	ranked := make([]fxrates.ExchangeRate, 0, len(rates))
+	ranked = append(ranked, *preferred)
+
+	rest := make([]fxrates.ExchangeRate, 0, len(rates)-1)
+
+	for i := range rates {
+		if &rates[i] != preferred {
+			rest = append(rest, rates[i])
+		}
+	}
+
+	sort.SliceStable(rest, func(i, j int) bool {
+		if rest[i].Source != rest[j].Source {
+			return rest[i].Source < rest[j].Source
+		}
+
+		return rest[i].RateType < rest[j].RateType
+	})
+
+	return append(ranked, rest...)
+}
+
+// rateArticle builds the inline article for a single rate
+func rateArticle(rate fxrates.ExchangeRate, loc Locale) *models.InlineQueryResultArticle {
+	thumbnail := emojiThumbnailURL(getEmoji(rate.Base))
+
+	return &models.InlineQueryResultArticle{
+		ID:    rateResultID(rate),
+		Title: fmt.Sprintf("%s/%s", rate.Base, rate.Target),
+		Description: fmt.Sprintf(
+			"%s\n%s %s (%s, %s)",
+			pairNames(rate.Base, rate.Target, loc.Language),
+			currencySymbol(rate.Target),
+			formatAmount(rate.Rate, rate.Target, loc.Numbers),
+			rate.Source,
+			rate.RateType,
+		),
+		ThumbnailURL:    thumbnail,
+		ThumbnailWidth:  emojiThumbnailSize,
+		ThumbnailHeight: emojiThumbnailSize,
+		InputMessageContent: &models.InputTextMessageContent{
+			MessageText: FormatRate(rate, loc),
+			ParseMode:   loc.markup().ParseMode(),
+		},
+	}
+}
+
+// summaryArticle builds the inline article listing every rate for a base, like /tasas
+func summaryArticle(rates []fxrates.ExchangeRate, loc Locale) *models.InlineQueryResultArticle {
+	base := rates[0].Base
+
+	return &models.InlineQueryResultArticle{
+		ID:    strings.ToLower(base.String()) + "-" + summaryResultSuffix,
+		Title: translate(loc.Language, "inline.summary.title", i18n.Params{"base": base}),
+		Description: translate(loc.Language, "inline.summary.description", i18n.Params{
+			i18n.CountParam: len(rates),
+		}),
+		ThumbnailURL:    emojiThumbnailURL(getEmoji(base)),
+		ThumbnailWidth:  emojiThumbnailSize,
+		ThumbnailHeight: emojiThumbnailSize,
+		InputMessageContent: &models.InputTextMessageContent{
+			MessageText: FormatRates(rates, loc),
+			ParseMode:   loc.markup().ParseMode(),
+		},
+	}
+}
+
+// rateResultID returns the inline result ID for a rate,
+// unique across pairs, sources and rate types
+func rateResultID(rate fxrates.ExchangeRate) string {
+	return strings.ToLower(strings.Join([]string{
+		rate.Base.String(),
+		rate.Target.String(),
+		rate.Source.String(),
+		string(rate.RateType),
+	}, "-"))
+}
+
+// parseResultID returns the pair of an inline result ID, as built by rateResultID
+// and summaryArticle. Summaries have the wildcard target
+func parseResultID(id string) (fxrates.Currency, fxrates.Currency, bool) {
+	parts := strings.Split(id, "-")
+	if len(parts) < 2 || parts[0] == "" {
+		return "", "", false
+	}
+
+	base := fxrates.Currency(strings.ToUpper(parts[0]))
+
+	if len(parts) == 2 && parts[1] == summaryResultSuffix {
+		return base, inlineWildcard, true
+	}
+
+	if len(parts) < 3 || parts[1] == "" {
+		return "", "", false
+	}
+
+	return base, fxrates.Currency(strings.ToUpper(parts[1])), true
+}
+
+// emojiThumbnailURL returns the URL of the Twemoji image for the emoji
+func emojiThumbnailURL(emoji string) string {
+	codePoints := make([]string, 0, len(emoji))
+
+	for _, r := range emoji {
+		if r == variationSelector {
+			continue
+		}
+
+		codePoints = append(codePoints, fmt.Sprintf("%x", r))
+	}
+
+	return emojiThumbnailBaseURL + strings.Join(codePoints, "-") + ".png"
+}
+
+// filterPair returns the rates matching the given pair
+func filterPair(rates []fxrates.ExchangeRate, base, target fxrates.Currency) []fxrates.ExchangeRate {
+	filtered := make([]fxrates.ExchangeRate, 0, len(rates))
+
+	for _, rate := range rates {
+		if rate.Base == base && rate.Target == target {
+			filtered = append(filtered, rate)
+		}
+	}
+
+	return filtered
+}
+
+// filterBase returns the rates matching the given base currency
+func filterBase(rates []fxrates.ExchangeRate, base fxrates.Currency) []fxrates.ExchangeRate {
+	filtered := make([]fxrates.ExchangeRate, 0, len(rates))
+
+	for _, rate := range rates {
+		if rate.Base == base {
+			filtered = append(filtered, rate)
+		}
+	}
+
+	return filtered
+}
@@ -3,9 +3,12 @@ package bot
 import (
 	"context"
 	"encoding/json"
-	"log/slog"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -14,7 +17,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/sig-0/chigui-cifras/internal/analytics"
 	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/preference"
+	"github.com/sig-0/chigui-cifras/internal/store"
 
 	"github.com/sig-0/fxrates/storage/types"
 )
@@ -26,37 +32,64 @@ func TestInlineQuery_ValidRateEnglish(t *testing.T) {
 		asOf      = time.Date(2026, time.January, 2, 15, 4, 0, 0, time.UTC)
 		fetchedAt = time.Date(2026, time.January, 2, 15, 5, 0, 0, time.UTC)
 
-		response = fxrates.PageExchangeRate{
-			Results: []fxrates.ExchangeRate{
-				{
-					Base:      types.CurrencyUSD,
-					Target:    types.CurrencyVES,
-					Rate:      42.1234,
-					RateType:  types.RateTypeMID,
-					Source:    types.SourceBCV,
-					AsOf:      asOf,
-					FetchedAt: fetchedAt,
-				},
+		usdVES = []fxrates.ExchangeRate{
+			{
+				Base:      types.CurrencyUSD,
+				Target:    types.CurrencyVES,
+				Rate:      45,
+				RateType:  types.RateTypeBUY,
+				Source:    types.SourceBinance,
+				AsOf:      asOf,
+				FetchedAt: fetchedAt,
+			},
+			{
+				Base:      types.CurrencyUSD,
+				Target:    types.CurrencyVES,
+				Rate:      42.1234,
+				RateType:  types.RateTypeMID,
+				Source:    types.SourceBCV,
+				AsOf:      asOf,
+				FetchedAt: fetchedAt,
+			},
+		}
+		vesUSD = []fxrates.ExchangeRate{
+			{
+				Base:      types.CurrencyVES,
+				Target:    types.CurrencyUSD,
+				Rate:      0.0237,
+				RateType:  types.RateTypeMID,
+				Source:    types.SourceBCV,
+				AsOf:      asOf,
+				FetchedAt: fetchedAt,
+			},
+		}
+		usdAll = []fxrates.ExchangeRate{
+			usdVES[1],
+			{
+				Base:      types.CurrencyUSD,
+				Target:    types.CurrencyEUR,
+				Rate:      0.92,
+				RateType:  types.RateTypeMID,
+				Source:    types.SourceBCV,
+				AsOf:      asOf,
+				FetchedAt: fetchedAt,
 			},
-			Total: 1,
 		}
-	)
-
-	var fxPath string
 
-	fxServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		fxPath = r.URL.Path
+		responses = map[string]any{
+			"/v1/rates/USD/VES": page(usdVES...),
+			"/v1/rates/VES/USD": page(vesUSD...),
+			"/v1/rates/USD":     page(usdAll...),
+		}
+	)
 
-		w.Header().Set("Content-Type", "application/json")
-		require.NoError(t, json.NewEncoder(w).Encode(response))
-	}))
-	t.Cleanup(fxServer.Close)
+	fxServer, fxPaths := newInlineFXServer(t, responses)
 
 	tgServer, requests := newInlineServer(t)
 	t.Cleanup(tgServer.Close)
 
 	client := fxrates.NewClient(fxServer.URL, time.Second)
-	h := NewHandlers(client, slog.Default())
+	h := newTestHandler(t, client, store.NewMemory(), Settings{})
 	b := newTelegramBot(t, tgServer.URL)
 
 	update := &models.Update{
@@ -74,20 +107,518 @@ func TestInlineQuery_ValidRateEnglish(t *testing.T) {
 	request := awaitInlineRequest(t, requests)
 
 	assert.Equal(t, "inline-1", request.InlineQueryID)
-	assert.Equal(t, "/v1/rates/USD/VES", fxPath)
 
-	require.Len(t, request.Results, 1)
-	result := request.Results[0]
+	assert.ElementsMatch(t, []string{
+		"/v1/currencies",
+		"/v1/rates/USD/VES",
+		"/v1/rates/VES/USD",
+		"/v1/rates/USD",
+	}, fxPaths())
+
+	require.Len(t, request.Results, 4)
 
-	assert.Equal(t, "article", resultString(result, "type"))
-	assert.Equal(t, "USD/VES", resultString(result, "title"))
-	assert.Contains(t, resultString(result, "description"), "42.1234")
+	ids := make([]string, 0, len(request.Results))
+	for _, result := range request.Results {
+		ids = append(ids, resultString(result, "id"))
+	}
+
+	assert.Equal(t, []string{
+		"usd-ves-bcv-mid",
+		"usd-ves-binance-buy",
+		"ves-usd-bcv-mid",
+		"usd-summary",
+	}, ids)
+
+	// The preferred rate comes first
+	preferred := request.Results[0]
 
-	message := resultMessageText(t, result)
+	assert.Equal(t, "article", resultString(preferred, "type"))
+	assert.Equal(t, "USD/VES", resultString(preferred, "title"))
+	assert.Equal(t, "US dollar → Venezuelan bolívar\nBs. 42.12 (BCV, MID)", resultString(preferred, "description"))
+	assert.Equal(t, emojiThumbnailURL(getEmoji(types.CurrencyUSD)), resultString(preferred, "thumbnail_url"))
+
+	message := resultMessageText(t, preferred)
 	assert.Contains(t, message, "Rate:")
 	assert.Contains(t, message, "USD")
 	assert.Contains(t, message, "VES")
 	assert.Contains(t, message, "VET")
+
+	assert.Equal(t, "VES/USD", resultString(request.Results[2], "title"))
+
+	summary := request.Results[3]
+
+	assert.Equal(t, "All USD rates", resultString(summary, "title"))
+	assert.Equal(t, "2 rates, like /rates", resultString(summary, "description"))
+	assert.Contains(t, resultMessageText(t, summary), "EUR")
+}
+
+func TestInlineQuery_OptionalFetchFailures(t *testing.T) {
+	t.Parallel()
+
+	rate := fxrates.ExchangeRate{
+		Base:     types.CurrencyUSDT,
+		Target:   types.CurrencyVES,
+		Rate:     50,
+		RateType: types.RateTypeBUY,
+		Source:   types.SourceBinance,
+	}
+
+	// Every path other than the queried pair fails
+	fxServer, _ := newInlineFXServer(t, map[string]any{
+		"/v1/rates/USDT/VES": page(rate),
+	})
+
+	tgServer, requests := newInlineServer(t)
+	t.Cleanup(tgServer.Close)
+
+	client := fxrates.NewClient(fxServer.URL, time.Second)
+	h := newTestHandler(t, client, store.NewMemory(), Settings{})
+	b := newTelegramBot(t, tgServer.URL)
+
+	update := &models.Update{
+		InlineQuery: &models.InlineQuery{
+			ID:    "inline-5",
+			Query: "USDT",
+			From:  &models.User{},
+		},
+	}
+
+	h.InlineQuery(context.Background(), b, update)
+
+	request := awaitInlineRequest(t, requests)
+
+	// The inverse pair and the summary are best-effort
+	require.Len(t, request.Results, 1)
+	assert.Equal(t, "usdt-ves-binance-buy", resultString(request.Results[0], "id"))
+}
+
+func TestInlineQuery_Suggestions(t *testing.T) {
+	t.Parallel()
+
+	rate := func(base fxrates.Currency, value float64) fxrates.ExchangeRate {
+		return fxrates.ExchangeRate{
+			Base:     base,
+			Target:   types.CurrencyVES,
+			Rate:     value,
+			RateType: types.RateTypeMID,
+			Source:   types.SourceBCV,
+		}
+	}
+
+	// Only some of the suggested pairs have rates, the rest fail
+	responses := map[string]any{
+		"/v1/rates/USD/VES":  page(rate(types.CurrencyUSD, 42)),
+		"/v1/rates/EUR/VES":  page(rate(types.CurrencyEUR, 45)),
+		"/v1/rates/USDT/VES": page(rate(types.CurrencyUSDT, 50)),
+	}
+
+	testTable := []struct {
+		name   string
+		query  string
+		chosen []string
+		titles []string
+	}{
+		{
+			name:   "empty query shows popular pairs",
+			query:  "",
+			titles: []string{"USD/VES", "EUR/VES", "USDT/VES"},
+		},
+		{
+			name:   "empty query ranks chosen pairs",
+			query:  "",
+			chosen: []string{"USDT", "USDT", "EUR"},
+			titles: []string{"USDT/VES", "EUR/VES"},
+		},
+		{
+			name:   "partial base",
+			query:  "U",
+			titles: []string{"USD/VES", "USDT/VES"},
+		},
+		{
+			name:   "partial target",
+			query:  "USD V",
+			titles: []string{"USD/VES"},
+		},
+		{
+			name:   "partial name",
+			query:  "dól",
+			titles: []string{"USD/VES"},
+		},
+		{
+			name:   "no matches shows help",
+			query:  "ZZZ",
+			titles: []string{"Help"},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fxServer, _ := newInlineFXServer(t, responses)
+
+			tgServer, requests := newInlineServer(t)
+			t.Cleanup(tgServer.Close)
+
+			tracker := analytics.NewTracker(nil)
+			for _, base := range testCase.chosen {
+				tracker.RecordInlineChoice(analytics.InlineChoice{
+					ChosenAt: time.Date(2026, time.January, 2, 15, 4, 0, 0, time.UTC),
+					Base:     base,
+					Target:   "VES",
+					Language: "en",
+				})
+			}
+
+			client := fxrates.NewClient(fxServer.URL, time.Second)
+			h := newTestHandler(t, client, store.NewMemory(), Settings{Analytics: tracker})
+			b := newTelegramBot(t, tgServer.URL)
+
+			update := &models.Update{
+				InlineQuery: &models.InlineQuery{
+					ID:    "inline-6",
+					Query: testCase.query,
+					From: &models.User{
+						LanguageCode: "en",
+					},
+				},
+			}
+
+			h.InlineQuery(context.Background(), b, update)
+
+			request := awaitInlineRequest(t, requests)
+
+			titles := make([]string, 0, len(request.Results))
+			for _, result := range request.Results {
+				titles = append(titles, resultString(result, "title"))
+			}
+
+			assert.Equal(t, testCase.titles, titles)
+		})
+	}
+}
+
+func TestInlineQuery_Listing(t *testing.T) {
+	t.Parallel()
+
+	// One more than a page of rates, for made-up targets
+	rates := make([]fxrates.ExchangeRate, 0, maxInlineResults+1)
+	for i := range maxInlineResults + 1 {
+		rates = append(rates, fxrates.ExchangeRate{
+			Base:     types.CurrencyUSD,
+			Target:   fxrates.Currency(fmt.Sprintf("T%02d", i)),
+			Rate:     float64(i + 1),
+			RateType: types.RateTypeMID,
+			Source:   types.SourceBCV,
+		})
+	}
+
+	testTable := []struct {
+		name       string
+		offset     string
+		count      int
+		first      string
+		nextOffset string
+	}{
+		{
+			name:       "first page",
+			offset:     "",
+			count:      maxInlineResults,
+			first:      "USD/T00",
+			nextOffset: "50",
+		},
+		{
+			name:       "last page",
+			offset:     "50",
+			count:      1,
+			first:      "USD/T50",
+			nextOffset: "",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fxServer, _ := newInlineFXServer(t, map[string]any{
+				"/v1/rates/USD": page(rates...),
+			})
+
+			tgServer, requests := newInlineServer(t)
+			t.Cleanup(tgServer.Close)
+
+			client := fxrates.NewClient(fxServer.URL, time.Second)
+			h := newTestHandler(t, client, store.NewMemory(), Settings{})
+			b := newTelegramBot(t, tgServer.URL)
+
+			update := &models.Update{
+				InlineQuery: &models.InlineQuery{
+					ID:     "inline-7",
+					Query:  "usd *",
+					Offset: testCase.offset,
+					From:   &models.User{},
+				},
+			}
+
+			h.InlineQuery(context.Background(), b, update)
+
+			request := awaitInlineRequest(t, requests)
+
+			require.Len(t, request.Results, testCase.count)
+			assert.Equal(t, testCase.first, resultString(request.Results[0], "title"))
+			assert.Equal(t, testCase.nextOffset, request.NextOffset)
+		})
+	}
+}
+
+func TestInlineQuery_Paginate(t *testing.T) {
+	t.Parallel()
+
+	results := make([]models.InlineQueryResult, 120)
+	for i := range results {
+		results[i] = &models.InlineQueryResultArticle{ID: strconv.Itoa(i)}
+	}
+
+	testTable := []struct {
+		name       string
+		offset     string
+		count      int
+		nextOffset string
+	}{
+		{name: "no offset", offset: "", count: 50, nextOffset: "50"},
+		{name: "middle page", offset: "50", count: 50, nextOffset: "100"},
+		{name: "last page", offset: "100", count: 20, nextOffset: ""},
+		{name: "past the end", offset: "200", count: 0, nextOffset: ""},
+		{name: "invalid offset", offset: "abc", count: 50, nextOffset: "50"},
+		{name: "negative offset", offset: "-5", count: 50, nextOffset: "50"},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			pageResults, nextOffset := paginate(results, testCase.offset)
+
+			assert.Len(t, pageResults, testCase.count)
+			assert.Equal(t, testCase.nextOffset, nextOffset)
+		})
+	}
+}
+
+func TestInlineQuery_ResultsLimit(t *testing.T) {
+	t.Parallel()
+
+	data := &inlineRates{
+		pair:    make([]fxrates.ExchangeRate, 0, maxInlineResults+10),
+		inverse: []fxrates.ExchangeRate{{Base: types.CurrencyVES, Target: types.CurrencyUSD, Rate: 0.027}},
+	}
+
+	for i := range maxInlineResults + 10 {
+		data.pair = append(data.pair, fxrates.ExchangeRate{
+			Base:     types.CurrencyUSD,
+			Target:   types.CurrencyVES,
+			Rate:     36,
+			RateType: types.RateTypeMID,
+			Source:   fxrates.Source(fmt.Sprintf("SOURCE%d", i)),
+		})
+	}
+
+	results := inlineResults(data, NewLocale(LanguageEN))
+
+	// The lowest ranked results, like the inverse pair, are dropped
+	require.Len(t, results, maxInlineResults)
+
+	first, ok := results[0].(*models.InlineQueryResultArticle)
+	require.True(t, ok)
+	assert.Equal(t, rateResultID(rankRates(data.pair, preference.Default())[0]), first.ID)
+}
+
+func TestInlineQuery_CachePolicy(t *testing.T) {
+	t.Parallel()
+
+	settings := Settings{
+		InlineCache: InlineCacheSettings{
+			Help:   InlineCachePolicy{CacheTime: 10 * time.Minute},
+			Rates:  InlineCachePolicy{CacheTime: 5 * time.Second, Personal: true},
+			Errors: InlineCachePolicy{CacheTime: 1500 * time.Millisecond, Personal: true},
+		},
+	}
+
+	fxServer, _ := newInlineFXServer(t, map[string]any{
+		"/v1/rates/USD/VES": page(fxrates.ExchangeRate{
+			Base:     types.CurrencyUSD,
+			Target:   types.CurrencyVES,
+			Rate:     42,
+			RateType: types.RateTypeMID,
+			Source:   types.SourceBCV,
+		}),
+	})
+
+	testTable := []struct {
+		name      string
+		query     string
+		cacheTime string
+		personal  string
+	}{
+		{
+			name:      "help is shared",
+			query:     "ZZZ",
+			cacheTime: "600",
+			personal:  "",
+		},
+		{
+			name:      "rates are personal",
+			query:     "USD",
+			cacheTime: "5",
+			personal:  "true",
+		},
+		{
+			name:      "errors round down to seconds",
+			query:     "EUR",
+			cacheTime: "1",
+			personal:  "true",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tgServer, requests := newInlineServer(t)
+			t.Cleanup(tgServer.Close)
+
+			h := newTestHandler(t, fxrates.NewClient(fxServer.URL, time.Second), nil, settings)
+
+			b := newTelegramBot(t, tgServer.URL)
+
+			update := &models.Update{
+				InlineQuery: &models.InlineQuery{
+					ID:    "inline-8",
+					Query: testCase.query,
+					From:  &models.User{},
+				},
+			}
+
+			h.InlineQuery(context.Background(), b, update)
+
+			request := awaitInlineRequest(t, requests)
+
+			assert.Equal(t, testCase.cacheTime, request.CacheTime)
+			assert.Equal(t, testCase.personal, request.IsPersonal)
+		})
+	}
+}
+
+func TestInlineQuery_ChosenInlineResult(t *testing.T) {
+	t.Parallel()
+
+	tracker := analytics.NewTracker(nil)
+
+	h := newTestHandler(t, nil, nil, Settings{Analytics: tracker})
+
+	for _, resultID := range []string{"usd-ves-bcv-mid", "usd-summary", "help"} {
+		h.ChosenInlineResult(context.Background(), nil, &models.Update{
+			ChosenInlineResult: &models.ChosenInlineResult{
+				ResultID: resultID,
+				From:     models.User{LanguageCode: "pt-BR"},
+			},
+		})
+	}
+
+	popular := tracker.Popular(0)
+	require.Len(t, popular, 2)
+
+	assert.Equal(t, "USD", popular[0].Base)
+	assert.Equal(t, "*", popular[0].Target)
+	assert.Equal(t, "VES", popular[1].Target)
+
+	var sb strings.Builder
+
+	require.NoError(t, tracker.WriteMetrics(&sb))
+	assert.Contains(t, sb.String(), `language="pt"`)
+}
+
+func TestInlineQuery_ParseResultID(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name   string
+		id     string
+		base   fxrates.Currency
+		target fxrates.Currency
+		ok     bool
+	}{
+		{name: "rate", id: "usd-ves-bcv-mid", base: "USD", target: "VES", ok: true},
+		{name: "summary", id: "eur-summary", base: "EUR", target: "*", ok: true},
+		{name: "help", id: "help", ok: false},
+		{name: "truncated", id: "usd-ves", ok: false},
+		{name: "empty", id: "", ok: false},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			base, target, ok := parseResultID(testCase.id)
+
+			assert.Equal(t, testCase.ok, ok)
+			assert.Equal(t, testCase.base, base)
+			assert.Equal(t, testCase.target, target)
+		})
+	}
+}
+
+func TestInlineQuery_RankRates(t *testing.T) {
+	t.Parallel()
+
+	rates := []fxrates.ExchangeRate{
+		{Base: types.CurrencyUSD, Source: types.SourceBinance, RateType: types.RateTypeSELL},
+		{Base: types.CurrencyUSD, Source: types.SourceBinance, RateType: types.RateTypeBUY},
+		{Base: types.CurrencyUSD, Source: types.SourceBCV, RateType: types.RateTypeMID},
+	}
+
+	ranked := rankRates(rates, preference.Default())
+
+	require.Len(t, ranked, 3)
+	assert.Equal(t, rates[2], ranked[0])
+	assert.Equal(t, rates[1], ranked[1])
+	assert.Equal(t, rates[0], ranked[2])
+
+	assert.Nil(t, rankRates(nil, preference.Default()))
+}
+
+func TestInlineQuery_EmojiThumbnailURL(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name  string
+		emoji string
+		file  string
+	}{
+		{
+			name:  "flag",
+			emoji: "🇺🇸",
+			file:  "1f1fa-1f1f8.png",
+		},
+		{
+			name:  "variation selector",
+			emoji: "\u2600\ufe0f",
+			file:  "2600.png",
+		},
+		{
+			name:  "single code point",
+			emoji: "💱",
+			file:  "1f4b1.png",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, emojiThumbnailBaseURL+testCase.file, emojiThumbnailURL(testCase.emoji))
+		})
+	}
 }
 
 func TestInlineQuery_HelpEnglish(t *testing.T) {
@@ -96,7 +627,7 @@ func TestInlineQuery_HelpEnglish(t *testing.T) {
 	tgServer, requests := newInlineServer(t)
 	t.Cleanup(tgServer.Close)
 
-	h := NewHandlers(nil, slog.Default())
+	h := newTestHandler(t, nil, store.NewMemory(), Settings{})
 	b := newTelegramBot(t, tgServer.URL)
 
 	update := &models.Update{
@@ -124,19 +655,15 @@ func TestInlineQuery_HelpEnglish(t *testing.T) {
 func TestInlineQuery_NoResultsSpanish(t *testing.T) {
 	t.Parallel()
 
-	response := fxrates.PageExchangeRate{}
-
-	fxServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		require.NoError(t, json.NewEncoder(w).Encode(response))
-	}))
-	t.Cleanup(fxServer.Close)
+	fxServer, _ := newInlineFXServer(t, map[string]any{
+		"/v1/rates/USD/VES": page(),
+	})
 
 	tgServer, requests := newInlineServer(t)
 	t.Cleanup(tgServer.Close)
 
 	client := fxrates.NewClient(fxServer.URL, time.Second)
-	h := NewHandlers(client, slog.Default())
+	h := newTestHandler(t, client, store.NewMemory(), Settings{})
 	b := newTelegramBot(t, tgServer.URL)
 
 	update := &models.Update{
@@ -172,7 +699,7 @@ func TestInlineQuery_ErrorSpanish(t *testing.T) {
 	t.Cleanup(tgServer.Close)
 
 	client := fxrates.NewClient(fxServer.URL, time.Second)
-	h := NewHandlers(client, slog.Default())
+	h := newTestHandler(t, client, store.NewMemory(), Settings{})
 	b := newTelegramBot(t, tgServer.URL)
 
 	update := &models.Update{
@@ -260,17 +787,79 @@ func TestInlineQuery_ParseInlineQuery(t *testing.T) {
 func TestInlineQuery_LanguageForInline(t *testing.T) {
 	t.Parallel()
 
-	h := NewHandlers(nil, slog.Default())
+	h := newTestHandler(t, nil, store.NewMemory(), Settings{})
 
 	assert.Equal(t, LanguageES, h.languageForInline(nil))
 	assert.Equal(t, LanguageES, h.languageForInline(&models.InlineQuery{}))
 	assert.Equal(t, LanguageEN, h.languageForInline(&models.InlineQuery{From: &models.User{LanguageCode: "en"}}))
 	assert.Equal(t, LanguageEN, h.languageForInline(&models.InlineQuery{From: &models.User{LanguageCode: "en-US"}}))
 	assert.Equal(t, LanguageES, h.languageForInline(&models.InlineQuery{From: &models.User{LanguageCode: "es-VE"}}))
+	assert.Equal(t, LanguagePT, h.languageForInline(&models.InlineQuery{From: &models.User{LanguageCode: "pt-BR"}}))
+}
+
+// inlineCurrencies is the supported currency list served by newInlineFXServer
+var inlineCurrencies = fxrates.CurrenciesResponse{
+	Results: []fxrates.Currency{
+		types.CurrencyUSD,
+		types.CurrencyEUR,
+		types.CurrencyVES,
+		types.CurrencyUSDT,
+	},
+}
+
+// newInlineFXServer starts a fake fxrates API serving the given JSON responses by path,
+// along with the supported currency list. Unknown paths fail with a server error.
+// It returns the server and a function listing the requested paths
+func newInlineFXServer(t *testing.T, responses map[string]any) (*httptest.Server, func() []string) {
+	t.Helper()
+
+	var (
+		mux   sync.Mutex
+		paths []string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.Lock()
+		paths = append(paths, r.URL.Path)
+		mux.Unlock()
+
+		response, ok := responses[r.URL.Path]
+		if !ok && r.URL.Path == "/v1/currencies" {
+			response, ok = inlineCurrencies, true
+		}
+
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(response))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, func() []string {
+		mux.Lock()
+		defer mux.Unlock()
+
+		return append([]string(nil), paths...)
+	}
+}
+
+// page wraps the rates in an API response page
+func page(rates ...fxrates.ExchangeRate) fxrates.PageExchangeRate {
+	return fxrates.PageExchangeRate{
+		Results: rates,
+		Total:   len(rates),
+	}
 }
 
 type inlineRequest struct {
 	InlineQueryID string
+	NextOffset    string
+	CacheTime     string
+	IsPersonal    string
 	Results       []map[string]any
 }
 
@@ -299,6 +888,9 @@ func newInlineServer(t *testing.T) (*httptest.Server, <-chan inlineRequest) {
 
 		requests <- inlineRequest{
 			InlineQueryID: r.FormValue("inline_query_id"),
+			NextOffset:    r.FormValue("next_offset"),
+			CacheTime:     r.FormValue("cache_time"),
+			IsPersonal:    r.FormValue("is_personal"),
 			Results:       results,
 		}
 
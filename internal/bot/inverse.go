@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-telegram/bot/models"
+
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/i18n"
+)
+
+// invertedResultSuffix ends the ID of the inverted rate inline results
+const invertedResultSuffix = "inverted"
+
+var errNoInvertedRate = errors.New("no rates to invert the pair from")
+
+// invertedRate is a rate computed as the inverse of the one published for the opposite pair
+type invertedRate struct {
+	// Rate is the inverted rate, with the source and rate type of the published one
+	Rate fxrates.ExchangeRate
+
+	// Published is the rate of the opposite pair it was inverted from
+	Published fxrates.ExchangeRate
+}
+
+// invertRate inverts the published rate, or reports false if it's zero
+func invertRate(published fxrates.ExchangeRate) (invertedRate, bool) {
+	if published.Rate == 0 {
+		return invertedRate{}, false
+	}
+
+	rate := published
+	rate.Base, rate.Target = published.Target, published.Base
+	rate.Rate = 1 / published.Rate
+
+	return invertedRate{Rate: rate, Published: published}, true
+}
+
+// preferredInvertedRate fetches the opposite pair, as it would be fetched directly,
+// and inverts its preferred rate
+func (h *FxHandler) preferredInvertedRate(ctx context.Context, base, target fxrates.Currency) (*invertedRate, error) {
+	if h.fxClient == nil {
+		return nil, errFXClientNotConfigured
+	}
+
+	if base == target {
+		return nil, errNoInvertedRate
+	}
+
+	preferences := h.settings().preferences
+
+	rates, err := h.fxClient.Rate(ctx, target.String(), base.String(), preferences.Source(target).String())
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch %s/%s: %w", target, base, err)
+	}
+
+	published := preferences.Select(filterPair(rates.Results, target, base))
+	if published == nil {
+		return nil, errNoInvertedRate
+	}
+
+	inverted, ok := invertRate(*published)
+	if !ok {
+		return nil, errNoInvertedRate
+	}
+
+	return &inverted, nil
+}
+
+// FormatInvertedRate formats an inverted rate, marked as such along with the published rate
+func FormatInvertedRate(inverted invertedRate, loc Locale) string {
+	return formatRate(inverted.Rate, &inverted.Published, loc)
+}
+
+// invertedArticles builds the inline articles for the inverse of the published rates,
+// ranked like the published ones
+func invertedArticles(published []fxrates.ExchangeRate, loc Locale) []*models.InlineQueryResultArticle {
+	articles := make([]*models.InlineQueryResultArticle, 0, len(published))
+
+	for _, rate := range rankRates(published, loc.preferences()) {
+		if inverted, ok := invertRate(rate); ok {
+			articles = append(articles, invertedArticle(inverted, loc))
+		}
+	}
+
+	return articles
+}
+
+// invertedArticle builds the inline article for an inverted rate
+func invertedArticle(inverted invertedRate, loc Locale) *models.InlineQueryResultArticle {
+	var (
+		rate    = inverted.Rate
+		article = rateArticle(rate, loc)
+	)
+
+	article.ID = rateResultID(rate) + "-" + invertedResultSuffix
+	article.Description = fmt.Sprintf(
+		"%s · %s\n%s %s (%s, %s)",
+		translate(loc.Language, "inverse.title", i18n.Params{
+			"pair": fmt.Sprintf("%s/%s", inverted.Published.Base, inverted.Published.Target),
+		}),
+		pairNames(rate.Base, rate.Target, loc.Language),
+		currencySymbol(rate.Target),
+		formatAmount(rate.Rate, rate.Target, loc.Numbers),
+		rate.Source,
+		rate.RateType,
+	)
+	article.InputMessageContent = &models.InputTextMessageContent{
+		MessageText: FormatInvertedRate(inverted, loc),
+		ParseMode:   loc.markup().ParseMode(),
+	}
+
+	return article
+}
@@ -0,0 +1,184 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sig-0/chigui-cifras/internal/clock"
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/store"
+
+	"github.com/sig-0/fxrates/storage/types"
+)
+
+func TestInvertRate(t *testing.T) {
+	t.Parallel()
+
+	inverted, ok := invertRate(usdVES)
+	require.True(t, ok)
+
+	assert.Equal(t, fxrates.ExchangeRate{
+		Base:     types.CurrencyVES,
+		Target:   types.CurrencyUSD,
+		Rate:     0.025,
+		RateType: usdVES.RateType,
+		Source:   usdVES.Source,
+		AsOf:     usdVES.AsOf,
+	}, inverted.Rate)
+	assert.Equal(t, usdVES, inverted.Published)
+
+	zero := usdVES
+	zero.Rate = 0
+
+	_, ok = invertRate(zero)
+	assert.False(t, ok)
+}
+
+func TestFormatInvertedRate(t *testing.T) {
+	t.Parallel()
+
+	inverted, ok := invertRate(usdVES)
+	require.True(t, ok)
+
+	loc := NewLocale(LanguageEN)
+	loc.Clock = clock.Fixed(crossAsOf)
+
+	message := FormatInvertedRate(inverted, loc)
+
+	assert.Contains(t, message, "VES → USD")
+	assert.Contains(t, message, "Rate: 0.02500")
+	assert.Contains(t, message, "🔁 Inverted from the published rate: 1 USD = 40.00 VES")
+
+	// Published rates aren't marked
+	assert.NotContains(t, FormatRate(usdVES, loc), "Inverted")
+}
+
+func TestHandler_RateInverseFallback(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name      string
+		command   string
+		handle    func(*FxHandler, context.Context, *bot.Bot, *models.Update)
+		responses map[string]any
+		expected  string
+	}{
+		{
+			name:    "pair not published",
+			command: "/tasa VES USD",
+			handle:  (*FxHandler).Rate,
+			responses: map[string]any{
+				"/v1/rates/VES/USD": page(),
+				"/v1/rates/USD/VES": page(usdVES),
+			},
+			expected: "🔁 Invertida de la tasa publicada: 1 USD = 40,00 VES",
+		},
+		{
+			name:    "pair unavailable",
+			command: "/tasa VES USD",
+			handle:  (*FxHandler).Rate,
+			responses: map[string]any{
+				"/v1/rates/USD/VES": page(usdVES),
+			},
+			expected: "Tasa: 0,02500",
+		},
+		{
+			name:    "shortcut",
+			command: "/dolar",
+			handle:  defaultShortcut(t, "/dolar"),
+			responses: map[string]any{
+				"/v1/rates/USD/VES": page(),
+				"/v1/rates/VES/USD": page(fxrates.ExchangeRate{
+					Base:     types.CurrencyVES,
+					Target:   types.CurrencyUSD,
+					Rate:     0.025,
+					RateType: types.RateTypeMID,
+					Source:   types.SourceBCV,
+					AsOf:     crossAsOf,
+				}),
+			},
+			expected: "🔁 Invertida de la tasa publicada: 1 VES = 0,02500 USD",
+		},
+		{
+			name:    "shortcut without rates",
+			command: "/dolar",
+			handle:  defaultShortcut(t, "/dolar"),
+			responses: map[string]any{
+				"/v1/rates/USD/VES": page(),
+				"/v1/rates/VES/USD": page(),
+			},
+			expected: "No se encontraron tasas para USD/VES",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fxServer, _ := newInlineFXServer(t, testCase.responses)
+			h := newTestHandler(t, fxrates.NewClient(fxServer.URL, time.Second), store.NewMemory(), Settings{})
+
+			srv, messages := newMessageServer(t)
+			b := newTelegramBot(t, srv.URL)
+
+			testCase.handle(h, context.Background(), b, commandUpdate(1, testCase.command))
+
+			assert.Contains(t, receiveMessage(t, messages).Text, testCase.expected)
+		})
+	}
+}
+
+func TestInlineQuery_InverseFallback(t *testing.T) {
+	t.Parallel()
+
+	fxServer, _ := newInlineFXServer(t, map[string]any{
+		"/v1/rates/VES/USD": page(),
+		"/v1/rates/USD/VES": page(usdVES),
+		"/v1/rates/VES":     page(),
+	})
+
+	tgServer, requests := newInlineServer(t)
+	t.Cleanup(tgServer.Close)
+
+	h := newTestHandler(t, fxrates.NewClient(fxServer.URL, time.Second), store.NewMemory(), Settings{})
+	b := newTelegramBot(t, tgServer.URL)
+
+	h.InlineQuery(context.Background(), b, &models.Update{
+		InlineQuery: &models.InlineQuery{
+			ID:    "inline-1",
+			Query: "VES USD",
+			From:  &models.User{LanguageCode: "en-US"},
+		},
+	})
+
+	request := awaitInlineRequest(t, requests)
+
+	ids := make([]string, 0, len(request.Results))
+	for _, result := range request.Results {
+		ids = append(ids, resultString(result, "id"))
+	}
+
+	// The inverted rate comes first, then the published one
+	assert.Equal(t, []string{"ves-usd-bcv-mid-inverted", "usd-ves-bcv-mid"}, ids)
+
+	inverted := request.Results[0]
+
+	assert.Equal(t, "VES/USD", resultString(inverted, "title"))
+	assert.Equal(
+		t,
+		"Inverted from USD/VES · Venezuelan bolívar → US dollar\n$ 0.02500 (BCV, MID)",
+		resultString(inverted, "description"),
+	)
+	assert.Contains(t, resultMessageText(t, inverted), "🔁 Inverted from the published rate: 1 USD = 40.00 VES")
+
+	base, target, ok := parseResultID(resultString(inverted, "id"))
+	require.True(t, ok)
+	assert.Equal(t, types.CurrencyVES, base)
+	assert.Equal(t, types.CurrencyUSD, target)
+}
@@ -0,0 +1,158 @@
+package bot
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/store"
+
+	"github.com/sig-0/fxrates/provider/currencies"
+)
+
+// liveRatesBase marks the live rates summary among the chat's pinned messages,
+// since it shows several bases at once
+const liveRatesBase = "*"
+
+// defaultLiveRatesInterval is how often the live rates summaries are checked for changes
+const defaultLiveRatesInterval = time.Minute
+
+var errNoLiveRates = errors.New("no rates available")
+
+// liveRatesCurrencies are the bases shown in the live rates summary, against VES
+var liveRatesCurrencies = []fxrates.Currency{currencies.USD, currencies.EUR, currencies.USDT}
+
+// PinRates handles the /fijar command, posting and pinning
+// a rates summary that is kept up to date
+func (h *FxHandler) PinRates(ctx context.Context, b *bot.Bot, update *models.Update) {
+	var (
+		loc    = h.commandLocale(update)
+		chatID = updateMessage(update).Chat.ID
+	)
+
+	rates, err := h.liveRates(ctx)
+	if err != nil {
+		h.reply(ctx, b, update, ErrorMessage(err, loc))
+
+		return
+	}
+
+	now := h.clock.Now()
+
+	// A new summary replaces the one kept up to date, if any
+	summary := store.PinnedMessage{
+		Base:     liveRatesBase,
+		Target:   currencies.VES.String(),
+		Language: string(loc.Language),
+		Digest:   ratesDigest(rates),
+	}
+
+	if err := h.postPinned(ctx, b, chatID, summary, LiveRatesMessage(rates, now, loc), now, true); err != nil {
+		h.logger.Error("unable to pin live rates", "chat_id", chatID, "error", err)
+
+		h.reply(ctx, b, update, ErrorMessage(err, loc))
+	}
+}
+
+// UpdateLiveRates edits the live rates summary of every chat
+// showing rates other than the current ones
+func (h *FxHandler) UpdateLiveRates(ctx context.Context, b *bot.Bot) error {
+	if h.store == nil {
+		return errStoreNotConfigured
+	}
+
+	rates, err := h.liveRates(ctx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		digest = ratesDigest(rates)
+		now    = h.clock.Now()
+	)
+
+	for _, chat := range h.store.Chats() {
+		summary, ok := chat.PinnedFor(liveRatesBase, currencies.VES.String())
+		if !ok || summary.Digest == digest {
+			continue
+		}
+
+		summary.Digest = digest
+
+		loc := h.localeFor(chat.ID, cmp.Or(Language(summary.Language), LanguageES))
+
+		edited, err := h.editPinned(ctx, b, chat.ID, summary, LiveRatesMessage(rates, now, loc), now)
+
+		switch {
+		case err == nil && edited:
+			continue
+		case err == nil, errors.Is(err, bot.ErrorForbidden):
+			// The summary was deleted, or the bot removed from the chat
+			h.logger.Info("live rates message gone, no longer updating it", "chat_id", chat.ID)
+
+			err = h.store.RemovePinned(chat.ID, summary.Base, summary.Target)
+		}
+
+		if err != nil {
+			h.logger.Error("unable to update live rates", "chat_id", chat.ID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// liveRates fetches the preferred rate of every live rates base
+func (h *FxHandler) liveRates(ctx context.Context) ([]fxrates.ExchangeRate, error) {
+	if h.fxClient == nil {
+		return nil, errFXClientNotConfigured
+	}
+
+	var (
+		target      = currencies.VES
+		rates       = make([]fxrates.ExchangeRate, 0, len(liveRatesCurrencies))
+		preferences = h.settings().preferences
+	)
+
+	for _, base := range liveRatesCurrencies {
+		results, err := h.fxClient.Rate(ctx, base.String(), target.String(), preferences.Source(base).String())
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch %s rate: %w", base, err)
+		}
+
+		if rate := preferences.Select(results.Results); rate != nil {
+			rates = append(rates, *rate)
+		}
+	}
+
+	if len(rates) == 0 {
+		return nil, errNoLiveRates
+	}
+
+	return rates, nil
+}
+
+// ratesDigest identifies the rates, to tell when any of them changes
+func ratesDigest(rates []fxrates.ExchangeRate) string {
+	parts := make([]string, 0, len(rates))
+
+	for _, rate := range rates {
+		parts = append(parts, strings.Join([]string{
+			rate.Base.String(),
+			rate.Target.String(),
+			rate.Source.String(),
+			rate.RateType.String(),
+			strconv.FormatFloat(rate.Rate, 'g', -1, 64),
+			strconv.FormatInt(rate.AsOf.Unix(), 10),
+		}, ":"))
+	}
+
+	return strings.Join(parts, ";")
+}
@@ -0,0 +1,175 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/store"
+
+	"github.com/sig-0/fxrates/storage/types"
+)
+
+var liveRatesTestRates = []fxrates.ExchangeRate{
+	{
+		Base:     types.CurrencyUSD,
+		Target:   types.CurrencyVES,
+		Rate:     36.5,
+		RateType: types.RateTypeMID,
+		Source:   types.SourceBCV,
+	},
+	{
+		Base:     types.CurrencyEUR,
+		Target:   types.CurrencyVES,
+		Rate:     39.8,
+		RateType: types.RateTypeMID,
+		Source:   types.SourceBCV,
+	},
+	{
+		Base:     types.CurrencyUSDT,
+		Target:   types.CurrencyVES,
+		Rate:     41.2,
+		RateType: types.RateTypeBUY,
+		Source:   types.SourceBinance,
+	},
+}
+
+// newLiveRatesClient creates a client of a fake fxrates API serving the live rates
+func newLiveRatesClient(t *testing.T) *fxrates.Client {
+	t.Helper()
+
+	fxServer, _ := newInlineFXServer(t, map[string]any{
+		"/v1/rates/USD/VES":  page(liveRatesTestRates[0]),
+		"/v1/rates/EUR/VES":  page(liveRatesTestRates[1]),
+		"/v1/rates/USDT/VES": page(liveRatesTestRates[2]),
+	})
+
+	return fxrates.NewClient(fxServer.URL, time.Second)
+}
+
+func TestLiveRates_PinRates(t *testing.T) {
+	t.Parallel()
+
+	chatStore := store.NewMemory()
+	h := newTestHandler(t, newLiveRatesClient(t), chatStore, Settings{})
+
+	srv, requests := newMessageServer(t)
+	b := newTelegramBot(t, srv.URL)
+
+	h.PinRates(context.Background(), b, &models.Update{
+		Message: &models.Message{
+			Text: "/pin",
+			Chat: models.Chat{ID: -100, Type: models.ChatTypeSupergroup},
+			From: &models.User{ID: 7},
+		},
+	})
+
+	posted := <-requests
+	assert.Equal(t, "sendMessage", posted.Method)
+	assert.Contains(t, posted.Text, "Today's rates")
+	assert.Contains(t, posted.Text, "USD/VES")
+	assert.Contains(t, posted.Text, "EUR/VES")
+	assert.Contains(t, posted.Text, "USDT/VES")
+
+	assert.Equal(t, "pinChatMessage", (<-requests).Method)
+
+	chat, ok := chatStore.Chat(-100)
+	require.True(t, ok)
+
+	summary, ok := chat.PinnedFor(liveRatesBase, "VES")
+	require.True(t, ok)
+
+	assert.Equal(t, 10, summary.MessageID)
+	assert.Equal(t, string(LanguageEN), summary.Language)
+	assert.Equal(t, ratesDigest(liveRatesTestRates), summary.Digest)
+}
+
+func TestLiveRates_Update(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name      string
+		digest    string
+		editError string
+		methods   []string
+		kept      bool
+	}{
+		{
+			name:    "unchanged rates",
+			digest:  ratesDigest(liveRatesTestRates),
+			methods: nil,
+			kept:    true,
+		},
+		{
+			name:    "changed rates",
+			digest:  "outdated",
+			methods: []string{"editMessageText"},
+			kept:    true,
+		},
+		{
+			name:      "deleted message",
+			digest:    "outdated",
+			editError: "Bad Request: message to edit not found",
+			methods:   []string{"editMessageText"},
+			kept:      false,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			chatStore := store.NewMemory()
+			require.NoError(t, chatStore.SetPinned(-100, store.PinnedMessage{
+				Base:      liveRatesBase,
+				Target:    "VES",
+				Language:  string(LanguagePT),
+				Digest:    testCase.digest,
+				MessageID: 5,
+			}))
+
+			// Chats pinning a single pair aren't edited
+			require.NoError(t, chatStore.SetPinned(-200, store.PinnedMessage{
+				Base:      "USD",
+				Target:    "VES",
+				MessageID: 6,
+			}))
+
+			h := newTestHandler(t, newLiveRatesClient(t), chatStore, Settings{})
+
+			srv, requests := newMessageServer(t, apiFailure{Method: "editMessageText", Description: testCase.editError})
+			b := newTelegramBot(t, srv.URL)
+
+			require.NoError(t, h.UpdateLiveRates(context.Background(), b))
+
+			var methods []string
+
+			for range testCase.methods {
+				request := <-requests
+
+				assert.Equal(t, "5", request.MessageID)
+				assert.Contains(t, request.Text, "Taxas do dia")
+
+				methods = append(methods, request.Method)
+			}
+
+			assert.Equal(t, testCase.methods, methods)
+			assert.Empty(t, requests)
+
+			chat, ok := chatStore.Chat(-100)
+			require.True(t, ok)
+
+			summary, ok := chat.PinnedFor(liveRatesBase, "VES")
+			require.Equal(t, testCase.kept, ok)
+
+			if testCase.kept {
+				assert.Equal(t, ratesDigest(liveRatesTestRates), summary.Digest)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// Markup renders text for a Telegram parse mode.
+// Every method takes plain text and escapes it as needed
+type Markup interface {
+	// ParseMode returns the Telegram parse mode for the rendered text
+	ParseMode() models.ParseMode
+
+	// Escape escapes plain text so it's displayed verbatim
+	Escape(text string) string
+
+	// Bold renders the text in bold
+	Bold(text string) string
+
+	// Italic renders the text in italics
+	Italic(text string) string
+
+	// Code renders the text in a monospace font
+	Code(text string) string
+}
+
+// MarkupFor returns the markup for the given parse mode.
+// An empty parse mode renders plain text
+func MarkupFor(mode models.ParseMode) (Markup, error) {
+	switch mode {
+	case "":
+		return plainMarkup{}, nil
+	case models.ParseModeMarkdown:
+		return markdownMarkup{}, nil
+	case models.ParseModeHTML:
+		return htmlMarkup{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported parse mode: %q", mode)
+	}
+}
+
+// plainMarkup renders plain text, without any formatting
+type plainMarkup struct{}
+
+func (plainMarkup) ParseMode() models.ParseMode { return "" }
+
+func (plainMarkup) Escape(text string) string { return text }
+
+func (plainMarkup) Bold(text string) string { return text }
+
+func (plainMarkup) Italic(text string) string { return text }
+
+func (plainMarkup) Code(text string) string { return text }
+
+// markdownReplacer escapes the characters reserved by MarkdownV2
+var markdownReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	"_", `\_`,
+	"*", `\*`,
+	"[", `\[`,
+	"]", `\]`,
+	"(", `\(`,
+	")", `\)`,
+	"~", `\~`,
+	"`", "\\`",
+	">", `\>`,
+	"#", `\#`,
+	"+", `\+`,
+	"-", `\-`,
+	"=", `\=`,
+	"|", `\|`,
+	"{", `\{`,
+	"}", `\}`,
+	".", `\.`,
+	"!", `\!`,
+)
+
+// markdownCodeReplacer escapes the characters reserved inside MarkdownV2 code spans
+var markdownCodeReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+)
+
+// markdownMarkup renders Telegram MarkdownV2
+type markdownMarkup struct{}
+
+func (markdownMarkup) ParseMode() models.ParseMode { return models.ParseModeMarkdown }
+
+func (markdownMarkup) Escape(text string) string { return markdownReplacer.Replace(text) }
+
+func (m markdownMarkup) Bold(text string) string { return "*" + m.Escape(text) + "*" }
+
+func (m markdownMarkup) Italic(text string) string { return "_" + m.Escape(text) + "_" }
+
+func (markdownMarkup) Code(text string) string { return "`" + markdownCodeReplacer.Replace(text) + "`" }
+
+// htmlMarkup renders Telegram HTML
+type htmlMarkup struct{}
+
+func (htmlMarkup) ParseMode() models.ParseMode { return models.ParseModeHTML }
+
+func (htmlMarkup) Escape(text string) string { return html.EscapeString(text) }
+
+func (m htmlMarkup) Bold(text string) string { return "<b>" + m.Escape(text) + "</b>" }
+
+func (m htmlMarkup) Italic(text string) string { return "<i>" + m.Escape(text) + "</i>" }
+
+func (m htmlMarkup) Code(text string) string { return "<code>" + m.Escape(text) + "</code>" }
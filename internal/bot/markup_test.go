@@ -0,0 +1,85 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkup_MarkupFor(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name     string
+		mode     models.ParseMode
+		expected Markup
+	}{
+		{name: "plain", mode: "", expected: plainMarkup{}},
+		{name: "markdownv2", mode: models.ParseModeMarkdown, expected: markdownMarkup{}},
+		{name: "html", mode: models.ParseModeHTML, expected: htmlMarkup{}},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			markup, err := MarkupFor(testCase.mode)
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, markup)
+			assert.Equal(t, testCase.mode, markup.ParseMode())
+		})
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := MarkupFor(models.ParseModeMarkdownV1)
+
+		assert.ErrorContains(t, err, "unsupported parse mode")
+	})
+}
+
+func TestMarkup_Plain(t *testing.T) {
+	t.Parallel()
+
+	m := plainMarkup{}
+	text := "1.234,56 <b> *x* (y)"
+
+	assert.Equal(t, text, m.Escape(text))
+	assert.Equal(t, text, m.Bold(text))
+	assert.Equal(t, text, m.Italic(text))
+	assert.Equal(t, text, m.Code(text))
+}
+
+func TestMarkup_Markdown(t *testing.T) {
+	t.Parallel()
+
+	m := markdownMarkup{}
+
+	assert.Equal(
+		t,
+		`\_\*\[\]\(\)\~\`+"`"+`\>\#\+\-\=\|\{\}\.\!\\`,
+		m.Escape("_*[]()~`>#+-=|{}.!\\"),
+	)
+	assert.Equal(t, "plain text, no reserved chars", m.Escape("plain text, no reserved chars"))
+	assert.Equal(t, `*1\.234,56*`, m.Bold("1.234,56"))
+	assert.Equal(t, `_2026\-01\-02_`, m.Italic("2026-01-02"))
+
+	// Only backticks and backslashes are escaped inside code spans
+	assert.Equal(t, "`1.234,56 (x)`", m.Code("1.234,56 (x)"))
+	assert.Equal(t, "`a\\`b\\\\`", m.Code("a`b\\"))
+}
+
+func TestMarkup_HTML(t *testing.T) {
+	t.Parallel()
+
+	m := htmlMarkup{}
+
+	assert.Equal(t, "&lt;b&gt; &amp; *x*", m.Escape("<b> & *x*"))
+	assert.Equal(t, "<b>a &lt; b</b>", m.Bold("a < b"))
+	assert.Equal(t, "<i>a &gt; b</i>", m.Italic("a > b"))
+	assert.Equal(t, "<code>&lt;base&gt;</code>", m.Code("<base>"))
+}
@@ -0,0 +1,121 @@
+package bot
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+)
+
+const (
+	// defaultPrecision is the number of decimals shown for the currencies missing from the registry
+	defaultPrecision = 4
+
+	// cryptoPrecision is the number of decimals shown for crypto amounts
+	cryptoPrecision = 6
+
+	// significantDigits is the number of significant digits shown for amounts below one,
+	// which are often small fractions, like VES/USD, and would round to zero with the currency precision
+	significantDigits = 4
+)
+
+// NumberStyle describes the separators used when formatting numbers
+type NumberStyle struct {
+	Decimal   string
+	Thousands string
+}
+
+var (
+	// NumberStyleComma formats numbers as 1.234.567,89 (Venezuela, Spain, Brazil)
+	NumberStyleComma = NumberStyle{Decimal: ",", Thousands: "."}
+
+	// NumberStylePoint formats numbers as 1,234,567.89 (US, UK)
+	NumberStylePoint = NumberStyle{Decimal: ".", Thousands: ","}
+)
+
+// Number format names, as persisted per chat
+const (
+	numberFormatComma = "comma"
+	numberFormatPoint = "point"
+)
+
+// numberFormatAliases maps user input to a number format name
+var numberFormatAliases = map[string]string{
+	"coma":     numberFormatComma,
+	"comma":    numberFormatComma,
+	"virgula":  numberFormatComma,
+	"vírgula":  numberFormatComma,
+	"1.234,56": numberFormatComma,
+	"punto":    numberFormatPoint,
+	"point":    numberFormatPoint,
+	"ponto":    numberFormatPoint,
+	"1,234.56": numberFormatPoint,
+}
+
+// numberStyleFor returns the default number style for the language
+func numberStyleFor(lang Language) NumberStyle {
+	if lang == LanguageEN {
+		return NumberStylePoint
+	}
+
+	return NumberStyleComma
+}
+
+// numberStyleByName returns the number style for a persisted format name
+func numberStyleByName(name string) (NumberStyle, bool) {
+	switch name {
+	case numberFormatComma:
+		return NumberStyleComma, true
+	case numberFormatPoint:
+		return NumberStylePoint, true
+	default:
+		return NumberStyle{}, false
+	}
+}
+
+// formatAmount formats an amount denominated in the given currency
+func formatAmount(value float64, currency fxrates.Currency, style NumberStyle) string {
+	return formatNumber(value, amountPrecision(value, currency), style)
+}
+
+// amountPrecision returns the number of decimals an amount in the given currency is shown with,
+// adding decimals as needed to keep the significant digits of amounts below one
+func amountPrecision(value float64, currency fxrates.Currency) int {
+	precision := precisionFor(currency)
+
+	if value > 0 && value < 1 {
+		precision = max(precision, significantDigits-1-int(math.Floor(math.Log10(value))))
+	}
+
+	return precision
+}
+
+// formatNumber formats the value with the given precision,
+// grouping thousands according to the style
+func formatNumber(value float64, precision int, style NumberStyle) string {
+	formatted := strconv.FormatFloat(math.Abs(value), 'f', precision, 64)
+	integer, fraction, _ := strings.Cut(formatted, ".")
+
+	var sb strings.Builder
+
+	// Avoid rendering "-0,00" for tiny negative values
+	if value < 0 && strings.Trim(integer+fraction, "0") != "" {
+		sb.WriteString("-")
+	}
+
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			sb.WriteString(style.Thousands)
+		}
+
+		sb.WriteRune(digit)
+	}
+
+	if fraction != "" {
+		sb.WriteString(style.Decimal)
+		sb.WriteString(fraction)
+	}
+
+	return sb.String()
+}
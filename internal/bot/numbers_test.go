@@ -0,0 +1,174 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+
+	"github.com/sig-0/fxrates/storage/types"
+)
+
+func TestNumbers_FormatNumber(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name      string
+		expected  string
+		style     NumberStyle
+		value     float64
+		precision int
+	}{
+		{
+			name:      "comma small",
+			value:     36.45,
+			precision: 2,
+			style:     NumberStyleComma,
+			expected:  "36,45",
+		},
+		{
+			name:      "comma thousands",
+			value:     1234567.891,
+			precision: 2,
+			style:     NumberStyleComma,
+			expected:  "1.234.567,89",
+		},
+		{
+			name:      "point thousands",
+			value:     1234567.891,
+			precision: 2,
+			style:     NumberStylePoint,
+			expected:  "1,234,567.89",
+		},
+		{
+			name:      "exact thousand",
+			value:     1000,
+			precision: 2,
+			style:     NumberStyleComma,
+			expected:  "1.000,00",
+		},
+		{
+			name:      "no decimals",
+			value:     999999,
+			precision: 0,
+			style:     NumberStylePoint,
+			expected:  "999,999",
+		},
+		{
+			name:      "negative",
+			value:     -1234.5,
+			precision: 2,
+			style:     NumberStyleComma,
+			expected:  "-1.234,50",
+		},
+		{
+			name:      "negative zero",
+			value:     -0.001,
+			precision: 2,
+			style:     NumberStyleComma,
+			expected:  "0,00",
+		},
+		{
+			name:      "rounding",
+			value:     0.123456789,
+			precision: 6,
+			style:     NumberStylePoint,
+			expected:  "0.123457",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expected, formatNumber(testCase.value, testCase.precision, testCase.style))
+		})
+	}
+}
+
+func TestNumbers_PrecisionFor(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 2, precisionFor(types.CurrencyVES))
+	assert.Equal(t, 2, precisionFor(types.CurrencyEUR))
+	assert.Equal(t, 0, precisionFor("JPY"))
+	assert.Equal(t, cryptoPrecision, precisionFor(types.CurrencyUSDT))
+	assert.Equal(t, defaultPrecision, precisionFor("XYZ"))
+}
+
+func TestNumbers_FormatAmount(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name     string
+		expected string
+		currency fxrates.Currency
+		value    float64
+	}{
+		{
+			name:     "currency precision",
+			expected: "1,234,567.89",
+			currency: types.CurrencyVES,
+			value:    1234567.891,
+		},
+		{
+			name:     "no minor units",
+			expected: "151",
+			currency: "JPY",
+			value:    151.34,
+		},
+		{
+			name:     "crypto",
+			expected: "1.000200",
+			currency: types.CurrencyUSDT,
+			value:    1.0002,
+		},
+		{
+			name:     "fraction",
+			expected: "0.9234",
+			currency: types.CurrencyEUR,
+			value:    0.92341,
+		},
+		{
+			name:     "small fraction",
+			expected: "0.02740",
+			currency: types.CurrencyUSD,
+			value:    1 / 36.5,
+		},
+		{
+			name:     "smaller fraction",
+			expected: "0.002000",
+			currency: types.CurrencyUSD,
+			value:    1.0 / 500,
+		},
+		{
+			name:     "currency precision is enough",
+			expected: "0.027397",
+			currency: types.CurrencyUSDT,
+			value:    1 / 36.5,
+		},
+		{
+			name:     "above one",
+			expected: "1.25",
+			currency: types.CurrencyUSD,
+			value:    1.25,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expected, formatAmount(testCase.value, testCase.currency, NumberStylePoint))
+		})
+	}
+}
+
+func TestNumbers_NumberStyleFor(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, NumberStyleComma, numberStyleFor(LanguageES))
+	assert.Equal(t, NumberStyleComma, numberStyleFor(LanguagePT))
+	assert.Equal(t, NumberStylePoint, numberStyleFor(LanguageEN))
+}
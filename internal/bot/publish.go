@@ -0,0 +1,200 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/store"
+)
+
+var errStoreNotConfigured = errors.New("store not configured")
+
+// ChannelPost is a rate posted to a channel on a schedule,
+// editing the same message instead of posting new ones
+type ChannelPost struct {
+	Base     fxrates.Currency
+	Target   fxrates.Currency
+	Language Language
+
+	ChatID int64
+
+	// Interval is the wait between updates of the message
+	Interval time.Duration
+
+	// Pin pins the message when it's first posted
+	Pin bool
+}
+
+// publishRate keeps the chat's message for the pair up to date, editing the
+// previously posted one when the rate changes, or posting a new one if there's none or it was deleted
+func (h *FxHandler) publishRate(
+	ctx context.Context,
+	b *bot.Bot,
+	chatID int64,
+	base, target fxrates.Currency,
+	lang Language,
+	pin bool,
+) error {
+	if h.fxClient == nil {
+		return errFXClientNotConfigured
+	}
+
+	if h.store == nil {
+		return errStoreNotConfigured
+	}
+
+	preferences := h.settings().preferences
+
+	rates, err := h.fxClient.Rate(ctx, base.String(), target.String(), preferences.Source(base).String())
+	if err != nil {
+		return fmt.Errorf("unable to fetch rate: %w", err)
+	}
+
+	rate := preferences.Select(rates.Results)
+	if rate == nil {
+		return fmt.Errorf("no rates for %s/%s", base, target)
+	}
+
+	chat, _ := h.store.Chat(chatID)
+
+	var (
+		digest     = ratesDigest([]fxrates.ExchangeRate{*rate})
+		pinned, ok = chat.PinnedFor(base.String(), target.String())
+		now        = h.clock.Now()
+		text       = PinnedRateMessage(*rate, now, h.localeFor(chatID, lang))
+	)
+
+	if ok && pinned.Digest == digest && pinned.Language == string(lang) {
+		return nil
+	}
+
+	if ok {
+		pinned.Digest = digest
+		pinned.Language = string(lang)
+
+		edited, err := h.editPinned(ctx, b, chatID, pinned, text, now)
+		if err != nil || edited {
+			return err
+		}
+
+		h.logger.Info("pinned message deleted, posting a new one", "chat_id", chatID, "message_id", pinned.MessageID)
+	}
+
+	return h.postPinned(ctx, b, chatID, store.PinnedMessage{
+		Base:     base.String(),
+		Target:   target.String(),
+		Language: string(lang),
+		Digest:   digest,
+	}, text, now, pin)
+}
+
+// editPinned edits the chat's message in place, and records when it was updated.
+// It reports false if the message no longer exists
+func (h *FxHandler) editPinned(
+	ctx context.Context,
+	b *bot.Bot,
+	chatID int64,
+	pinned store.PinnedMessage,
+	text string,
+	now time.Time,
+) (bool, error) {
+	_, err := b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    chatID,
+		MessageID: pinned.MessageID,
+		Text:      text,
+		ParseMode: h.settings().markup.ParseMode(),
+	})
+
+	switch {
+	case err == nil, isMessageNotModified(err):
+		pinned.UpdatedAt = now
+
+		return true, h.store.SetPinned(chatID, pinned)
+	case isMessageNotFound(err):
+		return false, nil
+	default:
+		return false, fmt.Errorf("unable to edit message: %w", err)
+	}
+}
+
+// postPinned posts a new message to the chat, pinning it if requested,
+// and records it as the chat's message to keep up to date
+func (h *FxHandler) postPinned(
+	ctx context.Context,
+	b *bot.Bot,
+	chatID int64,
+	pinned store.PinnedMessage,
+	text string,
+	now time.Time,
+	pin bool,
+) error {
+	message, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    chatID,
+		Text:      text,
+		ParseMode: h.settings().markup.ParseMode(),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to post message: %w", err)
+	}
+
+	if pin {
+		// The message is still kept up to date if it can't be pinned,
+		// like when the bot lacks the permission to
+		if _, err := b.PinChatMessage(ctx, &bot.PinChatMessageParams{
+			ChatID:              chatID,
+			MessageID:           message.ID,
+			DisableNotification: true,
+		}); err != nil {
+			h.logger.Warn("unable to pin message", "chat_id", chatID, "message_id", message.ID, "error", err)
+		}
+	}
+
+	pinned.MessageID = message.ID
+	pinned.UpdatedAt = now
+
+	return h.store.SetPinned(chatID, pinned)
+}
+
+// registerMembership registers the chats the bot is added to,
+// like channels, where it's made an administrator
+func (h *FxHandler) registerMembership(updated *models.ChatMemberUpdated) {
+	switch updated.NewChatMember.Type {
+	case models.ChatMemberTypeAdministrator, models.ChatMemberTypeMember:
+	default:
+		return
+	}
+
+	if h.store == nil {
+		return
+	}
+
+	chat := updated.Chat
+
+	registered, err := h.store.RegisterChat(chat.ID, string(chat.Type), h.clock.Now())
+	if err != nil {
+		h.logger.Error("unable to register chat", "chat_id", chat.ID, "error", err)
+
+		return
+	}
+
+	if registered {
+		h.logger.Info("added to chat", "chat_id", chat.ID, "type", chat.Type, "status", updated.NewChatMember.Type)
+	}
+}
+
+// isMessageNotModified checks if an edit failed because the text didn't change
+func isMessageNotModified(err error) bool {
+	return errors.Is(err, bot.ErrorBadRequest) && strings.Contains(err.Error(), "message is not modified")
+}
+
+// isMessageNotFound checks if an edit failed because the message was deleted
+func isMessageNotFound(err error) bool {
+	return errors.Is(err, bot.ErrorBadRequest) && strings.Contains(err.Error(), "message to edit not found")
+}
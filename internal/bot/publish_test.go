@@ -0,0 +1,233 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/store"
+
+	"github.com/sig-0/fxrates/storage/types"
+)
+
+const testChannelID int64 = -1001234567890
+
+// publishTestRate is the rate served to the publishing tests
+var publishTestRate = fxrates.ExchangeRate{
+	Base:     types.CurrencyUSD,
+	Target:   types.CurrencyVES,
+	Rate:     36.5,
+	RateType: types.RateTypeMID,
+	Source:   types.SourceBCV,
+	AsOf:     time.Date(2026, time.January, 2, 15, 4, 0, 0, time.UTC),
+}
+
+func TestPublishRate_PostsThenEdits(t *testing.T) {
+	t.Parallel()
+
+	fxServer, _ := newInlineFXServer(t, map[string]any{
+		"/v1/rates/USD/VES": page(publishTestRate),
+	})
+
+	chatStore := store.NewMemory()
+	h := newTestHandler(t, fxrates.NewClient(fxServer.URL, time.Second), chatStore, Settings{})
+
+	srv, requests := newMessageServer(t)
+	b := newTelegramBot(t, srv.URL)
+
+	// The first update posts and pins the message
+	require.NoError(t, h.publishRate(context.Background(), b, testChannelID, "USD", "VES", LanguageES, true))
+
+	posted := <-requests
+	assert.Equal(t, "sendMessage", posted.Method)
+	assert.Contains(t, posted.Text, "Actualizado:")
+
+	pinned := <-requests
+	assert.Equal(t, "pinChatMessage", pinned.Method)
+	assert.Equal(t, "10", pinned.MessageID)
+
+	chat, ok := chatStore.Chat(testChannelID)
+	require.True(t, ok)
+
+	message, ok := chat.PinnedFor("USD", "VES")
+	require.True(t, ok)
+	assert.Equal(t, 10, message.MessageID)
+
+	assert.Equal(t, ratesDigest([]fxrates.ExchangeRate{publishTestRate}), message.Digest)
+
+	// The next ones leave it alone while the rate is unchanged
+	require.NoError(t, h.publishRate(context.Background(), b, testChannelID, "USD", "VES", LanguageES, true))
+
+	assert.Empty(t, requests)
+
+	chat, ok = chatStore.Chat(testChannelID)
+	require.True(t, ok)
+
+	unchanged, ok := chat.PinnedFor("USD", "VES")
+	require.True(t, ok)
+	assert.Equal(t, message, unchanged)
+
+	// And edit it once the rate changes
+	message.Digest = "previous"
+	require.NoError(t, chatStore.SetPinned(testChannelID, message))
+
+	require.NoError(t, h.publishRate(context.Background(), b, testChannelID, "USD", "VES", LanguageES, true))
+
+	edited := <-requests
+	assert.Equal(t, "editMessageText", edited.Method)
+	assert.Equal(t, "10", edited.MessageID)
+
+	assert.Empty(t, requests)
+}
+
+func TestPublishRate_EditErrors(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name      string
+		editError string
+		methods   []string
+	}{
+		{
+			name:      "unchanged",
+			editError: "Bad Request: message is not modified",
+			methods:   []string{"editMessageText"},
+		},
+		{
+			name:      "deleted",
+			editError: "Bad Request: message to edit not found",
+			methods:   []string{"editMessageText", "sendMessage"},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			chatStore := store.NewMemory()
+			require.NoError(t, chatStore.SetPinned(testChannelID, store.PinnedMessage{
+				Base:      "USD",
+				Target:    "VES",
+				MessageID: 5,
+			}))
+
+			fxServer, _ := newInlineFXServer(t, map[string]any{
+				"/v1/rates/USD/VES": page(publishTestRate),
+			})
+
+			h := newTestHandler(t, fxrates.NewClient(fxServer.URL, time.Second), chatStore, Settings{})
+
+			srv, requests := newMessageServer(t, apiFailure{Method: "editMessageText", Description: testCase.editError})
+			b := newTelegramBot(t, srv.URL)
+
+			require.NoError(t, h.publishRate(context.Background(), b, testChannelID, "USD", "VES", LanguageES, false))
+
+			methods := make([]string, 0, len(testCase.methods))
+			for range testCase.methods {
+				methods = append(methods, (<-requests).Method)
+			}
+
+			assert.Equal(t, testCase.methods, methods)
+			assert.Empty(t, requests)
+
+			chat, ok := chatStore.Chat(testChannelID)
+			require.True(t, ok)
+
+			message, ok := chat.PinnedFor("USD", "VES")
+			require.True(t, ok)
+			assert.False(t, message.UpdatedAt.IsZero())
+		})
+	}
+}
+
+func TestHandler_ChannelPostCommand(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHandler(t, nil, store.NewMemory(), Settings{AdminUserIDs: []int64{testAdminID}})
+
+	update := &models.Update{
+		ChannelPost: &models.Message{
+			Text: "/ayuda",
+			Chat: models.Chat{ID: testChannelID, Type: models.ChatTypeChannel},
+		},
+	}
+
+	assert.True(t, matchCommand("/ayuda")(update))
+	assert.False(t, matchCommand("/tasa")(update))
+
+	// Channel posts have no sender
+	assert.False(t, h.isAdmin(update))
+
+	srv, messages := newMessageServer(t)
+	b := newTelegramBot(t, srv.URL)
+
+	h.Help(context.Background(), b, update)
+
+	message := receiveMessage(t, messages)
+	assert.Equal(t, testChannelID, message.ChatID)
+}
+
+func TestHandler_RegisterMembership(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name       string
+		status     models.ChatMemberType
+		registered bool
+	}{
+		{
+			name:       "made administrator",
+			status:     models.ChatMemberTypeAdministrator,
+			registered: true,
+		},
+		{
+			name:       "removed",
+			status:     models.ChatMemberTypeLeft,
+			registered: false,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := newTestHandler(t, nil, store.NewMemory(), Settings{AdminUserIDs: []int64{testAdminID}})
+
+			h.trackUpdate(&models.Update{
+				MyChatMember: &models.ChatMemberUpdated{
+					Chat:          models.Chat{ID: testChannelID, Type: models.ChatTypeChannel},
+					NewChatMember: models.ChatMember{Type: testCase.status},
+				},
+			}, nil)
+
+			_, ok := h.store.Chat(testChannelID)
+			assert.Equal(t, testCase.registered, ok)
+		})
+	}
+}
+
+func TestPinnedRateMessage(t *testing.T) {
+	t.Parallel()
+
+	rate := fxrates.ExchangeRate{
+		Base:     types.CurrencyUSD,
+		Target:   types.CurrencyVES,
+		Rate:     36.5,
+		RateType: types.RateTypeMID,
+		Source:   types.SourceBCV,
+	}
+
+	updatedAt := time.Date(2026, time.January, 2, 15, 4, 0, 0, time.UTC)
+
+	message := PinnedRateMessage(rate, updatedAt, NewLocale(LanguageEN))
+
+	assert.True(t, strings.HasPrefix(message, FormatRate(rate, NewLocale(LanguageEN))))
+	assert.Contains(t, message, "🔄 Updated:")
+	assert.Contains(t, message, NewLocale(LanguageEN).formatTime(updatedAt))
+}
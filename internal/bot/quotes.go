@@ -0,0 +1,194 @@
+package bot
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/go-telegram/bot/models"
+
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/i18n"
+	"github.com/sig-0/chigui-cifras/internal/preference"
+
+	"github.com/sig-0/fxrates/storage/types"
+)
+
+// quotesResultSuffix ends the ID of the BUY and SELL quotes inline result
+const quotesResultSuffix = "quotes"
+
+// sourceQuotes are the rates a source publishes for a pair
+type sourceQuotes struct {
+	Source fxrates.Source
+
+	// Buy and Sell are the source's BUY and SELL rates, if published
+	Buy  *fxrates.ExchangeRate
+	Sell *fxrates.ExchangeRate
+
+	// Others are the rest of the source's rates, like MID
+	Others []fxrates.ExchangeRate
+}
+
+// hasSpread checks if the source publishes both BUY and SELL rates
+func (q sourceQuotes) hasSpread() bool {
+	return q.Buy != nil && q.Sell != nil
+}
+
+// mid returns the midpoint between the BUY and SELL rates
+func (q sourceQuotes) mid() float64 {
+	return (q.Buy.Rate + q.Sell.Rate) / 2
+}
+
+// spread returns the gap between the BUY and SELL rates, as a percentage of their midpoint
+func (q sourceQuotes) spread() float64 {
+	mid := q.mid()
+	if mid == 0 {
+		return 0
+	}
+
+	return math.Abs(q.Sell.Rate-q.Buy.Rate) / mid * 100
+}
+
+// groupQuotes groups the pair's rates by source, in the order they're ranked by the policy
+func groupQuotes(rates []fxrates.ExchangeRate, preferences preference.Policy) []sourceQuotes {
+	var (
+		groups []sourceQuotes
+		index  = make(map[fxrates.Source]int)
+	)
+
+	for _, rate := range rankRates(rates, preferences) {
+		i, ok := index[rate.Source]
+		if !ok {
+			i = len(groups)
+			index[rate.Source] = i
+
+			groups = append(groups, sourceQuotes{Source: rate.Source})
+		}
+
+		switch rate.RateType {
+		case types.RateTypeBUY:
+			if groups[i].Buy == nil {
+				groups[i].Buy = &rate
+
+				continue
+			}
+		case types.RateTypeSELL:
+			if groups[i].Sell == nil {
+				groups[i].Sell = &rate
+
+				continue
+			}
+		}
+
+		groups[i].Others = append(groups[i].Others, rate)
+	}
+
+	return groups
+}
+
+// hasBuyAndSell checks if any source publishes both BUY and SELL rates for the pair
+func hasBuyAndSell(rates []fxrates.ExchangeRate) bool {
+	var (
+		buy  = make(map[fxrates.Source]struct{})
+		sell = make(map[fxrates.Source]struct{})
+	)
+
+	for _, rate := range rates {
+		switch rate.RateType {
+		case types.RateTypeBUY:
+			buy[rate.Source] = struct{}{}
+		case types.RateTypeSELL:
+			sell[rate.Source] = struct{}{}
+		default:
+			continue
+		}
+
+		_, hasBuy := buy[rate.Source]
+		_, hasSell := sell[rate.Source]
+
+		if hasBuy && hasSell {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FormatQuotes formats a pair's rates grouped by source, showing the BUY and SELL rates
+// of each source along with their midpoint and spread, like for P2P markets
+func FormatQuotes(rates []fxrates.ExchangeRate, loc Locale) string {
+	groups := groupQuotes(rates, loc.preferences())
+	if len(groups) == 0 {
+		return loc.text("rates.empty", nil)
+	}
+
+	var (
+		m      = loc.markup()
+		ranked = rankRates(rates, loc.preferences())
+		first  = ranked[0]
+	)
+
+	var sb strings.Builder
+
+	header := fmt.Sprintf("%s → %s", first.Base, first.Target)
+	sb.WriteString(m.Escape(getEmoji(first.Base)) + " " + m.Bold(header) + "\n")
+	sb.WriteString(m.Italic(pairNames(first.Base, first.Target, loc.Language)) + "\n")
+
+	amount := func(value float64) i18n.Raw {
+		return i18n.Raw(m.Code(formatAmount(value, first.Target, loc.Numbers)))
+	}
+
+	for _, quotes := range groups {
+		sb.WriteString("\n" + m.Bold(quotes.Source.String()) + "\n")
+
+		if quotes.hasSpread() {
+			sb.WriteString(loc.text("quotes.buy", i18n.Params{"rate": amount(quotes.Buy.Rate)}) + "\n")
+			sb.WriteString(loc.text("quotes.sell", i18n.Params{"rate": amount(quotes.Sell.Rate)}) + "\n")
+			sb.WriteString(loc.text("quotes.mid", i18n.Params{"rate": amount(quotes.mid())}) + "\n")
+			sb.WriteString(loc.text("quotes.spread", i18n.Params{
+				"spread": formatNumber(quotes.spread(), 2, loc.Numbers) + "%",
+			}) + "\n")
+		} else {
+			// A lone BUY or SELL rate is listed like any other
+			for _, rate := range []*fxrates.ExchangeRate{quotes.Buy, quotes.Sell} {
+				if rate != nil {
+					quotes.Others = append(quotes.Others, *rate)
+				}
+			}
+		}
+
+		for _, rate := range quotes.Others {
+			sb.WriteString(loc.text("quotes.other", i18n.Params{
+				"type": rate.RateType,
+				"rate": amount(rate.Rate),
+			}) + "\n")
+		}
+	}
+
+	sb.WriteString("\n" + timestampLines(first, loc))
+	sb.WriteString(valueDateLines(ranked, loc.now(), loc))
+	sb.WriteString(staleLine(ranked, loc.now(), loc))
+
+	return sb.String()
+}
+
+// quotesArticle builds the inline article for the pair's rates grouped by source
+func quotesArticle(rates []fxrates.ExchangeRate, loc Locale) *models.InlineQueryResultArticle {
+	var (
+		first = rankRates(rates, loc.preferences())[0]
+		pair  = fmt.Sprintf("%s/%s", first.Base, first.Target)
+	)
+
+	return &models.InlineQueryResultArticle{
+		ID:              strings.ToLower(first.Base.String()+"-"+first.Target.String()) + "-" + quotesResultSuffix,
+		Title:           translate(loc.Language, "quotes.title", i18n.Params{"pair": pair}),
+		Description:     translate(loc.Language, "quotes.description", nil),
+		ThumbnailURL:    emojiThumbnailURL(getEmoji(first.Base)),
+		ThumbnailWidth:  emojiThumbnailSize,
+		ThumbnailHeight: emojiThumbnailSize,
+		InputMessageContent: &models.InputTextMessageContent{
+			MessageText: FormatQuotes(rates, loc),
+			ParseMode:   loc.markup().ParseMode(),
+		},
+	}
+}
@@ -0,0 +1,232 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sig-0/chigui-cifras/internal/clock"
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/preference"
+	"github.com/sig-0/chigui-cifras/internal/store"
+
+	"github.com/sig-0/fxrates/storage/types"
+)
+
+var (
+	usdtBuy = fxrates.ExchangeRate{
+		Base:     types.CurrencyUSDT,
+		Target:   types.CurrencyVES,
+		Rate:     40,
+		RateType: types.RateTypeBUY,
+		Source:   types.SourceBinance,
+		AsOf:     crossAsOf,
+	}
+	usdtSell = fxrates.ExchangeRate{
+		Base:     types.CurrencyUSDT,
+		Target:   types.CurrencyVES,
+		Rate:     41,
+		RateType: types.RateTypeSELL,
+		Source:   types.SourceBinance,
+		AsOf:     crossAsOf,
+	}
+	usdtMid = fxrates.ExchangeRate{
+		Base:     types.CurrencyUSDT,
+		Target:   types.CurrencyVES,
+		Rate:     39,
+		RateType: types.RateTypeMID,
+		Source:   types.SourceBCV,
+		AsOf:     crossAsOf,
+	}
+)
+
+func TestQuotes_GroupQuotes(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name     string
+		rates    []fxrates.ExchangeRate
+		expected []sourceQuotes
+		spread   bool
+	}{
+		{
+			name:  "buy and sell",
+			rates: []fxrates.ExchangeRate{usdtSell, usdtBuy},
+			expected: []sourceQuotes{
+				{Source: types.SourceBinance, Buy: &usdtBuy, Sell: &usdtSell},
+			},
+			spread: true,
+		},
+		{
+			name:  "several sources",
+			rates: []fxrates.ExchangeRate{usdtBuy, usdtMid, usdtSell},
+			expected: []sourceQuotes{
+				{Source: types.SourceBinance, Buy: &usdtBuy, Sell: &usdtSell},
+				{Source: types.SourceBCV, Others: []fxrates.ExchangeRate{usdtMid}},
+			},
+			spread: true,
+		},
+		{
+			name:  "buy only",
+			rates: []fxrates.ExchangeRate{usdtBuy},
+			expected: []sourceQuotes{
+				{Source: types.SourceBinance, Buy: &usdtBuy},
+			},
+			spread: false,
+		},
+		{
+			name:     "no rates",
+			rates:    nil,
+			expected: nil,
+			spread:   false,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expected, groupQuotes(testCase.rates, preference.Default()))
+			assert.Equal(t, testCase.spread, hasBuyAndSell(testCase.rates))
+		})
+	}
+}
+
+func TestQuotes_MidAndSpread(t *testing.T) {
+	t.Parallel()
+
+	quotes := sourceQuotes{Source: types.SourceBinance, Buy: &usdtBuy, Sell: &usdtSell}
+
+	assert.InDelta(t, 40.5, quotes.mid(), 1e-9)
+	assert.InDelta(t, 100/40.5, quotes.spread(), 1e-9)
+
+	zero := usdtBuy
+	zero.Rate = 0
+
+	assert.Zero(t, sourceQuotes{Buy: &zero, Sell: &zero}.spread())
+}
+
+func TestFormatQuotes(t *testing.T) {
+	t.Parallel()
+
+	loc := NewLocale(LanguageEN)
+	loc.Clock = clock.Fixed(crossAsOf)
+
+	message := FormatQuotes([]fxrates.ExchangeRate{usdtMid, usdtBuy, usdtSell}, loc)
+
+	assert.Contains(t, message, "USDT → VES")
+	assert.Contains(t, message, "BINANCE\n"+
+		"🟢 Buy: 40.00\n"+
+		"🔴 Sell: 41.00\n"+
+		"⚖️ Mid: 40.50\n"+
+		"↔️ Spread: 2.47%\n")
+	assert.Contains(t, message, "BCV\n• MID: 39.00\n")
+
+	assert.Equal(t, "No rates found", FormatQuotes(nil, loc))
+}
+
+func TestHandler_Quotes(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name     string
+		command  string
+		handle   func(*FxHandler, context.Context, *bot.Bot, *models.Update)
+		rates    []fxrates.ExchangeRate
+		expected []string
+	}{
+		{
+			name:     "usdt",
+			command:  "/usdt",
+			handle:   defaultShortcut(t, "/usdt"),
+			rates:    []fxrates.ExchangeRate{usdtBuy, usdtSell},
+			expected: []string{"🟢 Compra: 40,00", "🔴 Venta: 41,00", "⚖️ Media: 40,50", "↔️ Spread: 2,47%"},
+		},
+		{
+			name:     "usdt single rate",
+			command:  "/usdt",
+			handle:   defaultShortcut(t, "/usdt"),
+			rates:    []fxrates.ExchangeRate{usdtBuy},
+			expected: []string{"BINANCE\n• BUY: 40,00"},
+		},
+		{
+			name:     "rate with buy and sell",
+			command:  "/tasa USDT",
+			handle:   (*FxHandler).Rate,
+			rates:    []fxrates.ExchangeRate{usdtBuy, usdtSell},
+			expected: []string{"⚖️ Media: 40,50"},
+		},
+		{
+			name:     "rate without buy and sell",
+			command:  "/tasa USDT",
+			handle:   (*FxHandler).Rate,
+			rates:    []fxrates.ExchangeRate{usdtMid},
+			expected: []string{"Tasa: 39,00"},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fxServer, _ := newInlineFXServer(t, map[string]any{
+				"/v1/rates/USDT/VES": page(testCase.rates...),
+			})
+			h := newTestHandler(t, fxrates.NewClient(fxServer.URL, time.Second), store.NewMemory(), Settings{})
+
+			srv, messages := newMessageServer(t)
+			b := newTelegramBot(t, srv.URL)
+
+			testCase.handle(h, context.Background(), b, commandUpdate(1, testCase.command))
+
+			text := receiveMessage(t, messages).Text
+
+			for _, expected := range testCase.expected {
+				assert.Contains(t, text, expected)
+			}
+		})
+	}
+}
+
+func TestInlineQuery_Quotes(t *testing.T) {
+	t.Parallel()
+
+	fxServer, _ := newInlineFXServer(t, map[string]any{
+		"/v1/rates/USDT/VES": page(usdtBuy, usdtSell),
+		"/v1/rates/VES/USDT": page(),
+		"/v1/rates/USDT":     page(),
+	})
+
+	tgServer, requests := newInlineServer(t)
+	t.Cleanup(tgServer.Close)
+
+	h := newTestHandler(t, fxrates.NewClient(fxServer.URL, time.Second), store.NewMemory(), Settings{})
+	b := newTelegramBot(t, tgServer.URL)
+
+	h.InlineQuery(context.Background(), b, &models.Update{
+		InlineQuery: &models.InlineQuery{
+			ID:    "inline-1",
+			Query: "USDT VES",
+			From:  &models.User{LanguageCode: "en-US"},
+		},
+	})
+
+	request := awaitInlineRequest(t, requests)
+	require.Len(t, request.Results, 3)
+
+	quotes := request.Results[2]
+
+	assert.Equal(t, "usdt-ves-quotes", resultString(quotes, "id"))
+	assert.Equal(t, "USDT/VES buy and sell", resultString(quotes, "title"))
+	assert.Contains(t, resultMessageText(t, quotes), "↔️ Spread: 2.47%")
+
+	base, target, ok := parseResultID(resultString(quotes, "id"))
+	require.True(t, ok)
+	assert.Equal(t, types.CurrencyUSDT, base)
+	assert.Equal(t, types.CurrencyVES, target)
+}
@@ -0,0 +1,366 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sig-0/fxrates/provider/currencies"
+
+	"github.com/sig-0/chigui-cifras/internal/clock"
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+)
+
+const (
+	// currencyListTTL is how long the supported currency list is cached
+	currencyListTTL = 10 * time.Minute
+
+	// maxSuggestions is the maximum number of "did you mean" suggestions
+	maxSuggestions = 3
+
+	// maxSuggestionDistance is the maximum edit distance for a suggestion
+	maxSuggestionDistance = 2
+)
+
+var errFXClientNotConfigured = errors.New("fxrates client not configured")
+
+// currencyAliases maps normalized user input (names, symbols and common typos)
+// to their ISO currency code. Keys are lower-case and accent-free
+var currencyAliases = map[string]fxrates.Currency{
+	// USD
+	"$":        currencies.USD,
+	"us$":      currencies.USD,
+	"dolar":    currencies.USD,
+	"dolares":  currencies.USD,
+	"dolars":   currencies.USD,
+	"dollar":   currencies.USD,
+	"dollars":  currencies.USD,
+	"dollares": currencies.USD,
+	"dolla":    currencies.USD,
+	"dolr":     currencies.USD,
+	"verdes":   currencies.USD,
+
+	// EUR
+	"€":     currencies.EUR,
+	"euro":  currencies.EUR,
+	"euros": currencies.EUR,
+	"eur0":  currencies.EUR,
+	"uero":  currencies.EUR,
+	"ueros": currencies.EUR,
+
+	// VES
+	"bs":        currencies.VES,
+	"bs.":       currencies.VES,
+	"bs.s":      currencies.VES,
+	"bs.d":      currencies.VES,
+	"bsd":       currencies.VES,
+	"bss":       currencies.VES,
+	"bolivar":   currencies.VES,
+	"bolivares": currencies.VES,
+	"bolivars":  currencies.VES,
+	"bolos":     currencies.VES,
+	"bolibar":   currencies.VES,
+
+	// USDT
+	"₮":      currencies.USDT,
+	"tether": currencies.USDT,
+	"teter":  currencies.USDT,
+	"tehter": currencies.USDT,
+	"usdtt":  currencies.USDT,
+
+	// RUB
+	"₽":       currencies.RUB,
+	"rublo":   currencies.RUB,
+	"rublos":  currencies.RUB,
+	"ruble":   currencies.RUB,
+	"rubles":  currencies.RUB,
+	"rouble":  currencies.RUB,
+	"roubles": currencies.RUB,
+
+	// TRY
+	"₺":     currencies.TRY,
+	"lira":  currencies.TRY,
+	"liras": currencies.TRY,
+
+	// CNY
+	"¥":        currencies.CNY,
+	"元":        currencies.CNY,
+	"yuan":     currencies.CNY,
+	"yuanes":   currencies.CNY,
+	"yuans":    currencies.CNY,
+	"renminbi": currencies.CNY,
+	"rmb":      currencies.CNY,
+}
+
+// accentReplacer strips the Spanish diacritics users commonly type
+var accentReplacer = strings.NewReplacer(
+	"á", "a",
+	"é", "e",
+	"í", "i",
+	"ó", "o",
+	"ú", "u",
+	"ü", "u",
+	"ñ", "n",
+)
+
+// UnknownCurrencyError is returned when the user input
+// can't be resolved to a supported currency
+type UnknownCurrencyError struct {
+	Input       string
+	Suggestions []fxrates.Currency
+}
+
+func (e *UnknownCurrencyError) Error() string {
+	return fmt.Sprintf("unknown currency %q", e.Input)
+}
+
+// currencyResolver resolves user input (codes, names, symbols)
+// into currency codes supported by the fxrates API
+type currencyResolver struct {
+	fetchedAt time.Time
+	fxClient  *fxrates.Client
+	logger    *slog.Logger
+	clock     clock.Clock
+	supported []fxrates.Currency
+	ttl       time.Duration
+	mux       sync.Mutex
+}
+
+// newCurrencyResolver creates a new currency resolver, telling the age of the cached list by the clock
+func newCurrencyResolver(fxClient *fxrates.Client, logger *slog.Logger, clk clock.Clock) *currencyResolver {
+	return &currencyResolver{
+		fxClient: fxClient,
+		logger:   logger,
+		clock:    clock.Or(clk),
+		ttl:      currencyListTTL,
+	}
+}
+
+// setTTL sets how long the supported currency list is cached.
+// A non-positive TTL restores the default
+func (r *currencyResolver) setTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = currencyListTTL
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.ttl = ttl
+}
+
+// Resolve resolves the given user input into a supported currency code.
+// If the supported currency list is unavailable, the input is resolved
+// without validation, so an API hiccup doesn't block well-formed queries
+func (r *currencyResolver) Resolve(ctx context.Context, input string) (fxrates.Currency, error) {
+	normalized := normalizeCurrencyInput(input)
+
+	code, ok := currencyAliases[normalized]
+	if !ok {
+		code = fxrates.Currency(strings.ToUpper(normalized))
+	}
+
+	supported, err := r.currencies(ctx)
+	if err != nil {
+		r.logger.Warn("unable to fetch supported currencies", "error", err)
+
+		return code, nil
+	}
+
+	for _, currency := range supported {
+		if currency == code {
+			return code, nil
+		}
+	}
+
+	return "", &UnknownCurrencyError{
+		Input:       strings.TrimSpace(input),
+		Suggestions: suggestCurrencies(normalized, supported),
+	}
+}
+
+// Complete returns the supported currencies whose code starts with the given
+// partial input or, failing that, whose aliases do, the most popular first.
+// It returns nothing if the supported currency list is unavailable
+func (r *currencyResolver) Complete(ctx context.Context, input string) []fxrates.Currency {
+	normalized := normalizeCurrencyInput(input)
+	if normalized == "" {
+		return nil
+	}
+
+	supported, err := r.currencies(ctx)
+	if err != nil {
+		r.logger.Warn("unable to fetch supported currencies", "error", err)
+
+		return nil
+	}
+
+	isSupported := make(map[fxrates.Currency]bool, len(supported))
+	for _, currency := range supported {
+		isSupported[currency] = true
+	}
+
+	matches := make(map[fxrates.Currency]struct{})
+
+	for _, currency := range supported {
+		if strings.HasPrefix(strings.ToLower(currency.String()), normalized) {
+			matches[currency] = struct{}{}
+		}
+	}
+
+	if len(matches) == 0 {
+		// Only complete names once the input no longer matches a code,
+		// so typo aliases don't crowd out the codes
+		for alias, currency := range currencyAliases {
+			if isSupported[currency] && strings.HasPrefix(alias, normalized) {
+				matches[currency] = struct{}{}
+			}
+		}
+	}
+
+	completions := make([]fxrates.Currency, 0, len(matches))
+	for currency := range matches {
+		completions = append(completions, currency)
+	}
+
+	sort.Slice(completions, func(i, j int) bool {
+		ri, rj := popularityRank(completions[i]), popularityRank(completions[j])
+		if ri != rj {
+			return ri < rj
+		}
+
+		return completions[i] < completions[j]
+	})
+
+	return completions
+}
+
+// currencies returns the cached supported currency list, refreshing it if stale
+func (r *currencyResolver) currencies(ctx context.Context) ([]fxrates.Currency, error) {
+	if r.fxClient == nil {
+		return nil, errFXClientNotConfigured
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if r.supported != nil && r.clock.Now().Sub(r.fetchedAt) < r.ttl {
+		return r.supported, nil
+	}
+
+	response, err := r.fxClient.Currencies(ctx)
+	if err != nil {
+		if r.supported != nil {
+			// Serve the stale list rather than failing
+			return r.supported, nil
+		}
+
+		return nil, err
+	}
+
+	r.supported = response.Results
+	r.fetchedAt = r.clock.Now()
+
+	return r.supported, nil
+}
+
+// normalizeCurrencyInput lower-cases and strips accents from the user input
+func normalizeCurrencyInput(input string) string {
+	return accentReplacer.Replace(strings.ToLower(strings.TrimSpace(input)))
+}
+
+// suggestCurrencies returns the supported currencies closest to the given input,
+// matching against both ISO codes and known aliases
+func suggestCurrencies(normalized string, supported []fxrates.Currency) []fxrates.Currency {
+	if normalized == "" {
+		return nil
+	}
+
+	isSupported := make(map[fxrates.Currency]bool, len(supported))
+	for _, currency := range supported {
+		isSupported[currency] = true
+	}
+
+	best := make(map[fxrates.Currency]int)
+
+	consider := func(candidate string, currency fxrates.Currency) {
+		if !isSupported[currency] {
+			return
+		}
+
+		distance := levenshtein(normalized, candidate)
+
+		if distance > maxSuggestionDistance && !strings.HasPrefix(candidate, normalized) {
+			return
+		}
+
+		if current, ok := best[currency]; !ok || distance < current {
+			best[currency] = distance
+		}
+	}
+
+	for _, currency := range supported {
+		consider(strings.ToLower(currency.String()), currency)
+	}
+
+	for alias, currency := range currencyAliases {
+		consider(alias, currency)
+	}
+
+	suggestions := make([]fxrates.Currency, 0, len(best))
+	for currency := range best {
+		suggestions = append(suggestions, currency)
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if best[suggestions[i]] != best[suggestions[j]] {
+			return best[suggestions[i]] < best[suggestions[j]]
+		}
+
+		return suggestions[i] < suggestions[j]
+	})
+
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+
+	return suggestions
+}
+
+// levenshtein computes the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(
+				previous[j]+1,
+				current[j-1]+1,
+				previous[j-1]+cost,
+			)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
@@ -0,0 +1,220 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+
+	"github.com/sig-0/fxrates/storage/types"
+)
+
+func newCurrenciesServer(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	response := fxrates.CurrenciesResponse{
+		Results: []fxrates.Currency{
+			types.CurrencyUSD,
+			types.CurrencyEUR,
+			types.CurrencyVES,
+			types.CurrencyUSDT,
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls != nil {
+			calls.Add(1)
+		}
+
+		assert.Equal(t, "/v1/currencies", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(response))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	t.Parallel()
+
+	srv := newCurrenciesServer(t, nil)
+	resolver := newCurrencyResolver(fxrates.NewClient(srv.URL, time.Second), slog.Default(), nil)
+
+	testTable := []struct {
+		name     string
+		input    string
+		expected fxrates.Currency
+	}{
+		{name: "iso code", input: "usd", expected: types.CurrencyUSD},
+		{name: "upper iso code", input: "VES", expected: types.CurrencyVES},
+		{name: "spanish name", input: "dolar", expected: types.CurrencyUSD},
+		{name: "spanish name with accent", input: "Dólar", expected: types.CurrencyUSD},
+		{name: "english name", input: "dollars", expected: types.CurrencyUSD},
+		{name: "dollar symbol", input: "$", expected: types.CurrencyUSD},
+		{name: "euro symbol", input: "€", expected: types.CurrencyEUR},
+		{name: "bolivar symbol", input: "Bs", expected: types.CurrencyVES},
+		{name: "bolivar symbol with dot", input: "Bs.", expected: types.CurrencyVES},
+		{name: "bolivar name", input: "bolívares", expected: types.CurrencyVES},
+		{name: "tether symbol", input: "₮", expected: types.CurrencyUSDT},
+		{name: "tether name", input: "tether", expected: types.CurrencyUSDT},
+		{name: "common typo", input: "dolares", expected: types.CurrencyUSD},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			currency, err := resolver.Resolve(context.Background(), testCase.input)
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, currency)
+		})
+	}
+}
+
+func TestResolver_Unknown(t *testing.T) {
+	t.Parallel()
+
+	srv := newCurrenciesServer(t, nil)
+	resolver := newCurrencyResolver(fxrates.NewClient(srv.URL, time.Second), slog.Default(), nil)
+
+	t.Run("unsupported alias", func(t *testing.T) {
+		t.Parallel()
+
+		// RUB is a known alias, but not supported by the API
+		_, err := resolver.Resolve(context.Background(), "rublo")
+
+		var unknownErr *UnknownCurrencyError
+		require.ErrorAs(t, err, &unknownErr)
+		assert.Equal(t, "rublo", unknownErr.Input)
+	})
+
+	t.Run("suggestions", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := resolver.Resolve(context.Background(), "UDS")
+
+		var unknownErr *UnknownCurrencyError
+		require.ErrorAs(t, err, &unknownErr)
+		assert.Contains(t, unknownErr.Suggestions, types.CurrencyUSD)
+	})
+
+	t.Run("name typo suggestions", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := resolver.Resolve(context.Background(), "eruo")
+
+		var unknownErr *UnknownCurrencyError
+		require.ErrorAs(t, err, &unknownErr)
+		assert.Equal(t, types.CurrencyEUR, unknownErr.Suggestions[0])
+	})
+
+	t.Run("no suggestions", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := resolver.Resolve(context.Background(), "zzzzzzzz")
+
+		var unknownErr *UnknownCurrencyError
+		require.ErrorAs(t, err, &unknownErr)
+		assert.Empty(t, unknownErr.Suggestions)
+	})
+}
+
+func TestResolver_CachesCurrencies(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	srv := newCurrenciesServer(t, &calls)
+	resolver := newCurrencyResolver(fxrates.NewClient(srv.URL, time.Second), slog.Default(), nil)
+
+	for range 3 {
+		_, err := resolver.Resolve(context.Background(), "USD")
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestResolver_UnavailableCurrencies(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	resolver := newCurrencyResolver(fxrates.NewClient(srv.URL, time.Second), slog.Default(), nil)
+
+	currency, err := resolver.Resolve(context.Background(), "dólar")
+
+	require.NoError(t, err)
+	assert.Equal(t, types.CurrencyUSD, currency)
+}
+
+func TestResolver_Complete(t *testing.T) {
+	t.Parallel()
+
+	srv := newCurrenciesServer(t, nil)
+	resolver := newCurrencyResolver(fxrates.NewClient(srv.URL, time.Second), slog.Default(), nil)
+
+	testTable := []struct {
+		name     string
+		input    string
+		expected []fxrates.Currency
+	}{
+		{
+			name:     "code prefix, popular first",
+			input:    "U",
+			expected: []fxrates.Currency{types.CurrencyUSD, types.CurrencyUSDT},
+		},
+		{
+			name:     "lower-case code prefix",
+			input:    "eu",
+			expected: []fxrates.Currency{types.CurrencyEUR},
+		},
+		{
+			name:     "name prefix",
+			input:    "dól",
+			expected: []fxrates.Currency{types.CurrencyUSD},
+		},
+		{
+			name:     "unsupported alias",
+			input:    "rub",
+			expected: []fxrates.Currency{},
+		},
+		{
+			name:     "empty",
+			input:    " ",
+			expected: nil,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expected, resolver.Complete(context.Background(), testCase.input))
+		})
+	}
+}
+
+func TestResolver_Levenshtein(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 0, levenshtein("usd", "usd"))
+	assert.Equal(t, 1, levenshtein("usd", "usdt"))
+	assert.Equal(t, 2, levenshtein("uds", "usd"))
+	assert.Equal(t, 3, levenshtein("", "eur"))
+}
@@ -0,0 +1,114 @@
+package bot
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/sig-0/fxrates/provider/currencies"
+	"github.com/sig-0/fxrates/provider/ves"
+
+	"github.com/sig-0/chigui-cifras/internal/analytics"
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/i18n"
+)
+
+// Shortcut is a command replying with the rate of a fixed pair, like /dolar for USD/VES
+type Shortcut struct {
+	// Command is the shortcut command, like "/dolar"
+	Command string
+
+	Base fxrates.Currency
+
+	// Target is the pair's target. If empty, VES is used
+	Target fxrates.Currency
+
+	// Source is the source the rate is fetched from.
+	// If empty, the rates of every source are shown, grouped by source
+	Source fxrates.Source
+}
+
+// pair returns the shortcut's pair, with the default target if none is set
+func (s Shortcut) pair() (fxrates.Currency, fxrates.Currency) {
+	return s.Base, cmp.Or(s.Target, currencies.VES)
+}
+
+// DefaultShortcuts returns the shortcuts available when none are configured:
+// the BCV official rates and every USDT source, against VES
+func DefaultShortcuts() []Shortcut {
+	return []Shortcut{
+		{Command: "/dolar", Base: currencies.USD, Target: currencies.VES, Source: ves.BCVSource},
+		{Command: "/euro", Base: currencies.EUR, Target: currencies.VES, Source: ves.BCVSource},
+		{Command: "/usdt", Base: currencies.USDT, Target: currencies.VES},
+		{Command: "/rublo", Base: currencies.RUB, Target: currencies.VES, Source: ves.BCVSource},
+		{Command: "/lira", Base: currencies.TRY, Target: currencies.VES, Source: ves.BCVSource},
+		{Command: "/yuan", Base: currencies.CNY, Target: currencies.VES, Source: ves.BCVSource},
+	}
+}
+
+// ShortcutHandler returns the handler of the shortcut command
+func (h *FxHandler) ShortcutHandler(shortcut Shortcut) bot.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		h.rateShortcut(ctx, b, update, shortcut)
+	}
+}
+
+// rateShortcut replies with the shortcut's preferred rate, or with every source's rates if it has none set
+func (h *FxHandler) rateShortcut(ctx context.Context, b *bot.Bot, update *models.Update, shortcut Shortcut) {
+	var (
+		loc          = h.localeFor(updateMessage(update).Chat.ID, LanguageES)
+		base, target = shortcut.pair()
+	)
+
+	h.recordEvent(analytics.Event{Base: base.String(), Target: target.String()})
+
+	rates, err := h.fxClient.Rate(ctx, base.String(), target.String(), shortcut.Source.String())
+	if err == nil {
+		if shortcut.Source == "" && len(rates.Results) > 0 {
+			h.reply(ctx, b, update, FormatQuotes(rates.Results, loc))
+
+			return
+		}
+
+		if h.replyRates(ctx, b, update, rates.Results, loc) {
+			return
+		}
+	}
+
+	// Without a direct rate, invert the pair published the other way around
+	inverted, invertErr := h.preferredInvertedRate(ctx, base, target)
+
+	switch {
+	case invertErr == nil:
+		h.reply(ctx, b, update, FormatInvertedRate(*inverted, loc))
+	case err != nil:
+		h.reply(ctx, b, update, ErrorMessage(err, loc))
+	default:
+		h.reply(ctx, b, update, NoRatesForPairMessage(base, target, loc))
+	}
+}
+
+// shortcutsHelp lists the shortcuts for the help message, or returns nothing if there are none
+func shortcutsHelp(shortcuts []Shortcut, loc Locale) string {
+	if len(shortcuts) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(loc.text("shortcuts.header", nil) + "\n")
+
+	for _, shortcut := range shortcuts {
+		base, target := shortcut.pair()
+
+		sb.WriteString(loc.text("shortcuts.line", i18n.Params{
+			"command": shortcut.Command,
+			"pair":    fmt.Sprintf("%s/%s", base, target),
+		}) + "\n")
+	}
+
+	return sb.String() + "\n"
+}
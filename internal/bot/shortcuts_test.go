@@ -0,0 +1,166 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/store"
+
+	"github.com/sig-0/fxrates/storage/types"
+)
+
+// defaultShortcut returns the handler of the default shortcut with the given command
+func defaultShortcut(t *testing.T, command string) func(*FxHandler, context.Context, *bot.Bot, *models.Update) {
+	t.Helper()
+
+	shortcuts := DefaultShortcuts()
+
+	index := slices.IndexFunc(shortcuts, func(shortcut Shortcut) bool {
+		return shortcut.Command == command
+	})
+	require.NotEqual(t, -1, index, "no default shortcut %s", command)
+
+	return func(h *FxHandler, ctx context.Context, b *bot.Bot, update *models.Update) {
+		h.ShortcutHandler(shortcuts[index])(ctx, b, update)
+	}
+}
+
+func TestShortcut_Pair(t *testing.T) {
+	t.Parallel()
+
+	base, target := Shortcut{Command: "/peso", Base: "COP"}.pair()
+	assert.Equal(t, fxrates.Currency("COP"), base)
+	assert.Equal(t, types.CurrencyVES, target)
+
+	base, target = Shortcut{Command: "/real", Base: "BRL", Target: types.CurrencyUSD}.pair()
+	assert.Equal(t, fxrates.Currency("BRL"), base)
+	assert.Equal(t, types.CurrencyUSD, target)
+}
+
+func TestHandler_DefaultShortcuts(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name      string
+		shortcuts []Shortcut
+		expected  []Shortcut
+	}{
+		{
+			name:      "unset",
+			shortcuts: nil,
+			expected:  DefaultShortcuts(),
+		},
+		{
+			name:      "disabled",
+			shortcuts: []Shortcut{},
+			expected:  []Shortcut{},
+		},
+		{
+			name:      "configured",
+			shortcuts: []Shortcut{{Command: "/peso", Base: "COP"}},
+			expected:  []Shortcut{{Command: "/peso", Base: "COP"}},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := newTestHandler(t, nil, store.NewMemory(), Settings{Shortcuts: testCase.shortcuts})
+
+			assert.Equal(t, testCase.expected, h.shortcuts)
+		})
+	}
+}
+
+func TestBot_RegisterShortcuts(t *testing.T) {
+	t.Parallel()
+
+	rate := func(base fxrates.Currency, value float64) fxrates.ExchangeRate {
+		return fxrates.ExchangeRate{
+			Base:     base,
+			Target:   types.CurrencyVES,
+			Rate:     value,
+			RateType: types.RateTypeMID,
+			Source:   types.SourceBCV,
+			AsOf:     crossAsOf,
+		}
+	}
+
+	fxServer, fxPaths := newInlineFXServer(t, map[string]any{
+		"/v1/rates/COP/VES": page(rate("COP", 0.01)),
+		"/v1/rates/ARS/VES": page(rate("ARS", 0.04)),
+	})
+
+	h := newTestHandler(
+		t,
+		fxrates.NewClient(fxServer.URL, time.Second),
+		store.NewMemory(),
+		Settings{
+			Shortcuts: []Shortcut{
+				{Command: "/peso", Base: "COP", Source: types.SourceBCV},
+				{Command: "/peso_ar", Base: "ARS", Source: types.SourceBCV},
+			},
+		},
+	)
+
+	srv, messages := newMessageServer(t)
+
+	b := &Bot{
+		bot:      newTelegramBot(t, srv.URL),
+		handler:  h,
+		logger:   slog.Default(),
+		commands: make(map[string]struct{}),
+	}
+	b.registerHandlers()
+
+	assert.Contains(t, b.commands, "/peso")
+	assert.Contains(t, b.commands, "/peso_ar")
+	assert.NotContains(t, b.commands, "/dolar")
+
+	// The longer shortcut isn't shadowed by the one it starts with
+	b.bot.ProcessUpdate(context.Background(), commandUpdate(1, "/peso_ar"))
+
+	assert.Contains(t, receiveMessage(t, messages).Text, "ARS → VES")
+	assert.Equal(t, []string{"/v1/rates/ARS/VES"}, fxPaths())
+
+	// The help lists the configured shortcuts
+	b.bot.ProcessUpdate(context.Background(), commandUpdate(1, "/ayuda"))
+
+	assert.Contains(t, receiveMessage(t, messages).Text, "• /peso_ar - ARS/VES")
+}
+
+func TestBot_ShadowingCommand(t *testing.T) {
+	t.Parallel()
+
+	b := &Bot{commands: map[string]struct{}{"/tasa": {}, "/dolar": {}}}
+
+	testTable := []struct {
+		command   string
+		shadowing string
+	}{
+		{command: "/tasa_bcv", shadowing: "/tasa"},
+		{command: "/dolar", shadowing: "/dolar"},
+		{command: "/peso", shadowing: ""},
+		{command: "/tas", shadowing: ""},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.command, func(t *testing.T) {
+			t.Parallel()
+
+			shadowing, ok := b.shadowingCommand(testCase.command)
+			assert.Equal(t, testCase.shadowing != "", ok)
+			assert.Equal(t, testCase.shadowing, shadowing)
+		})
+	}
+}
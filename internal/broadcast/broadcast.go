@@ -0,0 +1,261 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+
+	"github.com/sig-0/chigui-cifras/internal/clock"
+	"github.com/sig-0/chigui-cifras/internal/store"
+)
+
+const (
+	// DefaultInterval keeps broadcasts well under
+	// Telegram's limit of 30 messages per second
+	DefaultInterval = 50 * time.Millisecond
+
+	// maxRetries is how many times a rate limited message is retried
+	maxRetries = 3
+)
+
+// ErrRunning is returned when a broadcast is already being delivered
+var ErrRunning = errors.New("broadcast already running")
+
+// Sender sends a plain text message to a chat
+type Sender interface {
+	SendMessage(ctx context.Context, chatID int64, text string) error
+}
+
+// TelegramSender sends messages through the Telegram Bot API
+type TelegramSender struct {
+	bot *bot.Bot
+}
+
+// NewTelegramSender creates a new Telegram sender
+func NewTelegramSender(b *bot.Bot) *TelegramSender {
+	return &TelegramSender{bot: b}
+}
+
+// SendMessage sends the text verbatim, as plain text
+func (s *TelegramSender) SendMessage(ctx context.Context, chatID int64, text string) error {
+	_, err := s.bot.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   text,
+	})
+
+	return err
+}
+
+// Report summarizes the deliveries of a broadcast
+type Report struct {
+	StartedAt  time.Time
+	FinishedAt time.Time
+
+	Pending int
+	Sent    int
+	Failed  int
+	Blocked int
+}
+
+// NewReport summarizes the deliveries of the broadcast
+func NewReport(broadcast store.Broadcast) Report {
+	report := Report{
+		StartedAt:  broadcast.StartedAt,
+		FinishedAt: broadcast.FinishedAt,
+	}
+
+	for _, status := range broadcast.Deliveries {
+		switch status {
+		case store.DeliverySent:
+			report.Sent++
+		case store.DeliveryFailed:
+			report.Failed++
+		case store.DeliveryBlocked:
+			report.Blocked++
+		default:
+			report.Pending++
+		}
+	}
+
+	return report
+}
+
+// Total returns the number of recipients
+func (r Report) Total() int {
+	return r.Pending + r.Sent + r.Failed + r.Blocked
+}
+
+// Broadcaster delivers announcements to every known chat.
+// Broadcasts are persisted in the store, so an interrupted one
+// resumes where it stopped
+type Broadcaster struct {
+	store  *store.Store
+	logger *slog.Logger
+	queue  *Queue
+	clock  clock.Clock
+	mux    sync.Mutex
+}
+
+// New creates a new broadcaster, sending the messages through the queue,
+// and recording when they're sent by the clock. If the clock is nil, the system clock is used
+func New(chatStore *store.Store, logger *slog.Logger, queue *Queue, clk clock.Clock) *Broadcaster {
+	return &Broadcaster{
+		store:  chatStore,
+		logger: logger,
+		queue:  queue,
+		clock:  clock.Or(clk),
+	}
+}
+
+// DryRun reports who a new broadcast would be sent to, without starting it.
+// Blocked counts the known chats that would be skipped
+func (b *Broadcaster) DryRun() Report {
+	recipients, blocked := b.recipients()
+
+	return Report{
+		Pending: len(recipients),
+		Blocked: blocked,
+	}
+}
+
+// Start persists a new broadcast of the text to every known chat,
+// except the blocked ones. It's delivered by Run
+func (b *Broadcaster) Start(text string) (Report, error) {
+	recipients, _ := b.recipients()
+
+	if err := b.store.StartBroadcast(text, recipients, b.clock.Now()); err != nil {
+		return Report{}, err
+	}
+
+	return b.status()
+}
+
+// Status reports the deliveries of the latest broadcast, if any
+func (b *Broadcaster) Status() (Report, bool) {
+	broadcast, ok := b.store.Broadcast()
+	if !ok {
+		return Report{}, false
+	}
+
+	return NewReport(broadcast), true
+}
+
+// Pending checks if the latest broadcast is unfinished
+func (b *Broadcaster) Pending() bool {
+	broadcast, ok := b.store.Broadcast()
+
+	return ok && !broadcast.Finished()
+}
+
+// Run delivers the pending messages of the unfinished broadcast, in chat ID order,
+// recording every delivery as it goes. It returns store.ErrNoBroadcast
+// if there's nothing to deliver, and ErrRunning if another run is delivering it
+func (b *Broadcaster) Run(ctx context.Context, sender Sender) (Report, error) {
+	if !b.mux.TryLock() {
+		return Report{}, ErrRunning
+	}
+	defer b.mux.Unlock()
+
+	broadcast, ok := b.store.Broadcast()
+	if !ok || broadcast.Finished() {
+		return Report{}, store.ErrNoBroadcast
+	}
+
+	pending := make([]int64, 0, len(broadcast.Deliveries))
+
+	for chatID, status := range broadcast.Deliveries {
+		if status == store.DeliveryPending {
+			pending = append(pending, chatID)
+		}
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i] < pending[j]
+	})
+
+	b.logger.Info("delivering broadcast",
+		"pending", len(pending),
+		"total", len(broadcast.Deliveries),
+	)
+
+	err := b.queue.Deliver(
+		ctx,
+		pending,
+		func(ctx context.Context, chatID int64) error {
+			return sender.SendMessage(ctx, chatID, broadcast.Text)
+		},
+		func(chatID int64, status store.DeliveryStatus) error {
+			return b.store.SetDelivery(chatID, status, b.clock.Now())
+		},
+	)
+	if err != nil {
+		return b.stopped(err)
+	}
+
+	if err := b.store.FinishBroadcast(b.clock.Now()); err != nil {
+		return b.stopped(err)
+	}
+
+	report, err := b.status()
+	if err != nil {
+		return Report{}, err
+	}
+
+	b.logger.Info("broadcast delivered",
+		"sent", report.Sent,
+		"failed", report.Failed,
+		"blocked", report.Blocked,
+	)
+
+	return report, nil
+}
+
+// recipients returns the known chats a new broadcast is sent to,
+// and the number of blocked chats it skips
+func (b *Broadcaster) recipients() ([]int64, int) {
+	var (
+		chats      = b.store.Chats()
+		recipients = make([]int64, 0, len(chats))
+		blocked    = 0
+	)
+
+	for _, chat := range chats {
+		if !chat.BlockedAt.IsZero() {
+			blocked++
+
+			continue
+		}
+
+		recipients = append(recipients, chat.ID)
+	}
+
+	return recipients, blocked
+}
+
+// status reports the deliveries of the latest broadcast
+func (b *Broadcaster) status() (Report, error) {
+	report, ok := b.Status()
+	if !ok {
+		return Report{}, store.ErrNoBroadcast
+	}
+
+	return report, nil
+}
+
+// stopped persists and reports the progress of an interrupted run, along with the reason
+func (b *Broadcaster) stopped(err error) (Report, error) {
+	if flushErr := b.store.Flush(); flushErr != nil {
+		err = errors.Join(err, flushErr)
+	}
+
+	report, _ := b.Status()
+
+	b.logger.Warn("broadcast interrupted", "pending", report.Pending, "error", err)
+
+	return report, err
+}
@@ -0,0 +1,210 @@
+package broadcast
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sig-0/chigui-cifras/internal/clock"
+	"github.com/sig-0/chigui-cifras/internal/store"
+)
+
+// mockSender records the sent messages, failing with the configured errors
+type mockSender struct {
+	// errs are the errors returned for each chat, in order
+	errs map[int64][]error
+
+	// onSend is called after every successful send, if set
+	onSend func(chatID int64)
+
+	sent []int64
+	mux  sync.Mutex
+}
+
+func (m *mockSender) SendMessage(_ context.Context, chatID int64, _ string) error {
+	m.mux.Lock()
+
+	if errs := m.errs[chatID]; len(errs) > 0 {
+		m.errs[chatID] = errs[1:]
+		m.mux.Unlock()
+
+		return errs[0]
+	}
+
+	m.sent = append(m.sent, chatID)
+	m.mux.Unlock()
+
+	if m.onSend != nil {
+		m.onSend(chatID)
+	}
+
+	return nil
+}
+
+func (m *mockSender) sentTo() []int64 {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	return append([]int64(nil), m.sent...)
+}
+
+func newTestStore(t *testing.T, chatIDs ...int64) *store.Store {
+	t.Helper()
+
+	s := store.NewMemory()
+
+	for _, chatID := range chatIDs {
+		_, err := s.RegisterChat(chatID, "group", time.Now())
+		require.NoError(t, err)
+	}
+
+	return s
+}
+
+func TestBroadcaster_Run(t *testing.T) {
+	t.Parallel()
+
+	var (
+		now         = time.Date(2026, time.January, 2, 15, 4, 0, 0, time.UTC)
+		chatStore   = newTestStore(t, 1, 2, 3, 4)
+		broadcaster = New(chatStore, slog.Default(), NewQueue(slog.Default(), time.Millisecond, nil), clock.Fixed(now))
+		sender      = &mockSender{
+			errs: map[int64][]error{
+				// Retried after the rate limit
+				1: {&bot.TooManyRequestsError{Message: "too many requests"}},
+				2: {fmt.Errorf("%w, bot was blocked by the user", bot.ErrorForbidden)},
+				3: {fmt.Errorf("%w, chat not found", bot.ErrorBadRequest)},
+			},
+		}
+	)
+
+	started, err := broadcaster.Start("hola")
+	require.NoError(t, err)
+	assert.Equal(t, 4, started.Pending)
+
+	report, err := broadcaster.Run(context.Background(), sender)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, report.Sent)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 1, report.Blocked)
+	assert.Zero(t, report.Pending)
+	assert.Equal(t, now, report.FinishedAt)
+
+	assert.Equal(t, []int64{1, 4}, sender.sentTo())
+
+	// Blocked chats are skipped by the next broadcast
+	chat, _ := chatStore.Chat(2)
+	assert.False(t, chat.BlockedAt.IsZero())
+
+	assert.Equal(t, Report{Pending: 3, Blocked: 1}, broadcaster.DryRun())
+
+	_, err = broadcaster.Run(context.Background(), sender)
+	assert.ErrorIs(t, err, store.ErrNoBroadcast)
+}
+
+func TestBroadcaster_DryRun(t *testing.T) {
+	t.Parallel()
+
+	var (
+		chatStore   = newTestStore(t, 1, 2)
+		broadcaster = New(chatStore, slog.Default(), NewQueue(slog.Default(), time.Millisecond, nil), nil)
+	)
+
+	assert.Equal(t, Report{Pending: 2}, broadcaster.DryRun())
+
+	// Nothing is persisted
+	_, ok := broadcaster.Status()
+	assert.False(t, ok)
+}
+
+func TestBroadcaster_Resume(t *testing.T) {
+	t.Parallel()
+
+	var (
+		chatStore   = newTestStore(t, 1, 2, 3)
+		broadcaster = New(chatStore, slog.Default(), NewQueue(slog.Default(), time.Millisecond, nil), nil)
+
+		ctx, cancelFn = context.WithCancel(context.Background())
+	)
+
+	defer cancelFn()
+
+	// Stop after the first message, like a restart would
+	interrupted := &mockSender{
+		onSend: func(int64) {
+			cancelFn()
+		},
+	}
+
+	_, err := broadcaster.Start("hola")
+	require.NoError(t, err)
+
+	_, err = broadcaster.Start("otra")
+	assert.ErrorIs(t, err, store.ErrBroadcastInProgress)
+
+	report, err := broadcaster.Run(ctx, interrupted)
+	require.ErrorIs(t, err, context.Canceled)
+
+	assert.Equal(t, 1, report.Sent)
+	assert.Equal(t, 2, report.Pending)
+	assert.True(t, broadcaster.Pending())
+
+	// Resuming only sends the pending messages
+	resumed := &mockSender{}
+
+	report, err = broadcaster.Run(context.Background(), resumed)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, report.Sent)
+	assert.Equal(t, []int64{2, 3}, resumed.sentTo())
+	assert.False(t, broadcaster.Pending())
+}
+
+func TestBroadcaster_RetriesExhausted(t *testing.T) {
+	t.Parallel()
+
+	var (
+		chatStore   = newTestStore(t, 1)
+		broadcaster = New(chatStore, slog.Default(), NewQueue(slog.Default(), time.Millisecond, nil), nil)
+		rateLimited = &bot.TooManyRequestsError{Message: "too many requests"}
+		sender      = &mockSender{
+			errs: map[int64][]error{
+				1: {rateLimited, rateLimited, rateLimited, rateLimited},
+			},
+		}
+	)
+
+	_, err := broadcaster.Start("hola")
+	require.NoError(t, err)
+
+	report, err := broadcaster.Run(context.Background(), sender)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Failed)
+	assert.Empty(t, sender.sentTo())
+}
+
+func TestReport_Total(t *testing.T) {
+	t.Parallel()
+
+	report := NewReport(store.Broadcast{
+		Deliveries: map[int64]store.DeliveryStatus{
+			1: store.DeliveryPending,
+			2: store.DeliverySent,
+			3: store.DeliveryFailed,
+			4: store.DeliveryBlocked,
+			5: store.DeliverySent,
+		},
+	})
+
+	assert.Equal(t, Report{Pending: 1, Sent: 2, Failed: 1, Blocked: 1}, report)
+	assert.Equal(t, 5, report.Total())
+}
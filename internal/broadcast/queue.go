@@ -0,0 +1,133 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+
+	"github.com/sig-0/chigui-cifras/internal/clock"
+	"github.com/sig-0/chigui-cifras/internal/store"
+)
+
+// SendFunc sends a message to a single chat
+type SendFunc func(ctx context.Context, chatID int64) error
+
+// RecordFunc records the delivery status of a message to a single chat
+type RecordFunc func(chatID int64, status store.DeliveryStatus) error
+
+// Queue sends messages to many chats, waiting the interval between them
+// to respect Telegram's limits, and retrying the rate limited ones.
+// Concurrent deliveries share the pacing, so they can't exceed the limits together
+type Queue struct {
+	logger *slog.Logger
+	clock  clock.Clock
+
+	// next is when the next message may be sent
+	next     time.Time
+	interval time.Duration
+	mux      sync.Mutex
+}
+
+// NewQueue creates a new queue, waiting the interval between messages by the clock.
+// If the interval is zero, DefaultInterval is used, and if the clock is nil, the system clock
+func NewQueue(logger *slog.Logger, interval time.Duration, clk clock.Clock) *Queue {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	return &Queue{
+		logger:   logger,
+		clock:    clock.Or(clk),
+		interval: interval,
+	}
+}
+
+// Deliver sends a message to every chat, in order, recording every delivery as it goes.
+// It stops early if the context is done, or a delivery can't be recorded
+func (q *Queue) Deliver(ctx context.Context, chatIDs []int64, send SendFunc, record RecordFunc) error {
+	for _, chatID := range chatIDs {
+		if err := q.wait(ctx); err != nil {
+			return err
+		}
+
+		status, err := q.deliver(ctx, chatID, send)
+		if err != nil {
+			return err
+		}
+
+		if err := record(chatID, status); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// wait blocks until the next message may be sent, reserving its turn
+func (q *Queue) wait(ctx context.Context) error {
+	q.mux.Lock()
+
+	now := q.clock.Now()
+
+	at := q.next
+	if at.Before(now) {
+		at = now
+	}
+
+	q.next = at.Add(q.interval)
+	q.mux.Unlock()
+
+	timer := time.NewTimer(at.Sub(now))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+	}
+
+	// Both may be ready at once, so don't rely on the select alone
+	return ctx.Err()
+}
+
+// deliver sends the message to a single chat, retrying when rate limited.
+// It only errors if the context is done, every other failure is a delivery status
+func (q *Queue) deliver(ctx context.Context, chatID int64, send SendFunc) (store.DeliveryStatus, error) {
+	for attempt := 0; ; attempt++ {
+		err := send(ctx, chatID)
+		if err == nil {
+			return store.DeliverySent, nil
+		}
+
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+
+		var tooManyRequests *bot.TooManyRequestsError
+
+		switch {
+		case errors.As(err, &tooManyRequests) && attempt < maxRetries:
+			retryAfter := time.Duration(tooManyRequests.RetryAfter) * time.Second
+
+			q.logger.Warn("message rate limited", "chat_id", chatID, "retry_after", retryAfter)
+
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(retryAfter):
+			}
+		case errors.Is(err, bot.ErrorForbidden):
+			q.logger.Info("chat blocked the bot", "chat_id", chatID, "error", err)
+
+			return store.DeliveryBlocked, nil
+		default:
+			q.logger.Warn("unable to deliver message", "chat_id", chatID, "error", err)
+
+			return store.DeliveryFailed, nil
+		}
+	}
+}
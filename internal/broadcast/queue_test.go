@@ -0,0 +1,81 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sig-0/chigui-cifras/internal/store"
+)
+
+func TestQueue_SharedPacing(t *testing.T) {
+	t.Parallel()
+
+	var (
+		interval = 20 * time.Millisecond
+		queue    = NewQueue(slog.Default(), interval, nil)
+		sender   = &mockSender{}
+		started  = time.Now()
+		wg       sync.WaitGroup
+	)
+
+	send := func(ctx context.Context, chatID int64) error {
+		return sender.SendMessage(ctx, chatID, "hola")
+	}
+
+	record := func(int64, store.DeliveryStatus) error {
+		return nil
+	}
+
+	// Concurrent deliveries wait for each other's messages
+	for _, chatIDs := range [][]int64{{1, 2, 3}, {4, 5, 6}} {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			assert.NoError(t, queue.Deliver(context.Background(), chatIDs, send, record))
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Len(t, sender.sentTo(), 6)
+	assert.GreaterOrEqual(t, time.Since(started), 5*interval)
+}
+
+func TestQueue_RecordError(t *testing.T) {
+	t.Parallel()
+
+	var (
+		queue     = NewQueue(slog.Default(), time.Millisecond, nil)
+		sender    = &mockSender{}
+		errRecord = errors.New("unable to record")
+		recorded  []int64
+	)
+
+	err := queue.Deliver(
+		context.Background(),
+		[]int64{1, 2},
+		func(ctx context.Context, chatID int64) error {
+			return sender.SendMessage(ctx, chatID, "hola")
+		},
+		func(chatID int64, status store.DeliveryStatus) error {
+			recorded = append(recorded, chatID)
+			require.Equal(t, store.DeliverySent, status)
+
+			return errRecord
+		},
+	)
+
+	// The delivery stops at the first record error
+	assert.ErrorIs(t, err, errRecord)
+	assert.Equal(t, []int64{1}, recorded)
+	assert.Equal(t, []int64{1}, sender.sentTo())
+}
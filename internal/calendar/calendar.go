@@ -0,0 +1,127 @@
+package calendar
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pelletier/go-toml"
+
+	"github.com/sig-0/chigui-cifras/internal/clock"
+)
+
+// dateLayout is the format of the holiday dates
+const dateLayout = "2006-01-02"
+
+// Holiday is a day the banks are closed, besides weekends
+type Holiday struct {
+	// Date is the day of the holiday, as YYYY-MM-DD
+	Date string `toml:"date"`
+	Name string `toml:"name"`
+}
+
+// holidaysFile is the holidays file content
+type holidaysFile struct {
+	Holidays []Holiday `toml:"holidays"`
+}
+
+// Calendar is the Venezuelan banking calendar: weekdays are business days,
+// except for the holidays. Official rates published on a day apply
+// to the next business day, and carry forward over the days in between
+type Calendar struct {
+	location *time.Location
+	holidays map[string]string
+}
+
+// New creates a banking calendar with the given holidays
+func New(holidays []Holiday) (*Calendar, error) {
+	c := &Calendar{
+		location: clock.Caracas,
+		holidays: make(map[string]string, len(holidays)),
+	}
+
+	for _, holiday := range holidays {
+		if _, err := time.ParseInLocation(dateLayout, holiday.Date, c.location); err != nil {
+			return nil, fmt.Errorf("invalid holiday date %q: %w", holiday.Date, err)
+		}
+
+		c.holidays[holiday.Date] = holiday.Name
+	}
+
+	return c, nil
+}
+
+// Load creates a banking calendar with the holidays in the TOML file at the given path
+func Load(path string) (*Calendar, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file holidaysFile
+
+	if err := toml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("unable to parse holidays: %w", err)
+	}
+
+	return New(file.Holidays)
+}
+
+// Holiday returns the name of the holiday on the day, if it's one
+func (c *Calendar) Holiday(day time.Time) (string, bool) {
+	name, ok := c.holidays[day.In(c.location).Format(dateLayout)]
+
+	return name, ok
+}
+
+// IsBusinessDay checks if the banks are open on the day
+func (c *Calendar) IsBusinessDay(day time.Time) bool {
+	switch day.In(c.location).Weekday() {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+
+	_, holiday := c.Holiday(day)
+
+	return !holiday
+}
+
+// NextBusinessDay returns the start of the first business day after the day
+func (c *Calendar) NextBusinessDay(day time.Time) time.Time {
+	next := c.startOfDay(day).AddDate(0, 0, 1)
+
+	for !c.IsBusinessDay(next) {
+		next = next.AddDate(0, 0, 1)
+	}
+
+	return next
+}
+
+// ValueDate returns the start of the business day an official rate
+// published at the given time applies to
+func (c *Calendar) ValueDate(publishedAt time.Time) time.Time {
+	return c.NextBusinessDay(publishedAt)
+}
+
+// CurrentValueDate returns the start of the business day the rates in effect apply to:
+// today, or the next business day on weekends and holidays
+func (c *Calendar) CurrentValueDate(now time.Time) time.Time {
+	if c.IsBusinessDay(now) {
+		return c.startOfDay(now)
+	}
+
+	return c.NextBusinessDay(now)
+}
+
+// IsOutdated checks if an official rate published at the given time
+// no longer applies, because a newer one should already be in effect
+func (c *Calendar) IsOutdated(publishedAt, now time.Time) bool {
+	return c.ValueDate(publishedAt).Before(c.CurrentValueDate(now))
+}
+
+// startOfDay returns the start of the day, in the calendar time zone
+func (c *Calendar) startOfDay(day time.Time) time.Time {
+	year, month, date := day.In(c.location).Date()
+
+	return time.Date(year, month, date, 0, 0, 0, 0, c.location)
+}
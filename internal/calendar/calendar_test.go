@@ -0,0 +1,175 @@
+package calendar
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sig-0/chigui-cifras/internal/clock"
+)
+
+// day returns the given time on a day of January 2026, in Caracas.
+// January 12 2026 is a Monday
+func day(date, hour int) time.Time {
+	return time.Date(2026, time.January, date, hour, 0, 0, 0, clock.Caracas)
+}
+
+func newTestCalendar(t *testing.T) *Calendar {
+	t.Helper()
+
+	c, err := New([]Holiday{{Date: "2026-01-15", Name: "Día del Maestro"}})
+	require.NoError(t, err)
+
+	return c
+}
+
+func TestCalendar_IsBusinessDay(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCalendar(t)
+
+	assert.True(t, c.IsBusinessDay(day(12, 9)))
+	assert.False(t, c.IsBusinessDay(day(10, 9)), "saturday")
+	assert.False(t, c.IsBusinessDay(day(11, 9)), "sunday")
+	assert.False(t, c.IsBusinessDay(day(15, 9)), "holiday")
+
+	// Days are in Caracas time: Friday 23:00 there is already Saturday in UTC
+	assert.True(t, c.IsBusinessDay(time.Date(2026, time.January, 10, 3, 0, 0, 0, time.UTC)))
+
+	name, ok := c.Holiday(day(15, 0))
+	assert.True(t, ok)
+	assert.Equal(t, "Día del Maestro", name)
+}
+
+func TestCalendar_ValueDate(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCalendar(t)
+
+	testTable := []struct {
+		name        string
+		publishedAt time.Time
+		expected    time.Time
+	}{
+		{
+			name:        "weekday",
+			publishedAt: day(12, 16),
+			expected:    day(13, 0),
+		},
+		{
+			name:        "friday applies to monday",
+			publishedAt: day(9, 16),
+			expected:    day(12, 0),
+		},
+		{
+			name:        "weekend carries forward",
+			publishedAt: day(10, 10),
+			expected:    day(12, 0),
+		},
+		{
+			name:        "skips holidays",
+			publishedAt: day(14, 16),
+			expected:    day(16, 0),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expected, c.ValueDate(testCase.publishedAt))
+		})
+	}
+}
+
+func TestCalendar_IsOutdated(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCalendar(t)
+
+	testTable := []struct {
+		name        string
+		publishedAt time.Time
+		now         time.Time
+		outdated    bool
+	}{
+		{
+			name:        "published today",
+			publishedAt: day(13, 16),
+			now:         day(13, 17),
+		},
+		{
+			name:        "not published yet today",
+			publishedAt: day(12, 16),
+			now:         day(13, 10),
+		},
+		{
+			name:        "friday rate over the weekend",
+			publishedAt: day(9, 16),
+			now:         day(11, 10),
+		},
+		{
+			name:        "friday rate on monday",
+			publishedAt: day(9, 16),
+			now:         day(12, 18),
+		},
+		{
+			name:        "friday rate on tuesday",
+			publishedAt: day(9, 16),
+			now:         day(13, 10),
+			outdated:    true,
+		},
+		{
+			name:        "over a holiday",
+			publishedAt: day(14, 16),
+			now:         day(15, 10),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.outdated, c.IsOutdated(testCase.publishedAt, testCase.now))
+		})
+	}
+}
+
+func TestCalendar_Load(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid file", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "holidays.toml")
+
+		require.NoError(t, os.WriteFile(path, []byte(`
+[[holidays]]
+date = "2026-01-01"
+name = "Año Nuevo"
+`), 0o600))
+
+		c, err := Load(path)
+		require.NoError(t, err)
+
+		assert.False(t, c.IsBusinessDay(time.Date(2026, time.January, 1, 12, 0, 0, 0, clock.Caracas)))
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "holidays.toml")
+
+		require.NoError(t, os.WriteFile(path, []byte(`
+[[holidays]]
+date = "01/01/2026"
+`), 0o600))
+
+		_, err := Load(path)
+		assert.ErrorContains(t, err, `invalid holiday date "01/01/2026"`)
+	})
+}
@@ -0,0 +1,42 @@
+package clock
+
+import "time"
+
+// Clock tells the current time. It's injected wherever time is read,
+// so messages, schedules and alerts can be tested deterministically
+type Clock interface {
+	Now() time.Time
+}
+
+// System is the system clock
+var System Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Func adapts a function to a Clock
+type Func func() time.Time
+
+// Now returns the time given by the function
+func (f Func) Now() time.Time {
+	return f()
+}
+
+// Fixed returns a clock stopped at the given time
+func Fixed(now time.Time) Clock {
+	return Func(func() time.Time {
+		return now
+	})
+}
+
+// Or returns the clock, or the system clock if nil
+func Or(c Clock) Clock {
+	if c == nil {
+		return System
+	}
+
+	return c
+}
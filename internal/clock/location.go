@@ -0,0 +1,23 @@
+package clock
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata" // Venezuela time doesn't depend on the host's tz database
+)
+
+// CaracasTimeZone is the IANA name of the Venezuelan time zone
+const CaracasTimeZone = "America/Caracas"
+
+// Caracas is the Venezuelan time zone, the banking days and rate publications follow
+var Caracas = mustLoadLocation(CaracasTimeZone)
+
+// mustLoadLocation loads the IANA time zone, bundled with the binary
+func mustLoadLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		panic(fmt.Sprintf("unable to load time zone %q: %v", name, err))
+	}
+
+	return location
+}
@@ -3,35 +3,113 @@ package config
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"net"
 	"net/url"
 	"os"
+	"regexp"
 	"strings"
 	"time"
+	_ "time/tzdata" // the time zone is validated without depending on the host's tz database
 
 	"github.com/pelletier/go-toml"
+
+	"github.com/sig-0/chigui-cifras/internal/clock"
 )
 
 const (
 	DefaultListenAddress = "0.0.0.0:8080"
 
+	// DefaultParseMode sends replies as plain text, like before rich formatting.
+	// HTML or MarkdownV2 are opted in to
+	DefaultParseMode = ""
+	DefaultTimeZone  = clock.CaracasTimeZone
+
 	DefaultFXRatesURL = "https://api.ojoporciento.com"
 	DefaultFXTimeout  = 10 * time.Second
+	DefaultFXCacheTTL = 10 * time.Minute
+
+	DefaultInlineHelpCacheTime        = 10 * time.Minute
+	DefaultInlineSuggestionsCacheTime = time.Minute
+	DefaultInlineRatesCacheTime       = 5 * time.Second
+	DefaultInlineListingCacheTime     = 30 * time.Second
+	DefaultInlineErrorsCacheTime      = 5 * time.Second
+
+	// DefaultBroadcastInterval keeps broadcasts well under
+	// Telegram's limit of 30 messages per second
+	DefaultBroadcastInterval = 50 * time.Millisecond
+
+	DefaultStatsRetentionDays = 90
+	DefaultStatsFlushInterval = time.Minute
+
+	DefaultLiveRatesInterval = time.Minute
+
+	DefaultWatcherInterval = 5 * time.Minute
+
+	DefaultFreshnessThreshold     = 2 * time.Hour
+	DefaultFreshnessCheckInterval = time.Minute
+
+	DefaultChannelInterval = 30 * time.Minute
+	DefaultChannelTarget   = "VES"
+	DefaultChannelLanguage = "es"
+
+	// minChannelInterval keeps channel posts from hammering the upstream API
+	minChannelInterval = time.Minute
 )
 
 var (
-	errMissingTelegramToken      = errors.New("missing telegram token")
-	errMissingListenAddr         = errors.New("missing listen address")
-	errMissingWebhookSecretToken = errors.New("missing webhook secret token")
-	errMissingFXRatesBaseURL     = errors.New("missing fxrates base url")
-	errFXRatesTimeoutNonPositive = errors.New("fxrates timeout must be positive")
+	errMissingTelegramToken         = errors.New("missing telegram token")
+	errMissingListenAddr            = errors.New("missing listen address")
+	errMissingWebhookSecretToken    = errors.New("missing webhook secret token")
+	errMissingFXRatesBaseURL        = errors.New("missing fxrates base url")
+	errFXRatesTimeoutNonPositive    = errors.New("fxrates timeout must be positive")
+	errFXRatesCacheTTLNonPositive   = errors.New("fxrates cache ttl must be positive")
+	errBroadcastIntervalNegative    = errors.New("broadcast interval must not be negative")
+	errStatsRetentionNonPositive    = errors.New("stats retention days must be positive")
+	errStatsFlushNonPositive        = errors.New("stats flush interval must be positive")
+	errLiveRatesIntervalNonPositive = errors.New("live rates interval must be positive")
+	errWatcherIntervalNonPositive   = errors.New("watcher interval must be positive")
+	errFreshnessThresholdNegative   = errors.New("freshness threshold must not be negative")
+	errFreshnessIntervalNonPositive = errors.New("freshness check interval must be positive")
+	errMissingChannelChatID         = errors.New("missing channel chat id")
+	errMissingChannelBase           = errors.New("missing channel base currency")
+	errMissingShortcutBase          = errors.New("missing shortcut base currency")
+	errMissingPreferenceCurrency    = errors.New("missing preference currency")
 )
 
+// shortcutCommandPattern matches the commands Telegram accepts
+var shortcutCommandPattern = regexp.MustCompile(`^/[a-z0-9_]{1,32}$`)
+
 // Config holds all application configuration
 type Config struct {
 	ListenAddress string         `toml:"listen_address"`
 	Telegram      TelegramConfig `toml:"telegram"`
 	FXRates       FXRatesConfig  `toml:"fxrates"`
+	Store         StoreConfig    `toml:"store"`
+	Admin         AdminConfig    `toml:"admin"`
+	Stats         StatsConfig    `toml:"stats"`
+
+	// LiveRates holds the settings of the pinned live rates summaries (/fijar)
+	LiveRates LiveRatesConfig `toml:"live_rates"`
+
+	// Calendar holds the banking calendar settings
+	Calendar CalendarConfig `toml:"calendar"`
+
+	// Watcher holds the settings of the new rate announcements (/avisos)
+	Watcher WatcherConfig `toml:"watcher"`
+
+	// Freshness holds the thresholds after which rates are considered stale
+	Freshness FreshnessConfig `toml:"freshness"`
+
+	// Preferences hold the source each currency is fetched from, and which of its rates is preferred
+	Preferences PreferencesConfig `toml:"preferences"`
+
+	// Channels are the rates posted to channels on a schedule
+	Channels []ChannelConfig `toml:"channels"`
+
+	// Shortcuts are the commands replying with the rate of a fixed pair, like /dolar.
+	// If unset, the built-in ones are used
+	Shortcuts []ShortcutConfig `toml:"shortcuts"`
 }
 
 // TelegramConfig holds Telegram bot settings
@@ -39,21 +117,221 @@ type TelegramConfig struct {
 	Token              string `toml:"token"`
 	WebhookURL         string `toml:"webhook_url"`
 	WebhookSecretToken string `toml:"webhook_secret_token"`
+
+	// ParseMode is the reply formatting: "HTML", "MarkdownV2", or empty for plain text
+	ParseMode string `toml:"parse_mode"`
+
+	// TimeZone is the IANA time zone times are displayed in, like "America/Caracas".
+	// Each chat can override it with /zona
+	TimeZone string `toml:"time_zone"`
+
+	// Inline is the Telegram-side caching policy of inline answers
+	Inline InlineConfig `toml:"inline"`
+}
+
+// InlineConfig holds the caching policy of every kind of inline answer
+type InlineConfig struct {
+	// Help is the usage help, shown when nothing matches the query
+	Help InlineCacheConfig `toml:"help"`
+
+	// Suggestions are the popular and partially typed pairs
+	Suggestions InlineCacheConfig `toml:"suggestions"`
+
+	// Rates are the live rates for a pair
+	Rates InlineCacheConfig `toml:"rates"`
+
+	// Listing is the paginated list of every rate for a base ("USD *")
+	Listing InlineCacheConfig `toml:"listing"`
+
+	// Errors are the error and "no results" answers
+	Errors InlineCacheConfig `toml:"errors"`
+}
+
+// InlineCacheConfig is how Telegram caches an inline answer
+type InlineCacheConfig struct {
+	// CacheTime is how long Telegram may serve the cached answer.
+	// It can't exceed the strictest freshness threshold, since a cached answer
+	// never shows the staleness warning of rates that went stale meanwhile
+	CacheTime time.Duration `toml:"cache_time"`
+
+	// Personal restricts the cached answer to the user who sent the query.
+	// Answers are rendered in the user's language and number format,
+	// so only share them if every user gets the same ones
+	Personal bool `toml:"personal"`
 }
 
 // FXRatesConfig holds fxrates API client settings
 type FXRatesConfig struct {
 	BaseURL string        `toml:"base_url"`
 	Timeout time.Duration `toml:"timeout"`
+
+	// CacheTTL is how long the bot caches fxrates reference data,
+	// like the supported currency list
+	CacheTTL time.Duration `toml:"cache_ttl"`
+}
+
+// StoreConfig holds the bot state store settings
+type StoreConfig struct {
+	// Path is the JSON file used to persist chat state.
+	// If empty, the state is kept in memory and lost on restart
+	Path string `toml:"path"`
+}
+
+// AdminConfig holds the bot operator settings
+type AdminConfig struct {
+	// UserIDs are the Telegram users allowed to run admin commands
+	UserIDs []int64 `toml:"user_ids"`
+	// BroadcastInterval is the wait between the messages of broadcasts and new rate announcements
+	// BroadcastInterval is the wait between the messages of a broadcast
+	BroadcastInterval time.Duration `toml:"broadcast_interval"`
+}
+
+// StatsConfig holds the daily usage statistics settings
+type StatsConfig struct {
+	// RetentionDays is how many days of usage statistics are kept
+	RetentionDays int `toml:"retention_days"`
+
+	// FlushInterval is how often the usage statistics are written to the store
+	FlushInterval time.Duration `toml:"flush_interval"`
+}
+
+// LiveRatesConfig holds the pinned live rates summary settings
+type LiveRatesConfig struct {
+	// Interval is how often the rates are checked for changes.
+	// The summaries are only edited when any of them changes
+	Interval time.Duration `toml:"interval"`
+}
+
+// CalendarConfig holds the Venezuelan banking calendar settings
+type CalendarConfig struct {
+	// HolidaysFile is the TOML file listing the bank holidays.
+	// If empty, only weekends are non-business days
+	HolidaysFile string `toml:"holidays_file"`
+}
+
+// WatcherConfig holds the new rate announcement settings
+type WatcherConfig struct {
+	// Pairs are the "BASE/TARGET" pairs announced whenever a new rate is published.
+	// If empty, no rates are announced
+	Pairs []string `toml:"pairs"`
+
+	// Interval is how often the pairs are checked for new rates
+	Interval time.Duration `toml:"interval"`
+}
+
+// FreshnessConfig holds the rate staleness thresholds, and the pairs tracked for readiness
+type FreshnessConfig struct {
+	// Default is the age after which the rates of a source without its own threshold are stale.
+	// Zero disables the staleness warnings
+	Default time.Duration `toml:"default"`
+
+	// Sources are the thresholds by source, like BCV = "26h"
+	Sources map[string]time.Duration `toml:"sources"`
+
+	// Pairs are the "BASE/TARGET" pairs whose freshness is exposed through
+	// the metrics endpoint, and degrades the readiness check when stale
+	Pairs []string `toml:"pairs"`
+
+	// CheckInterval is how often the tracked pairs are fetched
+	CheckInterval time.Duration `toml:"check_interval"`
+}
+
+// PreferencesConfig holds the rate preferences of each base currency
+type PreferencesConfig struct {
+	// Default is the rule of the currencies without their own
+	Default PreferenceRuleConfig `toml:"default"`
+
+	// Currencies are the rules by base currency, like USD.
+	// The built-in ones are kept unless overridden, even by an empty rule
+	Currencies map[string]PreferenceRuleConfig `toml:"currencies"`
+}
+
+// PreferenceRuleConfig holds how the rates of a currency are fetched, and which one is preferred
+type PreferenceRuleConfig struct {
+	// Source is the only source the rates are fetched from, like "BCV".
+	// If empty, they're fetched from every source
+	Source string `toml:"source"`
+
+	// Prefer are the "SOURCE:TYPE" rates tried in order, like "BCV:MID", where "*" matches any.
+	// If none is published, the first rate is preferred
+	Prefer []string `toml:"prefer"`
+}
+
+// ChannelConfig holds a rate posted to a channel on a schedule.
+// The bot edits the same message on every update, instead of posting new ones
+type ChannelConfig struct {
+	// ChatID is the channel ID, like -1001234567890
+	ChatID int64 `toml:"chat_id"`
+
+	// Base and Target are the posted pair. Target defaults to VES
+	Base   string `toml:"base"`
+	Target string `toml:"target"`
+
+	// Language is the language of the post: "es" (default), "en" or "pt"
+	Language string `toml:"language"`
+
+	// Interval is the wait between updates of the post. Defaults to 30m
+	Interval time.Duration `toml:"interval"`
+
+	// Pin pins the post when it's first posted
+	Pin bool `toml:"pin"`
+}
+
+// ShortcutConfig holds a command replying with the rate of a fixed pair
+type ShortcutConfig struct {
+	// Command is the shortcut command, like "/peso"
+	Command string `toml:"command"`
+
+	// Base and Target are the pair. Target defaults to VES
+	Base   string `toml:"base"`
+	Target string `toml:"target"`
+
+	// Source is the source the rate is fetched from, like "BCV".
+	// If empty, the rates of every source are shown
+	Source string `toml:"source"`
 }
 
 // DefaultConfig returns a Config with default values
 func DefaultConfig() *Config {
 	return &Config{
 		ListenAddress: DefaultListenAddress,
+		Telegram: TelegramConfig{
+			ParseMode: DefaultParseMode,
+			TimeZone:  DefaultTimeZone,
+			Inline: InlineConfig{
+				Help:        InlineCacheConfig{CacheTime: DefaultInlineHelpCacheTime, Personal: true},
+				Suggestions: InlineCacheConfig{CacheTime: DefaultInlineSuggestionsCacheTime, Personal: true},
+				Rates:       InlineCacheConfig{CacheTime: DefaultInlineRatesCacheTime, Personal: true},
+				Listing:     InlineCacheConfig{CacheTime: DefaultInlineListingCacheTime, Personal: true},
+				Errors:      InlineCacheConfig{CacheTime: DefaultInlineErrorsCacheTime, Personal: true},
+			},
+		},
 		FXRates: FXRatesConfig{
-			BaseURL: DefaultFXRatesURL,
-			Timeout: DefaultFXTimeout,
+			BaseURL:  DefaultFXRatesURL,
+			Timeout:  DefaultFXTimeout,
+			CacheTTL: DefaultFXCacheTTL,
+		},
+		Admin: AdminConfig{
+			BroadcastInterval: DefaultBroadcastInterval,
+		},
+		Stats: StatsConfig{
+			RetentionDays: DefaultStatsRetentionDays,
+			FlushInterval: DefaultStatsFlushInterval,
+		},
+		LiveRates: LiveRatesConfig{
+			Interval: DefaultLiveRatesInterval,
+		},
+		Watcher: WatcherConfig{
+			Pairs:    DefaultWatcherPairs(),
+			Interval: DefaultWatcherInterval,
+		},
+		Freshness: FreshnessConfig{
+			Default:       DefaultFreshnessThreshold,
+			Pairs:         DefaultFreshnessPairs(),
+			CheckInterval: DefaultFreshnessCheckInterval,
+		},
+		Preferences: PreferencesConfig{
+			Currencies: DefaultPreferenceCurrencies(),
 		},
 	}
 }
@@ -89,6 +367,64 @@ func ValidateConfig(config *Config) error {
 		return errFXRatesTimeoutNonPositive
 	}
 
+	if config.FXRates.CacheTTL <= 0 {
+		return errFXRatesCacheTTLNonPositive
+	}
+
+	if config.Admin.BroadcastInterval < 0 {
+		return errBroadcastIntervalNegative
+	}
+
+	if config.Stats.RetentionDays <= 0 {
+		return errStatsRetentionNonPositive
+	}
+
+	if config.Stats.FlushInterval <= 0 {
+		return errStatsFlushNonPositive
+	}
+
+	if config.LiveRates.Interval <= 0 {
+		return errLiveRatesIntervalNonPositive
+	}
+
+	if err := validateWatcherConfig(config.Watcher); err != nil {
+		return err
+	}
+
+	if err := validateFreshnessConfig(config.Freshness); err != nil {
+		return err
+	}
+
+	if err := validatePreferencesConfig(config.Preferences); err != nil {
+		return err
+	}
+
+	if err := validateInlineConfig(config.Telegram.Inline, config.Freshness); err != nil {
+		return err
+	}
+
+	for _, channel := range config.Channels {
+		if err := validateChannelConfig(channel); err != nil {
+			return err
+		}
+	}
+
+	if err := validateShortcuts(config.Shortcuts); err != nil {
+		return err
+	}
+
+	switch config.Telegram.ParseMode {
+	case "", "HTML", "MarkdownV2":
+	default:
+		return fmt.Errorf("invalid telegram parse mode: %q", config.Telegram.ParseMode)
+	}
+
+	// An empty time zone is UTC, and "Local" depends on the host
+	if _, err := time.LoadLocation(config.Telegram.TimeZone); err != nil ||
+		config.Telegram.TimeZone == "" || config.Telegram.TimeZone == "Local" {
+		return fmt.Errorf("invalid telegram time zone: %q", config.Telegram.TimeZone)
+	}
+
 	if strings.TrimSpace(config.Telegram.WebhookURL) == "" {
 		return nil
 	}
@@ -109,6 +445,231 @@ func ValidateConfig(config *Config) error {
 	return nil
 }
 
+// validateInlineConfig validates the inline caching policies,
+// which must fit within the freshness thresholds
+func validateInlineConfig(inline InlineConfig, freshness FreshnessConfig) error {
+	threshold := strictestThreshold(freshness)
+
+	policies := []struct {
+		name   string
+		policy InlineCacheConfig
+	}{
+		{name: "help", policy: inline.Help},
+		{name: "suggestions", policy: inline.Suggestions},
+		{name: "rates", policy: inline.Rates},
+		{name: "listing", policy: inline.Listing},
+		{name: "errors", policy: inline.Errors},
+	}
+
+	for _, p := range policies {
+		// Telegram caches for whole seconds, and treats 0 as its own default
+		if p.policy.CacheTime < time.Second {
+			return fmt.Errorf("inline %s cache time must be at least 1s: %s", p.name, p.policy.CacheTime)
+		}
+
+		if threshold > 0 && p.policy.CacheTime > threshold {
+			return fmt.Errorf(
+				"inline %s cache time (%s) exceeds the freshness threshold (%s)",
+				p.name,
+				p.policy.CacheTime,
+				threshold,
+			)
+		}
+	}
+
+	return nil
+}
+
+// strictestThreshold returns the shortest enabled freshness threshold,
+// or 0 if the staleness warnings are disabled for every source
+func strictestThreshold(freshness FreshnessConfig) time.Duration {
+	strictest := freshness.Default
+
+	for _, threshold := range freshness.Sources {
+		if threshold > 0 && (strictest == 0 || threshold < strictest) {
+			strictest = threshold
+		}
+	}
+
+	return strictest
+}
+
+// validateWatcherConfig validates the new rate announcements
+func validateWatcherConfig(watcher WatcherConfig) error {
+	if watcher.Interval <= 0 {
+		return errWatcherIntervalNonPositive
+	}
+
+	for _, pair := range watcher.Pairs {
+		if _, _, ok := ParsePair(pair); !ok {
+			return fmt.Errorf("invalid watcher pair, expected BASE/TARGET: %q", pair)
+		}
+	}
+
+	return nil
+}
+
+// ParsePair splits a "BASE/TARGET" pair into its upper case currencies
+func ParsePair(pair string) (string, string, bool) {
+	base, target, ok := strings.Cut(strings.ToUpper(strings.TrimSpace(pair)), "/")
+	if !ok || base == "" || target == "" || strings.Contains(target, "/") {
+		return "", "", false
+	}
+
+	return base, target, true
+}
+
+// DefaultWatcherPairs returns the pairs announced by default, the BCV official rates
+func DefaultWatcherPairs() []string {
+	return []string{"USD/VES", "EUR/VES"}
+}
+
+// validateFreshnessConfig validates the staleness thresholds and the tracked pairs
+func validateFreshnessConfig(freshness FreshnessConfig) error {
+	if freshness.Default < 0 {
+		return errFreshnessThresholdNegative
+	}
+
+	for source, threshold := range freshness.Sources {
+		if threshold < 0 {
+			return fmt.Errorf("%w: %s", errFreshnessThresholdNegative, source)
+		}
+	}
+
+	if freshness.CheckInterval <= 0 {
+		return errFreshnessIntervalNonPositive
+	}
+
+	for _, pair := range freshness.Pairs {
+		if _, _, ok := ParsePair(pair); !ok {
+			return fmt.Errorf("invalid freshness pair, expected BASE/TARGET: %q", pair)
+		}
+	}
+
+	return nil
+}
+
+// DefaultFreshnessPairs returns the pairs tracked for readiness by default
+func DefaultFreshnessPairs() []string {
+	return []string{"USD/VES", "EUR/VES", "USDT/VES"}
+}
+
+// validatePreferencesConfig validates the preferred rates of every rule
+func validatePreferencesConfig(preferences PreferencesConfig) error {
+	if err := validatePreferenceRule("default", preferences.Default); err != nil {
+		return err
+	}
+
+	for currency, rule := range preferences.Currencies {
+		if strings.TrimSpace(currency) == "" {
+			return errMissingPreferenceCurrency
+		}
+
+		if err := validatePreferenceRule(currency, rule); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validatePreferenceRule validates the preferred rates of a rule
+func validatePreferenceRule(name string, rule PreferenceRuleConfig) error {
+	for _, preference := range rule.Prefer {
+		if _, _, ok := ParsePreference(preference); !ok {
+			return fmt.Errorf("invalid %s preference, expected SOURCE:TYPE: %q", name, preference)
+		}
+	}
+
+	return nil
+}
+
+// ParsePreference parses a "SOURCE:TYPE" preferred rate, like "BCV:MID", into its source and rate type.
+// A "*" source or rate type matches any, and is returned empty
+func ParsePreference(preference string) (string, string, bool) {
+	source, rateType, ok := strings.Cut(strings.ToUpper(strings.TrimSpace(preference)), ":")
+	if !ok || source == "" || rateType == "" || strings.Contains(rateType, ":") {
+		return "", "", false
+	}
+
+	if source == "*" {
+		source = ""
+	}
+
+	if rateType == "*" {
+		rateType = ""
+	}
+
+	return source, rateType, true
+}
+
+// DefaultPreferenceCurrencies returns the built-in rules by base currency:
+// the official fiat currencies are fetched from BCV, preferring its MID rate, then any MID rate
+func DefaultPreferenceCurrencies() map[string]PreferenceRuleConfig {
+	currencies := make(map[string]PreferenceRuleConfig)
+
+	for _, currency := range []string{"USD", "EUR", "RUB", "TRY", "CNY"} {
+		currencies[currency] = PreferenceRuleConfig{
+			Source: "BCV",
+			Prefer: []string{"BCV:MID", "*:MID"},
+		}
+	}
+
+	return currencies
+}
+
+// validateChannelConfig validates a scheduled channel post
+func validateChannelConfig(channel ChannelConfig) error {
+	if channel.ChatID == 0 {
+		return errMissingChannelChatID
+	}
+
+	if strings.TrimSpace(channel.Base) == "" {
+		return errMissingChannelBase
+	}
+
+	// Zero uses the default interval
+	if channel.Interval != 0 && channel.Interval < minChannelInterval {
+		return fmt.Errorf(
+			"channel %d interval must be at least %s: %s",
+			channel.ChatID,
+			minChannelInterval,
+			channel.Interval,
+		)
+	}
+
+	switch channel.Language {
+	case "", "es", "en", "pt":
+	default:
+		return fmt.Errorf("invalid channel %d language: %q", channel.ChatID, channel.Language)
+	}
+
+	return nil
+}
+
+// validateShortcuts validates the shortcut commands, which must be unique
+func validateShortcuts(shortcuts []ShortcutConfig) error {
+	commands := make(map[string]struct{}, len(shortcuts))
+
+	for _, shortcut := range shortcuts {
+		if !shortcutCommandPattern.MatchString(shortcut.Command) {
+			return fmt.Errorf("invalid shortcut command, expected like /peso: %q", shortcut.Command)
+		}
+
+		if _, ok := commands[shortcut.Command]; ok {
+			return fmt.Errorf("duplicate shortcut command: %q", shortcut.Command)
+		}
+
+		commands[shortcut.Command] = struct{}{}
+
+		if strings.TrimSpace(shortcut.Base) == "" {
+			return fmt.Errorf("%w: %s", errMissingShortcutBase, shortcut.Command)
+		}
+	}
+
+	return nil
+}
+
 // Read reads the configuration from the given path
 func Read(path string) (*Config, error) {
 	// Read the config file
@@ -124,5 +685,25 @@ func Read(path string) (*Config, error) {
 		return nil, err
 	}
 
+	// Configured currencies replace the built-in rules map, instead of adding to it
+	cfg.Preferences.Currencies = withDefaultPreferences(cfg.Preferences.Currencies)
+
 	return cfg, nil
 }
+
+// withDefaultPreferences adds the built-in rules of the currencies without their own
+func withDefaultPreferences(currencies map[string]PreferenceRuleConfig) map[string]PreferenceRuleConfig {
+	merged := DefaultPreferenceCurrencies()
+
+	for currency := range merged {
+		for configured := range currencies {
+			if strings.EqualFold(strings.TrimSpace(configured), currency) {
+				delete(merged, currency)
+			}
+		}
+	}
+
+	maps.Copy(merged, currencies)
+
+	return merged
+}
@@ -112,6 +112,221 @@ func TestConfig_ValidateConfig(t *testing.T) {
 			},
 			err: errFXRatesTimeoutNonPositive,
 		},
+		{
+			name: "fxrates cache ttl non positive",
+			mutate: func(cfg *Config) {
+				cfg.FXRates.CacheTTL = 0
+			},
+			err: errFXRatesCacheTTLNonPositive,
+		},
+		{
+			name: "negative broadcast interval",
+			mutate: func(cfg *Config) {
+				cfg.Admin.BroadcastInterval = -time.Second
+			},
+			err: errBroadcastIntervalNegative,
+		},
+		{
+			name: "stats retention non positive",
+			mutate: func(cfg *Config) {
+				cfg.Stats.RetentionDays = 0
+			},
+			err: errStatsRetentionNonPositive,
+		},
+		{
+			name: "stats flush interval non positive",
+			mutate: func(cfg *Config) {
+				cfg.Stats.FlushInterval = 0
+			},
+			err: errStatsFlushNonPositive,
+		},
+		{
+			name: "live rates interval non positive",
+			mutate: func(cfg *Config) {
+				cfg.LiveRates.Interval = 0
+			},
+			err: errLiveRatesIntervalNonPositive,
+		},
+		{
+			name: "watcher interval non positive",
+			mutate: func(cfg *Config) {
+				cfg.Watcher.Interval = 0
+			},
+			err: errWatcherIntervalNonPositive,
+		},
+		{
+			name: "watcher invalid pair",
+			mutate: func(cfg *Config) {
+				cfg.Watcher.Pairs = []string{"USD/VES", "USD"}
+			},
+			errContains: `invalid watcher pair, expected BASE/TARGET: "USD"`,
+		},
+		{
+			name: "freshness threshold negative",
+			mutate: func(cfg *Config) {
+				cfg.Freshness.Sources = map[string]time.Duration{"BCV": -time.Hour}
+			},
+			err: errFreshnessThresholdNegative,
+		},
+		{
+			name: "freshness check interval non positive",
+			mutate: func(cfg *Config) {
+				cfg.Freshness.CheckInterval = 0
+			},
+			err: errFreshnessIntervalNonPositive,
+		},
+		{
+			name: "channel without chat id",
+			mutate: func(cfg *Config) {
+				cfg.Channels = []ChannelConfig{{Base: "USD"}}
+			},
+			err: errMissingChannelChatID,
+		},
+		{
+			name: "channel without base",
+			mutate: func(cfg *Config) {
+				cfg.Channels = []ChannelConfig{{ChatID: -100123}}
+			},
+			err: errMissingChannelBase,
+		},
+		{
+			name: "channel interval too short",
+			mutate: func(cfg *Config) {
+				cfg.Channels = []ChannelConfig{{ChatID: -100123, Base: "USD", Interval: 30 * time.Second}}
+			},
+			errContains: "channel -100123 interval must be at least 1m0s",
+		},
+		{
+			name: "channel invalid language",
+			mutate: func(cfg *Config) {
+				cfg.Channels = []ChannelConfig{{ChatID: -100123, Base: "USD", Language: "fr"}}
+			},
+			errContains: "invalid channel -100123 language",
+		},
+		{
+			name: "shortcut invalid command",
+			mutate: func(cfg *Config) {
+				cfg.Shortcuts = []ShortcutConfig{{Command: "peso", Base: "COP"}}
+			},
+			errContains: `invalid shortcut command, expected like /peso: "peso"`,
+		},
+		{
+			name: "shortcut upper case command",
+			mutate: func(cfg *Config) {
+				cfg.Shortcuts = []ShortcutConfig{{Command: "/Peso", Base: "COP"}}
+			},
+			errContains: `invalid shortcut command, expected like /peso: "/Peso"`,
+		},
+		{
+			name: "shortcut duplicate command",
+			mutate: func(cfg *Config) {
+				cfg.Shortcuts = []ShortcutConfig{{Command: "/peso", Base: "COP"}, {Command: "/peso", Base: "MXN"}}
+			},
+			errContains: `duplicate shortcut command: "/peso"`,
+		},
+		{
+			name: "shortcut without base",
+			mutate: func(cfg *Config) {
+				cfg.Shortcuts = []ShortcutConfig{{Command: "/peso"}}
+			},
+			err: errMissingShortcutBase,
+		},
+		{
+			name: "invalid currency preference",
+			mutate: func(cfg *Config) {
+				cfg.Preferences.Currencies["COP"] = PreferenceRuleConfig{Prefer: []string{"BCV"}}
+			},
+			errContains: `invalid COP preference, expected SOURCE:TYPE: "BCV"`,
+		},
+		{
+			name: "invalid default preference",
+			mutate: func(cfg *Config) {
+				cfg.Preferences.Default.Prefer = []string{"*:MID", ":SELL"}
+			},
+			errContains: `invalid default preference, expected SOURCE:TYPE: ":SELL"`,
+		},
+		{
+			name: "preference without currency",
+			mutate: func(cfg *Config) {
+				cfg.Preferences.Currencies[" "] = PreferenceRuleConfig{Source: "BCV"}
+			},
+			err: errMissingPreferenceCurrency,
+		},
+		{
+			name: "inline cache time below a second",
+			mutate: func(cfg *Config) {
+				cfg.Telegram.Inline.Rates.CacheTime = 0
+			},
+			errContains: "inline rates cache time must be at least 1s",
+		},
+		{
+			name: "inline cache time exceeds freshness threshold",
+			mutate: func(cfg *Config) {
+				cfg.Telegram.Inline.Help.CacheTime = 3 * time.Hour
+			},
+			errContains: "inline help cache time (3h0m0s) exceeds the freshness threshold (2h0m0s)",
+		},
+		{
+			name: "inline cache time exceeds source freshness threshold",
+			mutate: func(cfg *Config) {
+				cfg.Freshness.Sources = map[string]time.Duration{"BCV": 30 * time.Minute, "BINANCE": 0}
+				cfg.Telegram.Inline.Help.CacheTime = time.Hour
+			},
+			errContains: "inline help cache time (1h0m0s) exceeds the freshness threshold (30m0s)",
+		},
+		{
+			name: "inline cache time without freshness thresholds",
+			mutate: func(cfg *Config) {
+				cfg.FXRates.CacheTTL = time.Minute
+				cfg.Freshness.Default = 0
+				cfg.Telegram.Inline.Help.CacheTime = 24 * time.Hour
+			},
+		},
+		{
+			name: "shared inline answers",
+			mutate: func(cfg *Config) {
+				cfg.Telegram.Inline.Help.Personal = false
+			},
+		},
+		{
+			name: "invalid parse mode",
+			mutate: func(cfg *Config) {
+				cfg.Telegram.ParseMode = "Markdown"
+			},
+			errContains: "invalid telegram parse mode",
+		},
+		{
+			name: "invalid time zone",
+			mutate: func(cfg *Config) {
+				cfg.Telegram.TimeZone = "America/Atlantis"
+			},
+			errContains: "invalid telegram time zone",
+		},
+		{
+			name: "empty time zone",
+			mutate: func(cfg *Config) {
+				cfg.Telegram.TimeZone = ""
+			},
+			errContains: "invalid telegram time zone",
+		},
+		{
+			name: "madrid time zone",
+			mutate: func(cfg *Config) {
+				cfg.Telegram.TimeZone = "Europe/Madrid"
+			},
+		},
+		{
+			name: "html parse mode",
+			mutate: func(cfg *Config) {
+				cfg.Telegram.ParseMode = "HTML"
+			},
+		},
+		{
+			name: "markdown parse mode",
+			mutate: func(cfg *Config) {
+				cfg.Telegram.ParseMode = "MarkdownV2"
+			},
+		},
 		{
 			name: "valid configuration",
 		},
@@ -149,10 +364,70 @@ func TestConfig_Read(t *testing.T) {
 token = "token"
 webhook_url = "https://example.com/webhook"
 webhook_secret_token = "secret"
+parse_mode = "MarkdownV2"
+
+[telegram.inline.help]
+cache_time = "5m"
+personal = false
+
+[telegram.inline.rates]
+cache_time = "10s"
 
 [fxrates]
 base_url = "http://example.com"
 timeout = "12s"
+cache_ttl = "15m"
+
+[store]
+path = "/var/lib/chigui/store.json"
+
+[admin]
+user_ids = [42, 1337]
+broadcast_interval = "100ms"
+
+[stats]
+retention_days = 30
+
+[live_rates]
+interval = "5m"
+
+[calendar]
+holidays_file = "/etc/chigui/holidays.toml"
+
+[watcher]
+pairs = ["usd/ves"]
+
+[freshness]
+default = "3h"
+
+[freshness.sources]
+BCV = "26h"
+
+[preferences.default]
+prefer = ["BINANCE:SELL"]
+
+[preferences.currencies.USD]
+prefer = ["*:MID"]
+
+[preferences.currencies.COP]
+source = "BCV"
+prefer = ["BCV:*"]
+
+[[channels]]
+chat_id = -1001234567890
+base = "USD"
+interval = "15m"
+pin = true
+
+[[shortcuts]]
+command = "/peso"
+base = "COP"
+source = "BCV"
+
+[[shortcuts]]
+command = "/real"
+base = "BRL"
+target = "USD"
 `
 
 	path := filepath.Join(t.TempDir(), "config.toml")
@@ -167,7 +442,170 @@ timeout = "12s"
 	assert.Equal(t, "token", cfg.Telegram.Token)
 	assert.Equal(t, "https://example.com/webhook", cfg.Telegram.WebhookURL)
 	assert.Equal(t, "secret", cfg.Telegram.WebhookSecretToken)
+	assert.Equal(t, "MarkdownV2", cfg.Telegram.ParseMode)
 
 	assert.Equal(t, "http://example.com", cfg.FXRates.BaseURL)
 	assert.Equal(t, 12*time.Second, cfg.FXRates.Timeout)
+	assert.Equal(t, 15*time.Minute, cfg.FXRates.CacheTTL)
+
+	assert.Equal(t, InlineCacheConfig{CacheTime: 5 * time.Minute, Personal: false}, cfg.Telegram.Inline.Help)
+	assert.Equal(t, InlineCacheConfig{CacheTime: 10 * time.Second, Personal: true}, cfg.Telegram.Inline.Rates)
+	assert.Equal(t, DefaultConfig().Telegram.Inline.Listing, cfg.Telegram.Inline.Listing)
+
+	assert.Equal(t, "/var/lib/chigui/store.json", cfg.Store.Path)
+
+	assert.Equal(t, []int64{42, 1337}, cfg.Admin.UserIDs)
+	assert.Equal(t, 100*time.Millisecond, cfg.Admin.BroadcastInterval)
+
+	assert.Equal(t, 30, cfg.Stats.RetentionDays)
+	assert.Equal(t, DefaultStatsFlushInterval, cfg.Stats.FlushInterval)
+
+	assert.Equal(t, 5*time.Minute, cfg.LiveRates.Interval)
+
+	assert.Equal(t, "/etc/chigui/holidays.toml", cfg.Calendar.HolidaysFile)
+
+	assert.Equal(t, []string{"usd/ves"}, cfg.Watcher.Pairs)
+	assert.Equal(t, DefaultWatcherInterval, cfg.Watcher.Interval)
+
+	assert.Equal(t, 3*time.Hour, cfg.Freshness.Default)
+	assert.Equal(t, map[string]time.Duration{"BCV": 26 * time.Hour}, cfg.Freshness.Sources)
+	assert.Equal(t, DefaultFreshnessPairs(), cfg.Freshness.Pairs)
+
+	assert.Equal(t, PreferenceRuleConfig{Prefer: []string{"BINANCE:SELL"}}, cfg.Preferences.Default)
+	assert.Equal(t, PreferenceRuleConfig{Prefer: []string{"*:MID"}}, cfg.Preferences.Currencies["USD"])
+	assert.Equal(t, PreferenceRuleConfig{Source: "BCV", Prefer: []string{"BCV:*"}}, cfg.Preferences.Currencies["COP"])
+
+	// The built-in rules not overridden are kept
+	assert.Equal(t, DefaultPreferenceCurrencies()["EUR"], cfg.Preferences.Currencies["EUR"])
+
+	assert.Equal(t, []ChannelConfig{
+		{ChatID: -1001234567890, Base: "USD", Interval: 15 * time.Minute, Pin: true},
+	}, cfg.Channels)
+
+	assert.Equal(t, []ShortcutConfig{
+		{Command: "/peso", Base: "COP", Source: "BCV"},
+		{Command: "/real", Base: "BRL", Target: "USD"},
+	}, cfg.Shortcuts)
+}
+
+func TestRead_DefaultShortcuts(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+
+	require.NoError(t, os.WriteFile(path, []byte("listen_address = \"0.0.0.0:8080\"\n"), 0o600))
+
+	cfg, err := Read(path)
+	require.NoError(t, err)
+
+	// The bot falls back to the built-in shortcuts
+	assert.Nil(t, cfg.Shortcuts)
+
+	// Replies stay plain text unless rich formatting is opted in to
+	assert.Empty(t, cfg.Telegram.ParseMode)
+}
+
+func TestRead_Preferences(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name     string
+		body     string
+		expected map[string]PreferenceRuleConfig
+	}{
+		{
+			name:     "unset",
+			body:     "",
+			expected: DefaultPreferenceCurrencies(),
+		},
+		{
+			name: "overridden by an empty rule",
+			body: "[preferences.currencies.usd]\n",
+			expected: func() map[string]PreferenceRuleConfig {
+				currencies := DefaultPreferenceCurrencies()
+				delete(currencies, "USD")
+				currencies["usd"] = PreferenceRuleConfig{}
+
+				return currencies
+			}(),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "config.toml")
+
+			require.NoError(t, os.WriteFile(path, []byte(testCase.body), 0o600))
+
+			cfg, err := Read(path)
+			require.NoError(t, err)
+
+			assert.Equal(t, testCase.expected, cfg.Preferences.Currencies)
+		})
+	}
+}
+
+func TestParsePair(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		pair   string
+		base   string
+		target string
+		ok     bool
+	}{
+		{pair: "USD/VES", base: "USD", target: "VES", ok: true},
+		{pair: " eur/ves ", base: "EUR", target: "VES", ok: true},
+		{pair: "USD"},
+		{pair: "USD/"},
+		{pair: "/VES"},
+		{pair: "USD/VES/EUR"},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.pair, func(t *testing.T) {
+			t.Parallel()
+
+			base, target, ok := ParsePair(testCase.pair)
+
+			assert.Equal(t, testCase.ok, ok)
+			assert.Equal(t, testCase.base, base)
+			assert.Equal(t, testCase.target, target)
+		})
+	}
+}
+
+func TestParsePreference(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		preference string
+		source     string
+		rateType   string
+		ok         bool
+	}{
+		{preference: "BCV:MID", source: "BCV", rateType: "MID", ok: true},
+		{preference: " binance:sell ", source: "BINANCE", rateType: "SELL", ok: true},
+		{preference: "*:MID", source: "", rateType: "MID", ok: true},
+		{preference: "BCV:*", source: "BCV", rateType: "", ok: true},
+		{preference: "*:*", source: "", rateType: "", ok: true},
+		{preference: "BCV"},
+		{preference: "BCV:"},
+		{preference: ":MID"},
+		{preference: "BCV:MID:BUY"},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.preference, func(t *testing.T) {
+			t.Parallel()
+
+			source, rateType, ok := ParsePreference(testCase.preference)
+
+			assert.Equal(t, testCase.ok, ok)
+			assert.Equal(t, testCase.source, source)
+			assert.Equal(t, testCase.rateType, rateType)
+		})
+	}
 }
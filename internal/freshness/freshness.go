@@ -0,0 +1,265 @@
+package freshness
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sig-0/chigui-cifras/internal/clock"
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+	"github.com/sig-0/chigui-cifras/internal/metrics"
+)
+
+const (
+	ageMetric = "chigui_rate_age_seconds"
+	ageHelp   = "Age of the latest rate of each tracked pair and source, since it was fetched upstream."
+
+	staleMetric = "chigui_rate_stale"
+	staleHelp   = "Whether the latest rate of each tracked pair and source exceeds its freshness threshold."
+)
+
+// Policy holds the age after which the rates of each source are stale
+type Policy struct {
+	// Sources are the thresholds by source, like "BCV"
+	Sources map[string]time.Duration
+
+	// Default is the threshold of the sources without their own
+	Default time.Duration
+}
+
+// Threshold returns the age after which the rates of the source are stale
+func (p Policy) Threshold(source string) time.Duration {
+	if threshold, ok := p.Sources[strings.ToUpper(source)]; ok {
+		return threshold
+	}
+
+	return p.Default
+}
+
+// Stale returns the age of the rate, and whether it exceeds the threshold of its source.
+// A zero threshold never goes stale
+func (p Policy) Stale(rate fxrates.ExchangeRate, now time.Time) (time.Duration, bool) {
+	var (
+		age       = Age(rate, now)
+		threshold = p.Threshold(rate.Source.String())
+	)
+
+	return age, threshold > 0 && age > threshold
+}
+
+// Age returns how old the rate is: since it was fetched from its source,
+// or since it became effective, if the fetch time is unknown
+func Age(rate fxrates.ExchangeRate, now time.Time) time.Duration {
+	return max(now.Sub(updatedAt(rate)), 0)
+}
+
+// updatedAt returns when the rate was last fetched from its source, or when it became effective
+func updatedAt(rate fxrates.ExchangeRate) time.Time {
+	if rate.FetchedAt.IsZero() {
+		return rate.AsOf
+	}
+
+	return rate.FetchedAt
+}
+
+// Pair is a tracked currency pair
+type Pair struct {
+	Base   string
+	Target string
+}
+
+// String returns the pair as "BASE/TARGET"
+func (p Pair) String() string {
+	return p.Base + "/" + p.Target
+}
+
+// FetchFunc fetches the rates of every source for the pair
+type FetchFunc func(ctx context.Context, pair Pair) ([]fxrates.ExchangeRate, error)
+
+// Status is the freshness of the latest rate of a pair and source
+type Status struct {
+	Pair   string
+	Source string
+	Age    time.Duration
+	Stale  bool
+}
+
+// Monitor tracks the freshness of the latest rates of the tracked pairs,
+// for the metrics endpoint and the readiness check
+type Monitor struct {
+	latest map[string]map[string]fxrates.ExchangeRate // pair -> source -> rate
+	clock  clock.Clock
+	policy Policy
+	mux    sync.RWMutex
+}
+
+// NewMonitor creates a new freshness monitor, telling the age of the rates by the clock.
+// If the clock is nil, the system clock is used
+func NewMonitor(policy Policy, clk clock.Clock) *Monitor {
+	return &Monitor{
+		latest: make(map[string]map[string]fxrates.ExchangeRate),
+		clock:  clock.Or(clk),
+		policy: policy,
+	}
+}
+
+// Observe records the latest rates of the pair
+func (m *Monitor) Observe(pair Pair, rates []fxrates.ExchangeRate) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	sources, ok := m.latest[pair.String()]
+	if !ok {
+		sources = make(map[string]fxrates.ExchangeRate, len(rates))
+		m.latest[pair.String()] = sources
+	}
+
+	seen := make(map[string]fxrates.ExchangeRate, len(rates))
+
+	for _, rate := range rates {
+		source := rate.Source.String()
+
+		// Sources with several rate types keep the most recently updated one
+		if previous, ok := seen[source]; ok && !updatedAt(rate).After(updatedAt(previous)) {
+			continue
+		}
+
+		seen[source] = rate
+	}
+
+	for source, rate := range seen {
+		sources[source] = rate
+	}
+}
+
+// Statuses returns the freshness of every observed pair and source, sorted by pair and source
+func (m *Monitor) Statuses(now time.Time) []Status {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	statuses := make([]Status, 0, len(m.latest))
+
+	for pair, sources := range m.latest {
+		for source, rate := range sources {
+			age, stale := m.policy.Stale(rate, now)
+
+			statuses = append(statuses, Status{
+				Pair:   pair,
+				Source: source,
+				Age:    age,
+				Stale:  stale,
+			})
+		}
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Pair != statuses[j].Pair {
+			return statuses[i].Pair < statuses[j].Pair
+		}
+
+		return statuses[i].Source < statuses[j].Source
+	})
+
+	return statuses
+}
+
+// Stale returns the observed pairs and sources whose latest rate is stale
+func (m *Monitor) Stale(now time.Time) []Status {
+	var stale []Status
+
+	for _, status := range m.Statuses(now) {
+		if status.Stale {
+			stale = append(stale, status)
+		}
+	}
+
+	return stale
+}
+
+// Run fetches the pairs right away, and then every interval, until the context is done.
+// Failed fetches keep the previous rates, which eventually go stale
+func (m *Monitor) Run(ctx context.Context, interval time.Duration, pairs []Pair, fetch FetchFunc, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, pair := range pairs {
+			rates, err := fetch(ctx, pair)
+			if err != nil {
+				if ctx.Err() == nil {
+					onError(err)
+				}
+
+				continue
+			}
+
+			m.Observe(pair, rates)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// WriteMetrics writes the age and staleness of every observed pair and source
+func (m *Monitor) WriteMetrics(w io.Writer) error {
+	statuses := m.Statuses(m.clock.Now())
+
+	if err := metrics.WriteHeader(w, ageMetric, ageHelp, metrics.Gauge); err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		labels := map[string]string{"pair": status.Pair, "source": status.Source}
+
+		if err := metrics.WriteSample(w, ageMetric, labels, status.Age.Seconds()); err != nil {
+			return err
+		}
+	}
+
+	if err := metrics.WriteHeader(w, staleMetric, staleHelp, metrics.Gauge); err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		var (
+			labels = map[string]string{"pair": status.Pair, "source": status.Source}
+			value  = 0.0
+		)
+
+		if status.Stale {
+			value = 1
+		}
+
+		if err := metrics.WriteSample(w, staleMetric, labels, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ServeHTTP serves the readiness check, failing while any observed pair is stale
+func (m *Monitor) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	stale := m.Stale(m.clock.Now())
+	if len(stale) == 0 {
+		w.WriteHeader(http.StatusOK)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusServiceUnavailable)
+
+	for _, status := range stale {
+		_, _ = fmt.Fprintf(w, "%s %s stale for %s\n", status.Pair, status.Source, status.Age.Round(time.Second))
+	}
+}
@@ -0,0 +1,200 @@
+package freshness
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+
+	"github.com/sig-0/fxrates/storage/types"
+)
+
+var (
+	usdVES  = Pair{Base: "USD", Target: "VES"}
+	usdtVES = Pair{Base: "USDT", Target: "VES"}
+)
+
+// rate returns a rate of the source fetched the given time ago
+func rate(source types.Source, age time.Duration) fxrates.ExchangeRate {
+	return fxrates.ExchangeRate{
+		Base:      types.CurrencyUSD,
+		Target:    types.CurrencyVES,
+		Rate:      36,
+		RateType:  types.RateTypeMID,
+		Source:    source,
+		AsOf:      time.Now().Add(-48 * time.Hour),
+		FetchedAt: time.Now().Add(-age),
+	}
+}
+
+func TestPolicy_Stale(t *testing.T) {
+	t.Parallel()
+
+	var (
+		now    = time.Date(2026, time.January, 9, 16, 0, 0, 0, time.UTC)
+		policy = Policy{
+			Sources: map[string]time.Duration{"BCV": 26 * time.Hour},
+			Default: 2 * time.Hour,
+		}
+	)
+
+	testTable := []struct {
+		name  string
+		rate  fxrates.ExchangeRate
+		age   time.Duration
+		stale bool
+	}{
+		{
+			name: "within its source threshold",
+			rate: fxrates.ExchangeRate{
+				Source:    types.SourceBCV,
+				FetchedAt: now.Add(-20 * time.Hour),
+			},
+			age:   20 * time.Hour,
+			stale: false,
+		},
+		{
+			name: "past the default threshold",
+			rate: fxrates.ExchangeRate{
+				Source:    types.SourceBinance,
+				FetchedAt: now.Add(-3 * time.Hour),
+			},
+			age:   3 * time.Hour,
+			stale: true,
+		},
+		{
+			name: "without fetch time",
+			rate: fxrates.ExchangeRate{
+				Source: types.SourceBinance,
+				AsOf:   now.Add(-time.Hour),
+			},
+			age:   time.Hour,
+			stale: false,
+		},
+		{
+			name: "from the future",
+			rate: fxrates.ExchangeRate{
+				Source:    types.SourceBinance,
+				FetchedAt: now.Add(time.Minute),
+			},
+			age:   0,
+			stale: false,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			age, stale := policy.Stale(testCase.rate, now)
+
+			assert.Equal(t, testCase.age, age)
+			assert.Equal(t, testCase.stale, stale)
+		})
+	}
+
+	// A zero threshold never goes stale
+	_, stale := Policy{}.Stale(fxrates.ExchangeRate{Source: types.SourceBCV}, now)
+	assert.False(t, stale)
+}
+
+func TestMonitor_Run(t *testing.T) {
+	t.Parallel()
+
+	monitor := NewMonitor(Policy{Default: time.Hour}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var errs []error
+
+	fetch := func(_ context.Context, pair Pair) ([]fxrates.ExchangeRate, error) {
+		if pair == usdtVES {
+			return nil, errors.New("upstream down")
+		}
+
+		return []fxrates.ExchangeRate{
+			rate(types.SourceBCV, 2*time.Hour),
+			rate(types.SourceBinance, time.Minute),
+		}, nil
+	}
+
+	monitor.Run(ctx, time.Hour, []Pair{usdVES, usdtVES}, fetch, func(err error) {
+		errs = append(errs, err)
+
+		// Stop after the first round
+		cancel()
+	})
+
+	// Failed fetches leave the pair unobserved
+	assert.Len(t, errs, 1)
+
+	stale := monitor.Stale(time.Now())
+	require.Len(t, stale, 1)
+
+	assert.Equal(t, "USD/VES", stale[0].Pair)
+	assert.Equal(t, "BCV", stale[0].Source)
+	assert.Len(t, monitor.Statuses(time.Now()), 2)
+}
+
+func TestMonitor_Observe(t *testing.T) {
+	t.Parallel()
+
+	monitor := NewMonitor(Policy{Default: time.Hour}, nil)
+
+	// Sources with several rate types keep the most recently updated one
+	monitor.Observe(usdtVES, []fxrates.ExchangeRate{
+		rate(types.SourceBinance, 2*time.Hour),
+		rate(types.SourceBinance, time.Minute),
+	})
+
+	assert.Empty(t, monitor.Stale(time.Now()))
+
+	// Newer observations replace the previous ones
+	monitor.Observe(usdtVES, []fxrates.ExchangeRate{rate(types.SourceBinance, 3*time.Hour)})
+
+	assert.Len(t, monitor.Stale(time.Now()), 1)
+}
+
+func TestMonitor_WriteMetrics(t *testing.T) {
+	t.Parallel()
+
+	monitor := NewMonitor(Policy{Default: time.Hour}, nil)
+	monitor.Observe(usdVES, []fxrates.ExchangeRate{rate(types.SourceBCV, 2*time.Hour)})
+
+	var buf bytes.Buffer
+
+	require.NoError(t, monitor.WriteMetrics(&buf))
+
+	assert.Contains(t, buf.String(), "# TYPE chigui_rate_age_seconds gauge\n")
+	assert.Contains(t, buf.String(), `chigui_rate_age_seconds{pair="USD/VES",source="BCV"} 7200`)
+	assert.Contains(t, buf.String(), `chigui_rate_stale{pair="USD/VES",source="BCV"} 1`)
+}
+
+func TestMonitor_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	monitor := NewMonitor(Policy{Default: time.Hour}, nil)
+
+	// Nothing observed yet
+	recorder := httptest.NewRecorder()
+	monitor.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	monitor.Observe(usdVES, []fxrates.ExchangeRate{rate(types.SourceBCV, 2*time.Hour)})
+
+	recorder = httptest.NewRecorder()
+	monitor.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "USD/VES BCV stale for 2h0m0s")
+}
@@ -0,0 +1,145 @@
+package fxrates
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sig-0/chigui-cifras/internal/clock"
+)
+
+const (
+	// defaultFailureThreshold is the number of consecutive failures that opens the circuit
+	defaultFailureThreshold = 5
+
+	// defaultOpenDuration is how long the circuit stays open before probing the API again
+	defaultOpenDuration = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned when requests are short-circuited
+// because the API has been failing
+var ErrCircuitOpen = errors.New("fxrates circuit open")
+
+// CircuitState is the state of the client circuit breaker
+type CircuitState string
+
+const (
+	// CircuitClosed lets every request through
+	CircuitClosed CircuitState = "closed"
+
+	// CircuitOpen short-circuits every request
+	CircuitOpen CircuitState = "open"
+
+	// CircuitHalfOpen lets a single probe request through
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// CircuitStatus is a snapshot of the client circuit breaker
+type CircuitStatus struct {
+	// OpenedAt is when the circuit last opened, if ever
+	OpenedAt time.Time
+
+	State CircuitState
+
+	// ConsecutiveFailures is the number of failed requests since the last success
+	ConsecutiveFailures int
+}
+
+// breaker is a consecutive failure circuit breaker.
+// Once open, it lets a single probe through after the open duration,
+// closing again if it succeeds
+type breaker struct {
+	openedAt     time.Time
+	clock        clock.Clock
+	state        CircuitState
+	threshold    int
+	failures     int
+	openDuration time.Duration
+	probing      bool
+	mux          sync.Mutex
+}
+
+// newBreaker creates a new, closed circuit breaker, timing the open duration by the clock
+func newBreaker(threshold int, openDuration time.Duration, clk clock.Clock) *breaker {
+	return &breaker{
+		clock:        clock.Or(clk),
+		state:        CircuitClosed,
+		threshold:    threshold,
+		openDuration: openDuration,
+	}
+}
+
+// allow checks if a request can go through. Every allowed request
+// must be followed by a call to success, failure or release
+func (b *breaker) allow() error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if b.clock.Now().Sub(b.openedAt) < b.openDuration {
+			return ErrCircuitOpen
+		}
+
+		b.state = CircuitHalfOpen
+		b.probing = true
+
+		return nil
+	case CircuitHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+
+		b.probing = true
+
+		return nil
+	default:
+		return nil
+	}
+}
+
+// success records a successful request, closing the circuit
+func (b *breaker) success() {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.probing = false
+	b.failures = 0
+	b.state = CircuitClosed
+}
+
+// failure records a failed request, opening the circuit
+// if the probe failed or there were too many failures in a row
+func (b *breaker) failure() {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.probing = false
+	b.failures++
+
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+		b.state = CircuitOpen
+		b.openedAt = b.clock.Now()
+	}
+}
+
+// release records a request that says nothing about the API health,
+// like one canceled by the caller
+func (b *breaker) release() {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.probing = false
+}
+
+// status returns a snapshot of the circuit breaker
+func (b *breaker) status() CircuitStatus {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	return CircuitStatus{
+		OpenedAt:            b.openedAt,
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+	}
+}
@@ -0,0 +1,123 @@
+package fxrates
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sig-0/chigui-cifras/internal/clock"
+)
+
+func TestBreaker_Transitions(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.January, 2, 15, 4, 0, 0, time.UTC)
+
+	b := newBreaker(2, time.Minute, clock.Func(func() time.Time { return now }))
+
+	// Failures below the threshold keep the circuit closed
+	require.NoError(t, b.allow())
+	b.failure()
+	assert.Equal(t, CircuitClosed, b.status().State)
+
+	require.NoError(t, b.allow())
+	b.failure()
+
+	status := b.status()
+	assert.Equal(t, CircuitOpen, status.State)
+	assert.Equal(t, 2, status.ConsecutiveFailures)
+	assert.Equal(t, now, status.OpenedAt)
+
+	assert.ErrorIs(t, b.allow(), ErrCircuitOpen)
+
+	// After the open duration, a single probe goes through
+	now = now.Add(time.Minute)
+
+	require.NoError(t, b.allow())
+	assert.Equal(t, CircuitHalfOpen, b.status().State)
+	assert.ErrorIs(t, b.allow(), ErrCircuitOpen)
+
+	// A failed probe opens the circuit again
+	b.failure()
+	assert.Equal(t, CircuitOpen, b.status().State)
+
+	now = now.Add(time.Minute)
+
+	require.NoError(t, b.allow())
+	b.success()
+
+	status = b.status()
+	assert.Equal(t, CircuitClosed, status.State)
+	assert.Zero(t, status.ConsecutiveFailures)
+}
+
+func TestBreaker_ReleasedProbe(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.January, 2, 15, 4, 0, 0, time.UTC)
+
+	b := newBreaker(1, time.Minute, clock.Func(func() time.Time { return now }))
+
+	require.NoError(t, b.allow())
+	b.failure()
+
+	now = now.Add(time.Minute)
+
+	require.NoError(t, b.allow())
+	b.release()
+
+	// A released probe lets the next request probe instead
+	require.NoError(t, b.allow())
+	assert.Equal(t, CircuitHalfOpen, b.status().State)
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	var (
+		calls  atomic.Int32
+		status atomic.Int32
+	)
+
+	status.Store(http.StatusInternalServerError)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+
+		w.WriteHeader(int(status.Load()))
+	}))
+	t.Cleanup(srv.Close)
+
+	client := NewClient(srv.URL, time.Second)
+
+	for range defaultFailureThreshold {
+		_, err := client.Rates(context.Background(), "USD")
+		require.Error(t, err)
+	}
+
+	assert.Equal(t, CircuitOpen, client.Circuit().State)
+
+	// Open circuits don't reach the API
+	_, err := client.Rates(context.Background(), "USD")
+
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(defaultFailureThreshold), calls.Load())
+
+	// Client errors don't count as failures
+	status.Store(http.StatusNotFound)
+
+	healthy := NewClient(srv.URL, time.Second)
+
+	for range defaultFailureThreshold {
+		_, err := healthy.Rates(context.Background(), "USD")
+		require.Error(t, err)
+	}
+
+	assert.Equal(t, CircuitClosed, healthy.Circuit().State)
+}
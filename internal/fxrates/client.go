@@ -7,24 +7,34 @@ import (
 	"net/http"
 	"net/url"
 	"time"
+
+	"github.com/sig-0/chigui-cifras/internal/clock"
 )
 
 // Client is an HTTP client for the fxrates API
 type Client struct {
 	httpClient *http.Client
+	breaker    *breaker
 	baseURL    string
 }
 
-// NewClient creates a new fxrates API client
+// NewClient creates a new fxrates API client.
+// Requests are short-circuited with ErrCircuitOpen while the API keeps failing
 func NewClient(baseURL string, timeout time.Duration) *Client {
 	return &Client{
 		baseURL: baseURL,
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
+		breaker: newBreaker(defaultFailureThreshold, defaultOpenDuration, clock.System),
 	}
 }
 
+// Circuit returns a snapshot of the client circuit breaker
+func (c *Client) Circuit() CircuitStatus {
+	return c.breaker.status()
+}
+
 // Rate fetches the exchange rate for a specific currency pair.
 // If source is non-empty, it filters by that source
 func (c *Client) Rate(ctx context.Context, base, target, source string) (*PageExchangeRate, error) {
@@ -53,7 +63,8 @@ func (c *Client) Currencies(ctx context.Context) (*CurrenciesResponse, error) {
 	return get[CurrenciesResponse](ctx, c, "/v1/currencies")
 }
 
-// Health checks if the API is healthy
+// Health checks if the API is healthy.
+// It bypasses the circuit breaker, so it can be used to probe an open circuit
 func (c *Client) Health(ctx context.Context) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/health", http.NoBody)
 	if err != nil {
@@ -84,12 +95,30 @@ func get[T any](ctx context.Context, c *Client, path string) (*T, error) {
 		return nil, fmt.Errorf("unable to create request: %w", err)
 	}
 
+	if err := c.breaker.allow(); err != nil {
+		return nil, err
+	}
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		if ctx.Err() != nil {
+			// Canceled by the caller, not the API's fault
+			c.breaker.release()
+		} else {
+			c.breaker.failure()
+		}
+
 		return nil, fmt.Errorf("unable to execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	// Client errors mean the API is up, only server errors count as failures
+	if resp.StatusCode >= http.StatusInternalServerError {
+		c.breaker.failure()
+	} else {
+		c.breaker.success()
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
@@ -0,0 +1,287 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+const (
+	// CountParam is the placeholder used to select the plural form of a message
+	CountParam = "count"
+
+	formOne   = "one"
+	formOther = "other"
+
+	catalogExt = ".toml"
+)
+
+var (
+	errNoLocales          = errors.New("no locale files found")
+	errMissingDefault     = errors.New("default locale not found")
+	errInvalidPluralForms = errors.New("plural messages must define an \"other\" form")
+)
+
+// Params holds the placeholder values for a message
+type Params map[string]any
+
+// Raw marks a param value as pre-rendered markup,
+// inserted into escaped messages verbatim
+type Raw string
+
+// message is a single catalog entry, with one or more plural forms
+type message map[string]string
+
+// pluralRules maps a locale to its plural form selector.
+// Locales without a rule use the English one
+var pluralRules = map[string]func(int) string{
+	"pt": func(n int) string {
+		if n == 0 || n == 1 {
+			return formOne
+		}
+
+		return formOther
+	},
+}
+
+func defaultPluralRule(n int) string {
+	if n == 1 {
+		return formOne
+	}
+
+	return formOther
+}
+
+// Catalog holds the translated messages for every locale
+type Catalog struct {
+	messages      map[string]map[string]message
+	defaultLocale string
+}
+
+// Load reads every <locale>.toml file at the root of the given FS into a catalog.
+// Nested TOML tables are flattened into dot-separated keys, and tables holding
+// only plural forms ("one", "other") are treated as a single plural message
+func Load(fsys fs.FS, defaultLocale string) (*Catalog, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("unable to read locales: %w", err)
+	}
+
+	c := &Catalog{
+		messages:      make(map[string]map[string]message),
+		defaultLocale: defaultLocale,
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != catalogExt {
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", entry.Name(), err)
+		}
+
+		tree, err := toml.LoadBytes(content)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %s: %w", entry.Name(), err)
+		}
+
+		messages := make(map[string]message)
+		if err := flatten("", tree.ToMap(), messages); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", entry.Name(), err)
+		}
+
+		c.messages[strings.TrimSuffix(entry.Name(), catalogExt)] = messages
+	}
+
+	if len(c.messages) == 0 {
+		return nil, errNoLocales
+	}
+
+	if _, ok := c.messages[defaultLocale]; !ok {
+		return nil, fmt.Errorf("%w: %q", errMissingDefault, defaultLocale)
+	}
+
+	return c, nil
+}
+
+// flatten walks the parsed TOML tree, collecting messages by their dotted key
+func flatten(prefix string, tree map[string]any, out map[string]message) error {
+	for key, value := range tree {
+		fullKey := key
+		if prefix != "" {
+			fullKey = prefix + "." + key
+		}
+
+		switch v := value.(type) {
+		case string:
+			out[fullKey] = message{formOther: v}
+		case map[string]any:
+			if !isPlural(v) {
+				if err := flatten(fullKey, v, out); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			forms := make(message, len(v))
+			for form, text := range v {
+				forms[form], _ = text.(string)
+			}
+
+			if _, ok := forms[formOther]; !ok {
+				return fmt.Errorf("%w: %s", errInvalidPluralForms, fullKey)
+			}
+
+			out[fullKey] = forms
+		default:
+			return fmt.Errorf("unsupported value for %s: %T", fullKey, value)
+		}
+	}
+
+	return nil
+}
+
+// isPlural checks if the table only holds plural forms
+func isPlural(table map[string]any) bool {
+	if len(table) == 0 {
+		return false
+	}
+
+	for form, value := range table {
+		if form != formOne && form != formOther {
+			return false
+		}
+
+		if _, ok := value.(string); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Render returns the message for the given locale and key, with its
+// placeholders ({name}) replaced by the given params. Missing messages fall back
+// to the default locale, and finally to the key itself
+func (c *Catalog) Render(locale, key string, params Params) string {
+	return c.RenderEscaped(locale, key, params, noEscape)
+}
+
+// RenderEscaped is like Render, but passes both the message text and the param
+// values through the escape function, so the result is safe to use as markup.
+// Params of type Raw are inserted as-is
+func (c *Catalog) RenderEscaped(locale, key string, params Params, escape func(string) string) string {
+	msg, ok := c.messages[locale][key]
+	if !ok {
+		locale = c.defaultLocale
+
+		if msg, ok = c.messages[locale][key]; !ok {
+			return escape(key)
+		}
+	}
+
+	return substitute(msg.form(locale, params), params, escape)
+}
+
+func noEscape(text string) string {
+	return text
+}
+
+// Locales returns the sorted list of loaded locales
+func (c *Catalog) Locales() []string {
+	locales := make([]string, 0, len(c.messages))
+	for locale := range c.messages {
+		locales = append(locales, locale)
+	}
+
+	sort.Strings(locales)
+
+	return locales
+}
+
+// Keys returns the sorted message keys for the given locale
+func (c *Catalog) Keys(locale string) []string {
+	keys := make([]string, 0, len(c.messages[locale]))
+	for key := range c.messages[locale] {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// form selects the plural form of the message, based on the count param
+func (m message) form(locale string, params Params) string {
+	if len(m) == 1 {
+		return m[formOther]
+	}
+
+	count, ok := params[CountParam].(int)
+	if !ok {
+		return m[formOther]
+	}
+
+	rule, ok := pluralRules[locale]
+	if !ok {
+		rule = defaultPluralRule
+	}
+
+	if text, ok := m[rule(count)]; ok {
+		return text
+	}
+
+	return m[formOther]
+}
+
+// substitute replaces the {name} placeholders in the text with the given params,
+// escaping everything but Raw params. Unknown placeholders are left untouched
+func substitute(text string, params Params, escape func(string) string) string {
+	var sb strings.Builder
+
+	for {
+		start := strings.IndexByte(text, '{')
+		if start == -1 {
+			break
+		}
+
+		end := strings.IndexByte(text[start:], '}')
+		if end == -1 {
+			break
+		}
+
+		end += start
+
+		sb.WriteString(escape(text[:start]))
+
+		value, ok := params[text[start+1:end]]
+
+		switch v := value.(type) {
+		case Raw:
+			sb.WriteString(string(v))
+		default:
+			if !ok {
+				// Keep unknown placeholders as-is
+				sb.WriteString(escape(text[start : end+1]))
+
+				break
+			}
+
+			sb.WriteString(escape(fmt.Sprint(v)))
+		}
+
+		text = text[end+1:]
+	}
+
+	sb.WriteString(escape(text))
+
+	return sb.String()
+}
@@ -0,0 +1,210 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCatalog(t *testing.T) *Catalog {
+	t.Helper()
+
+	fsys := fstest.MapFS{
+		"en.toml": &fstest.MapFile{Data: []byte(`
+greeting = "Hello, {name}!"
+
+[items]
+count = { one = "{count} item", other = "{count} items" }
+
+[nested.deep]
+message = "Deep"
+`)},
+		"pt.toml": &fstest.MapFile{Data: []byte(`
+greeting = "Olá, {name}!"
+
+[items]
+count = { one = "{count} item", other = "{count} itens" }
+`)},
+		"README.md": &fstest.MapFile{Data: []byte("ignored")},
+	}
+
+	c, err := Load(fsys, "en")
+	require.NoError(t, err)
+
+	return c
+}
+
+func TestCatalog_Load(t *testing.T) {
+	t.Parallel()
+
+	c := testCatalog(t)
+
+	assert.Equal(t, []string{"en", "pt"}, c.Locales())
+	assert.Equal(t, []string{"greeting", "items.count", "nested.deep.message"}, c.Keys("en"))
+	assert.Equal(t, []string{"greeting", "items.count"}, c.Keys("pt"))
+}
+
+func TestCatalog_LoadErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no locales", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Load(fstest.MapFS{}, "en")
+
+		assert.ErrorIs(t, err, errNoLocales)
+	})
+
+	t.Run("missing default locale", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Load(fstest.MapFS{
+			"en.toml": &fstest.MapFile{Data: []byte(`key = "value"`)},
+		}, "es")
+
+		assert.ErrorIs(t, err, errMissingDefault)
+	})
+
+	t.Run("plural without other form", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Load(fstest.MapFS{
+			"en.toml": &fstest.MapFile{Data: []byte(`key = { one = "value" }`)},
+		}, "en")
+
+		assert.ErrorIs(t, err, errInvalidPluralForms)
+	})
+
+	t.Run("invalid toml", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Load(fstest.MapFS{
+			"en.toml": &fstest.MapFile{Data: []byte(`key = `)},
+		}, "en")
+
+		assert.ErrorContains(t, err, "unable to parse en.toml")
+	})
+}
+
+func TestCatalog_Render(t *testing.T) {
+	t.Parallel()
+
+	c := testCatalog(t)
+
+	testTable := []struct {
+		name     string
+		locale   string
+		key      string
+		params   Params
+		expected string
+	}{
+		{
+			name:     "placeholder",
+			locale:   "en",
+			key:      "greeting",
+			params:   Params{"name": "Chigui"},
+			expected: "Hello, Chigui!",
+		},
+		{
+			name:     "unknown placeholder kept",
+			locale:   "en",
+			key:      "greeting",
+			expected: "Hello, {name}!",
+		},
+		{
+			name:     "plural one",
+			locale:   "en",
+			key:      "items.count",
+			params:   Params{CountParam: 1},
+			expected: "1 item",
+		},
+		{
+			name:     "plural other",
+			locale:   "en",
+			key:      "items.count",
+			params:   Params{CountParam: 0},
+			expected: "0 items",
+		},
+		{
+			name:     "portuguese plural rule",
+			locale:   "pt",
+			key:      "items.count",
+			params:   Params{CountParam: 0},
+			expected: "0 item",
+		},
+		{
+			name:     "plural without count",
+			locale:   "pt",
+			key:      "items.count",
+			expected: "{count} itens",
+		},
+		{
+			name:     "nested key",
+			locale:   "en",
+			key:      "nested.deep.message",
+			expected: "Deep",
+		},
+		{
+			name:     "missing key falls back to default locale",
+			locale:   "pt",
+			key:      "nested.deep.message",
+			expected: "Deep",
+		},
+		{
+			name:     "unknown locale falls back to default locale",
+			locale:   "de",
+			key:      "greeting",
+			params:   Params{"name": "Chigui"},
+			expected: "Hello, Chigui!",
+		},
+		{
+			name:     "unknown key",
+			locale:   "en",
+			key:      "missing",
+			expected: "missing",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expected, c.Render(testCase.locale, testCase.key, testCase.params))
+		})
+	}
+}
+
+func TestCatalog_RenderEscaped(t *testing.T) {
+	t.Parallel()
+
+	c := testCatalog(t)
+
+	escape := func(text string) string {
+		return strings.ReplaceAll(text, "!", `\!`)
+	}
+
+	t.Run("escapes text and params", func(t *testing.T) {
+		t.Parallel()
+
+		rendered := c.RenderEscaped("en", "greeting", Params{"name": "Chigui!"}, escape)
+
+		assert.Equal(t, `Hello, Chigui\!\!`, rendered)
+	})
+
+	t.Run("raw params are not escaped", func(t *testing.T) {
+		t.Parallel()
+
+		rendered := c.RenderEscaped("en", "greeting", Params{"name": Raw("*Chigui!*")}, escape)
+
+		assert.Equal(t, `Hello, *Chigui!*\!`, rendered)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, `missing\!`, c.RenderEscaped("en", "missing!", nil, escape))
+	})
+}
@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the Prometheus text exposition format content type
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Type is a Prometheus metric type
+type Type string
+
+const (
+	Counter Type = "counter"
+	Gauge   Type = "gauge"
+)
+
+// Collector writes its metrics in the Prometheus text exposition format
+type Collector interface {
+	WriteMetrics(w io.Writer) error
+}
+
+// Registry serves the metrics of every registered collector
+type Registry struct {
+	collectors []Collector
+	mux        sync.RWMutex
+}
+
+// NewRegistry creates a new, empty metrics registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a collector to the registry
+func (r *Registry) Register(collector Collector) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.collectors = append(r.collectors, collector)
+}
+
+// WriteMetrics writes the metrics of every registered collector, in registration order
+func (r *Registry) WriteMetrics(w io.Writer) error {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	for _, collector := range r.collectors {
+		if err := collector.WriteMetrics(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ServeHTTP serves the registry metrics
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	// Render fully before writing, so a failing collector
+	// doesn't leave a half-written response
+	var buf bytes.Buffer
+
+	if err := r.WriteMetrics(&buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	_, _ = buf.WriteTo(w)
+}
+
+// WriteHeader writes the HELP and TYPE lines of a metric
+func WriteHeader(w io.Writer, name, help string, metricType Type) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, escapeHelp(help), name, metricType)
+
+	return err
+}
+
+// WriteSample writes a single metric sample, with its labels sorted by name
+func WriteSample(w io.Writer, name string, labels map[string]string, value float64) error {
+	var sb strings.Builder
+
+	sb.WriteString(name)
+
+	if len(labels) > 0 {
+		names := make([]string, 0, len(labels))
+		for label := range labels {
+			names = append(names, label)
+		}
+
+		sort.Strings(names)
+
+		sb.WriteByte('{')
+
+		for i, label := range names {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+
+			sb.WriteString(label)
+			sb.WriteString(`="`)
+			sb.WriteString(labelReplacer.Replace(labels[label]))
+			sb.WriteByte('"')
+		}
+
+		sb.WriteByte('}')
+	}
+
+	sb.WriteByte(' ')
+	sb.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	sb.WriteByte('\n')
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+// labelReplacer escapes label values, as required by the exposition format
+var labelReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+)
+
+// escapeHelp escapes HELP text, as required by the exposition format
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type collectorFunc func(w io.Writer) error
+
+func (f collectorFunc) WriteMetrics(w io.Writer) error {
+	return f(w)
+}
+
+func TestMetrics_WriteSample(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		name     string
+		labels   map[string]string
+		value    float64
+		expected string
+	}{
+		{
+			name:     "no labels",
+			value:    3,
+			expected: "test_total 3\n",
+		},
+		{
+			name:     "sorted labels",
+			labels:   map[string]string{"target": "VES", "base": "USD"},
+			value:    1.5,
+			expected: "test_total{base=\"USD\",target=\"VES\"} 1.5\n",
+		},
+		{
+			name:     "escaped label value",
+			labels:   map[string]string{"name": "a\"b\\c\nd"},
+			value:    0,
+			expected: "test_total{name=\"a\\\"b\\\\c\\nd\"} 0\n",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			var sb strings.Builder
+
+			require.NoError(t, WriteSample(&sb, "test_total", testCase.labels, testCase.value))
+			assert.Equal(t, testCase.expected, sb.String())
+		})
+	}
+}
+
+func TestMetrics_Registry(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+
+	registry.Register(collectorFunc(func(w io.Writer) error {
+		if err := WriteHeader(w, "first_total", "The first metric", Counter); err != nil {
+			return err
+		}
+
+		return WriteSample(w, "first_total", nil, 1)
+	}))
+	registry.Register(collectorFunc(func(w io.Writer) error {
+		return WriteSample(w, "second", nil, 2)
+	}))
+
+	recorder := httptest.NewRecorder()
+	registry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, ContentType, recorder.Header().Get("Content-Type"))
+	assert.Equal(
+		t,
+		"# HELP first_total The first metric\n# TYPE first_total counter\nfirst_total 1\nsecond 2\n",
+		recorder.Body.String(),
+	)
+}
+
+func TestMetrics_RegistryError(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+
+	registry.Register(collectorFunc(func(w io.Writer) error {
+		if err := WriteSample(w, "partial", nil, 1); err != nil {
+			return err
+		}
+
+		return errors.New("collector failed")
+	}))
+
+	recorder := httptest.NewRecorder()
+	registry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "partial")
+}
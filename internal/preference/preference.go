@@ -0,0 +1,96 @@
+package preference
+
+import (
+	"github.com/sig-0/fxrates/provider/currencies"
+	"github.com/sig-0/fxrates/storage/types"
+
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+)
+
+// Preference matches the rates of a source and rate type.
+// An empty source or rate type matches any
+type Preference struct {
+	Source   fxrates.Source
+	RateType fxrates.RateType
+}
+
+// Matches checks if the rate has the preferred source and rate type
+func (p Preference) Matches(rate fxrates.ExchangeRate) bool {
+	return (p.Source == "" || p.Source == rate.Source) &&
+		(p.RateType == "" || p.RateType == rate.RateType)
+}
+
+// Rule is how the rates of a base currency are fetched, and which one is preferred
+type Rule struct {
+	// Source is the only source the rates are fetched from.
+	// If empty, they're fetched from every source
+	Source fxrates.Source
+
+	// Preferences are tried in order, selecting the first rate matching one.
+	// If none matches, the first rate is selected
+	Preferences []Preference
+}
+
+// Policy holds the rules of each base currency
+type Policy struct {
+	// Currencies are the rules by base currency, like "USD"
+	Currencies map[fxrates.Currency]Rule
+
+	// Default is the rule of the currencies without their own
+	Default Rule
+}
+
+// Default returns the built-in policy: the official fiat currencies are fetched from BCV,
+// preferring its MID rate, then any MID rate. The rest, like USDT, take the first rate
+func Default() Policy {
+	official := Rule{
+		Source: types.SourceBCV,
+		Preferences: []Preference{
+			{Source: types.SourceBCV, RateType: types.RateTypeMID},
+			{RateType: types.RateTypeMID},
+		},
+	}
+
+	return Policy{
+		Currencies: map[fxrates.Currency]Rule{
+			currencies.USD: official,
+			currencies.EUR: official,
+			currencies.RUB: official,
+			currencies.TRY: official,
+			currencies.CNY: official,
+		},
+	}
+}
+
+// Rule returns the rule of the base currency
+func (p Policy) Rule(base fxrates.Currency) Rule {
+	if rule, ok := p.Currencies[base]; ok {
+		return rule
+	}
+
+	return p.Default
+}
+
+// Source returns the source the rates of the base currency are fetched from,
+// or an empty one for every source
+func (p Policy) Source(base fxrates.Currency) fxrates.Source {
+	return p.Rule(base).Source
+}
+
+// Select returns the preferred rate of a pair's rates, by the rule of their base currency,
+// or nil if there are none
+func (p Policy) Select(rates []fxrates.ExchangeRate) *fxrates.ExchangeRate {
+	if len(rates) == 0 {
+		return nil
+	}
+
+	for _, preference := range p.Rule(rates[0].Base).Preferences {
+		for i := range rates {
+			if preference.Matches(rates[i]) {
+				return &rates[i]
+			}
+		}
+	}
+
+	return &rates[0]
+}
@@ -0,0 +1,190 @@
+package preference
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sig-0/chigui-cifras/internal/fxrates"
+
+	"github.com/sig-0/fxrates/storage/types"
+)
+
+// rate returns a rate of the pair against VES, from the source and of the rate type
+func rate(base fxrates.Currency, source fxrates.Source, rateType fxrates.RateType) fxrates.ExchangeRate {
+	return fxrates.ExchangeRate{
+		Base:     base,
+		Target:   types.CurrencyVES,
+		Rate:     36,
+		RateType: rateType,
+		Source:   source,
+	}
+}
+
+func TestPreference_Matches(t *testing.T) {
+	t.Parallel()
+
+	bcvMid := rate(types.CurrencyUSD, types.SourceBCV, types.RateTypeMID)
+
+	testTable := []struct {
+		name       string
+		preference Preference
+		matches    bool
+	}{
+		{
+			name:       "source and rate type",
+			preference: Preference{Source: types.SourceBCV, RateType: types.RateTypeMID},
+			matches:    true,
+		},
+		{
+			name:       "any source",
+			preference: Preference{RateType: types.RateTypeMID},
+			matches:    true,
+		},
+		{
+			name:       "any rate type",
+			preference: Preference{Source: types.SourceBCV},
+			matches:    true,
+		},
+		{
+			name:       "anything",
+			preference: Preference{},
+			matches:    true,
+		},
+		{
+			name:       "other source",
+			preference: Preference{Source: types.SourceBinance, RateType: types.RateTypeMID},
+			matches:    false,
+		},
+		{
+			name:       "other rate type",
+			preference: Preference{Source: types.SourceBCV, RateType: types.RateTypeBUY},
+			matches:    false,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.matches, testCase.preference.Matches(bcvMid))
+		})
+	}
+}
+
+func TestPolicy_Source(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		base     fxrates.Currency
+		expected fxrates.Source
+	}{
+		{base: types.CurrencyUSD, expected: types.SourceBCV},
+		{base: types.CurrencyEUR, expected: types.SourceBCV},
+		{base: "RUB", expected: types.SourceBCV},
+		{base: "TRY", expected: types.SourceBCV},
+		{base: "CNY", expected: types.SourceBCV},
+		{base: types.CurrencyUSDT, expected: ""},
+		{base: types.CurrencyVES, expected: ""},
+		{base: "COP", expected: ""},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.base.String(), func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expected, Default().Source(testCase.base))
+		})
+	}
+}
+
+func TestPolicy_Select(t *testing.T) {
+	t.Parallel()
+
+	var (
+		usdBCVMid       = rate(types.CurrencyUSD, types.SourceBCV, types.RateTypeMID)
+		usdBCVBuy       = rate(types.CurrencyUSD, types.SourceBCV, types.RateTypeBUY)
+		usdBinanceMid   = rate(types.CurrencyUSD, types.SourceBinance, types.RateTypeMID)
+		usdBinanceBuy   = rate(types.CurrencyUSD, types.SourceBinance, types.RateTypeBUY)
+		usdBinanceSell  = rate(types.CurrencyUSD, types.SourceBinance, types.RateTypeSELL)
+		usdtBinanceBuy  = rate(types.CurrencyUSDT, types.SourceBinance, types.RateTypeBUY)
+		usdtBinanceSell = rate(types.CurrencyUSDT, types.SourceBinance, types.RateTypeSELL)
+		usdtBCVMid      = rate(types.CurrencyUSDT, types.SourceBCV, types.RateTypeMID)
+	)
+
+	testTable := []struct {
+		name     string
+		policy   Policy
+		rates    []fxrates.ExchangeRate
+		expected *fxrates.ExchangeRate
+	}{
+		{
+			name:     "no rates",
+			policy:   Default(),
+			rates:    nil,
+			expected: nil,
+		},
+		{
+			name:     "single rate",
+			policy:   Default(),
+			rates:    []fxrates.ExchangeRate{usdBinanceBuy},
+			expected: &usdBinanceBuy,
+		},
+		{
+			name:     "official currency prefers the BCV MID rate",
+			policy:   Default(),
+			rates:    []fxrates.ExchangeRate{usdBinanceMid, usdBCVBuy, usdBCVMid},
+			expected: &usdBCVMid,
+		},
+		{
+			name:     "official currency falls back to any MID rate",
+			policy:   Default(),
+			rates:    []fxrates.ExchangeRate{usdBinanceBuy, usdBCVBuy, usdBinanceMid},
+			expected: &usdBinanceMid,
+		},
+		{
+			name:     "official currency falls back to the first rate",
+			policy:   Default(),
+			rates:    []fxrates.ExchangeRate{usdBinanceSell, usdBinanceBuy},
+			expected: &usdBinanceSell,
+		},
+		{
+			name:     "other currency takes the first rate",
+			policy:   Default(),
+			rates:    []fxrates.ExchangeRate{usdtBinanceSell, usdtBCVMid, usdtBinanceBuy},
+			expected: &usdtBinanceSell,
+		},
+		{
+			name: "configured currency",
+			policy: Policy{
+				Currencies: map[fxrates.Currency]Rule{
+					types.CurrencyUSDT: {Preferences: []Preference{{Source: types.SourceBinance, RateType: types.RateTypeBUY}}},
+				},
+			},
+			rates:    []fxrates.ExchangeRate{usdtBCVMid, usdtBinanceSell, usdtBinanceBuy},
+			expected: &usdtBinanceBuy,
+		},
+		{
+			name: "configured default",
+			policy: Policy{
+				Default: Rule{Preferences: []Preference{{RateType: types.RateTypeSELL}}},
+			},
+			rates:    []fxrates.ExchangeRate{usdtBCVMid, usdtBinanceBuy, usdtBinanceSell},
+			expected: &usdtBinanceSell,
+		},
+		{
+			name:     "empty policy takes the first rate",
+			policy:   Policy{},
+			rates:    []fxrates.ExchangeRate{usdBinanceBuy, usdBCVMid},
+			expected: &usdBinanceBuy,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expected, testCase.policy.Select(testCase.rates))
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"errors"
+	"maps"
+	"time"
+)
+
+// ErrNoAnnouncement is returned when recording the delivery
+// of an announcement for a pair that was never announced
+var ErrNoAnnouncement = errors.New("no announced rate")
+
+// AnnouncedRate is the latest rate announced for a pair,
+// so new rates are only announced once, even across restarts
+type AnnouncedRate struct {
+	// AsOf is when the announced rate became effective
+	AsOf time.Time `json:"as_of"`
+
+	// Deliveries holds the delivery status of the announcement
+	// to every opted-in chat, by chat ID
+	Deliveries map[int64]DeliveryStatus `json:"deliveries,omitempty"`
+
+	// Failures counts the failed deliveries to every chat, by chat ID
+	Failures map[int64]int `json:"failures,omitempty"`
+
+	// Previous is the rate announced before, the change is announced against
+	Previous float64 `json:"previous,omitempty"`
+
+	Rate float64 `json:"rate"`
+}
+
+// clone returns a deep copy of the announced rate
+func (a AnnouncedRate) clone() AnnouncedRate {
+	a.Deliveries = maps.Clone(a.Deliveries)
+	a.Failures = maps.Clone(a.Failures)
+
+	return a
+}
+
+// Announced returns a copy of the latest rate announced for the "BASE/TARGET" pair, if any
+func (s *Store) Announced(pair string) (AnnouncedRate, bool) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	announced, ok := s.data.Announced[pair]
+
+	return announced.clone(), ok
+}
+
+// SetAnnounced records the latest rate announced for the "BASE/TARGET" pair,
+// and persists the store
+func (s *Store) SetAnnounced(pair string, announced AnnouncedRate) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.data.Announced == nil {
+		s.data.Announced = make(map[string]AnnouncedRate)
+	}
+
+	s.data.Announced[pair] = announced.clone()
+
+	return s.persist()
+}
+
+// SetAnnouncedDelivery records the delivery status of the latest rate announced
+// for the "BASE/TARGET" pair to a chat, counting the failed ones.
+// The store is persisted in batches, like the broadcast deliveries
+func (s *Store) SetAnnouncedDelivery(pair string, chatID int64, status DeliveryStatus) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	announced, ok := s.data.Announced[pair]
+	if !ok {
+		return ErrNoAnnouncement
+	}
+
+	if announced.Deliveries == nil {
+		announced.Deliveries = make(map[int64]DeliveryStatus)
+	}
+
+	announced.Deliveries[chatID] = status
+
+	if status == DeliveryFailed {
+		if announced.Failures == nil {
+			announced.Failures = make(map[int64]int)
+		}
+
+		announced.Failures[chatID]++
+	}
+
+	s.data.Announced[pair] = announced
+
+	return s.persistBatched()
+}
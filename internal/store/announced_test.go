@@ -0,0 +1,85 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Announced(t *testing.T) {
+	t.Parallel()
+
+	var (
+		path      = filepath.Join(t.TempDir(), "store.json")
+		announced = AnnouncedRate{
+			AsOf: time.Date(2026, time.January, 2, 0, 0, 0, 0, time.UTC),
+			Rate: 36.5,
+		}
+	)
+
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	_, ok := s.Announced("USD/VES")
+	assert.False(t, ok)
+
+	require.NoError(t, s.SetAnnounced("USD/VES", announced))
+
+	require.NoError(t, s.Close())
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+
+	stored, ok := reopened.Announced("USD/VES")
+	require.True(t, ok)
+	assert.Equal(t, announced, stored)
+
+	_, ok = reopened.Announced("EUR/VES")
+	assert.False(t, ok)
+}
+
+func TestStore_SetAnnouncedDelivery(t *testing.T) {
+	t.Parallel()
+
+	var (
+		path = filepath.Join(t.TempDir(), "store.json")
+		asOf = time.Date(2026, time.January, 2, 0, 0, 0, 0, time.UTC)
+	)
+
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, s.SetAnnouncedDelivery("USD/VES", 1, DeliverySent), ErrNoAnnouncement)
+
+	require.NoError(t, s.SetAnnounced("USD/VES", AnnouncedRate{
+		AsOf:       asOf,
+		Deliveries: map[int64]DeliveryStatus{1: DeliveryPending, 2: DeliveryPending},
+		Previous:   36,
+		Rate:       36.5,
+	}))
+
+	require.NoError(t, s.SetAnnouncedDelivery("USD/VES", 1, DeliverySent))
+	require.NoError(t, s.SetAnnouncedDelivery("USD/VES", 2, DeliveryFailed))
+	require.NoError(t, s.SetAnnouncedDelivery("USD/VES", 2, DeliveryFailed))
+
+	require.NoError(t, s.Close())
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+
+	announced, ok := reopened.Announced("USD/VES")
+	require.True(t, ok)
+
+	assert.Equal(t, map[int64]DeliveryStatus{1: DeliverySent, 2: DeliveryFailed}, announced.Deliveries)
+	assert.Equal(t, map[int64]int{2: 2}, announced.Failures)
+	assert.Equal(t, 36.0, announced.Previous)
+
+	// The returned rate is a copy
+	announced.Deliveries[1] = DeliveryFailed
+
+	stored, _ := reopened.Announced("USD/VES")
+	assert.Equal(t, DeliverySent, stored.Deliveries[1])
+}
@@ -0,0 +1,128 @@
+package store
+
+import (
+	"errors"
+	"maps"
+	"time"
+)
+
+var (
+	// ErrBroadcastInProgress is returned when starting a broadcast
+	// while the previous one is still unfinished
+	ErrBroadcastInProgress = errors.New("broadcast in progress")
+
+	// ErrNoBroadcast is returned when updating a broadcast
+	// that doesn't exist or is already finished
+	ErrNoBroadcast = errors.New("no unfinished broadcast")
+)
+
+// DeliveryStatus is the delivery status of a broadcast to a single chat
+type DeliveryStatus string
+
+const (
+	// DeliveryPending is a message not sent yet
+	DeliveryPending DeliveryStatus = "pending"
+
+	// DeliverySent is a message accepted by Telegram
+	DeliverySent DeliveryStatus = "sent"
+
+	// DeliveryFailed is a message rejected by Telegram
+	DeliveryFailed DeliveryStatus = "failed"
+
+	// DeliveryBlocked is a message rejected because the bot was blocked or removed from the chat
+	DeliveryBlocked DeliveryStatus = "blocked"
+)
+
+// Broadcast is an announcement sent to every known chat.
+// It's persisted with the delivery status of every recipient, so it can resume after a restart
+type Broadcast struct {
+	StartedAt time.Time `json:"started_at"`
+
+	// FinishedAt is when every delivery was attempted, if it already was
+	FinishedAt time.Time `json:"finished_at,omitzero"`
+
+	// Deliveries holds the delivery status of every recipient, by chat ID
+	Deliveries map[int64]DeliveryStatus `json:"deliveries"`
+
+	Text string `json:"text"`
+}
+
+// Finished checks if every delivery of the broadcast was attempted
+func (b Broadcast) Finished() bool {
+	return !b.FinishedAt.IsZero()
+}
+
+// Broadcast returns a copy of the latest broadcast, if any
+func (s *Store) Broadcast() (Broadcast, bool) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	if s.data.Broadcast == nil {
+		return Broadcast{}, false
+	}
+
+	broadcast := *s.data.Broadcast
+	broadcast.Deliveries = maps.Clone(broadcast.Deliveries)
+
+	return broadcast, true
+}
+
+// StartBroadcast replaces the latest broadcast with a new one,
+// pending delivery to the given chats, and persists the store
+func (s *Store) StartBroadcast(text string, chatIDs []int64, startedAt time.Time) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.data.Broadcast != nil && !s.data.Broadcast.Finished() {
+		return ErrBroadcastInProgress
+	}
+
+	deliveries := make(map[int64]DeliveryStatus, len(chatIDs))
+	for _, chatID := range chatIDs {
+		deliveries[chatID] = DeliveryPending
+	}
+
+	s.data.Broadcast = &Broadcast{
+		StartedAt:  startedAt,
+		Deliveries: deliveries,
+		Text:       text,
+	}
+
+	return s.persist()
+}
+
+// SetDelivery records the delivery status of the unfinished broadcast to a chat.
+// The store is persisted in batches, so a crash may send the latest deliveries again
+// when the broadcast resumes. Blocked deliveries also mark the chat as blocked
+func (s *Store) SetDelivery(chatID int64, status DeliveryStatus, at time.Time) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.data.Broadcast == nil || s.data.Broadcast.Finished() {
+		return ErrNoBroadcast
+	}
+
+	s.data.Broadcast.Deliveries[chatID] = status
+
+	if status == DeliveryBlocked {
+		if chat, ok := s.data.Chats[chatID]; ok {
+			chat.BlockedAt = at
+		}
+	}
+
+	return s.persistBatched()
+}
+
+// FinishBroadcast marks the unfinished broadcast as finished, and persists the store
+func (s *Store) FinishBroadcast(finishedAt time.Time) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.data.Broadcast == nil || s.data.Broadcast.Finished() {
+		return ErrNoBroadcast
+	}
+
+	s.data.Broadcast.FinishedAt = finishedAt
+
+	return s.persist()
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Broadcast(t *testing.T) {
+	t.Parallel()
+
+	var (
+		path      = filepath.Join(t.TempDir(), "store.json")
+		startedAt = time.Date(2026, time.January, 2, 15, 4, 0, 0, time.UTC)
+	)
+
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	_, ok := s.Broadcast()
+	assert.False(t, ok)
+
+	assert.ErrorIs(t, s.SetDelivery(1, DeliverySent, startedAt), ErrNoBroadcast)
+
+	for _, id := range []int64{1, 2} {
+		_, err := s.RegisterChat(id, "private", startedAt)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, s.StartBroadcast("hola", []int64{1, 2}, startedAt))
+	assert.ErrorIs(t, s.StartBroadcast("otra", []int64{1}, startedAt), ErrBroadcastInProgress)
+
+	require.NoError(t, s.SetDelivery(1, DeliverySent, startedAt))
+	require.NoError(t, s.SetDelivery(2, DeliveryBlocked, startedAt.Add(time.Minute)))
+
+	require.NoError(t, s.Close())
+
+	// The unfinished broadcast survives a restart
+	reopened, err := Open(path)
+	require.NoError(t, err)
+
+	broadcast, ok := reopened.Broadcast()
+	require.True(t, ok)
+
+	assert.Equal(t, "hola", broadcast.Text)
+	assert.False(t, broadcast.Finished())
+	assert.Equal(t, map[int64]DeliveryStatus{1: DeliverySent, 2: DeliveryBlocked}, broadcast.Deliveries)
+
+	chat, _ := reopened.Chat(2)
+	assert.Equal(t, startedAt.Add(time.Minute), chat.BlockedAt)
+
+	// The returned broadcast is a copy
+	broadcast.Deliveries[1] = DeliveryFailed
+
+	stored, _ := reopened.Broadcast()
+	assert.Equal(t, DeliverySent, stored.Deliveries[1])
+
+	require.NoError(t, reopened.FinishBroadcast(startedAt.Add(time.Hour)))
+	assert.ErrorIs(t, reopened.FinishBroadcast(startedAt), ErrNoBroadcast)
+
+	// A finished broadcast can be replaced
+	require.NoError(t, reopened.StartBroadcast("otra", []int64{1}, startedAt))
+}
+
+func TestStore_SetDeliveryBatched(t *testing.T) {
+	t.Parallel()
+
+	var (
+		path      = filepath.Join(t.TempDir(), "store.json")
+		startedAt = time.Date(2026, time.January, 2, 15, 4, 0, 0, time.UTC)
+		chatIDs   = make([]int64, 0, batchSize+1)
+	)
+
+	for id := range int64(batchSize + 1) {
+		chatIDs = append(chatIDs, id)
+	}
+
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	require.NoError(t, s.StartBroadcast("hola", chatIDs, startedAt))
+
+	// persisted reads the delivery statuses from disk, without the store
+	persisted := func() map[int64]DeliveryStatus {
+		reader, err := OpenReadOnly(path)
+		require.NoError(t, err)
+
+		broadcast, ok := reader.Broadcast()
+		require.True(t, ok)
+
+		return broadcast.Deliveries
+	}
+
+	// The deliveries are kept in memory until a batch is complete
+	require.NoError(t, s.SetDelivery(0, DeliverySent, startedAt))
+	assert.Equal(t, DeliveryPending, persisted()[0])
+
+	for _, id := range chatIDs[1:batchSize] {
+		require.NoError(t, s.SetDelivery(id, DeliverySent, startedAt))
+	}
+
+	assert.Equal(t, DeliverySent, persisted()[batchSize-1])
+
+	// Flushing persists the incomplete batch
+	require.NoError(t, s.SetDelivery(batchSize, DeliveryFailed, startedAt))
+	assert.Equal(t, DeliveryPending, persisted()[batchSize])
+
+	require.NoError(t, s.Flush())
+	assert.Equal(t, DeliveryFailed, persisted()[batchSize])
+}
+
+func TestStore_RegisterChatClearsBlock(t *testing.T) {
+	t.Parallel()
+
+	var (
+		s   = NewMemory()
+		now = time.Date(2026, time.January, 2, 15, 4, 0, 0, time.UTC)
+	)
+
+	_, err := s.RegisterChat(1, "private", now)
+	require.NoError(t, err)
+
+	require.NoError(t, s.StartBroadcast("hola", []int64{1}, now))
+	require.NoError(t, s.SetDelivery(1, DeliveryBlocked, now))
+
+	chat, _ := s.Chat(1)
+	require.False(t, chat.BlockedAt.IsZero())
+
+	// Using the bot again clears the block, without registering the chat again
+	registered, err := s.RegisterChat(1, "private", now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, registered)
+
+	chat, _ = s.Chat(1)
+	assert.True(t, chat.BlockedAt.IsZero())
+	assert.Equal(t, now, chat.JoinedAt)
+}
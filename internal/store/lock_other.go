@@ -0,0 +1,19 @@
+//go:build !unix
+
+package store
+
+import (
+	"fmt"
+	"os"
+)
+
+// lockFile creates the lock file, without locking it.
+// File locks are only supported on unix systems
+func lockFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open store lock: %w", err)
+	}
+
+	return file, nil
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"slices"
+	"time"
+)
+
+// PinnedMessage is a rate message kept up to date in a chat,
+// edited in place instead of posting new ones
+type PinnedMessage struct {
+	// UpdatedAt is when the message was last posted or edited
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+
+	Base   string `json:"base"`
+	Target string `json:"target"`
+
+	// Language is the language the message is written in, if not the default
+	Language string `json:"language,omitempty"`
+
+	// Digest identifies the rates shown, to only edit the message when they change
+	Digest string `json:"digest,omitempty"`
+
+	MessageID int `json:"message_id"`
+}
+
+// PinnedFor returns the chat's message for the pair, if any
+func (c Chat) PinnedFor(base, target string) (PinnedMessage, bool) {
+	for _, pinned := range c.Pinned {
+		if pinned.Base == base && pinned.Target == target {
+			return pinned, true
+		}
+	}
+
+	return PinnedMessage{}, false
+}
+
+// clone returns a copy of the chat, not sharing its pinned messages
+func (c *Chat) clone() Chat {
+	chat := *c
+	chat.Pinned = slices.Clone(c.Pinned)
+
+	return chat
+}
+
+// SetPinned records the chat's message for the pair, replacing the previous one,
+// and persists the store
+func (s *Store) SetPinned(chatID int64, pinned PinnedMessage) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	chat, ok := s.data.Chats[chatID]
+	if !ok {
+		chat = &Chat{ID: chatID}
+		s.data.Chats[chatID] = chat
+	}
+
+	index := slices.IndexFunc(chat.Pinned, func(existing PinnedMessage) bool {
+		return existing.Base == pinned.Base && existing.Target == pinned.Target
+	})
+
+	if index == -1 {
+		chat.Pinned = append(chat.Pinned, pinned)
+	} else {
+		chat.Pinned[index] = pinned
+	}
+
+	return s.persist()
+}
+
+// RemovePinned stops keeping the chat's message for the pair up to date,
+// and persists the store
+func (s *Store) RemovePinned(chatID int64, base, target string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	chat, ok := s.data.Chats[chatID]
+	if !ok {
+		return nil
+	}
+
+	chat.Pinned = slices.DeleteFunc(chat.Pinned, func(existing PinnedMessage) bool {
+		return existing.Base == base && existing.Target == target
+	})
+
+	return s.persist()
+}
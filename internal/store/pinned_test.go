@@ -0,0 +1,79 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_SetPinned(t *testing.T) {
+	t.Parallel()
+
+	var (
+		path      = filepath.Join(t.TempDir(), "store.json")
+		updatedAt = time.Date(2026, time.January, 2, 15, 4, 0, 0, time.UTC)
+	)
+
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	require.NoError(t, s.SetPinned(-100, PinnedMessage{Base: "USD", Target: "VES", MessageID: 1}))
+	require.NoError(t, s.SetPinned(-100, PinnedMessage{Base: "EUR", Target: "VES", MessageID: 2}))
+
+	// Replaces the message for the same pair
+	require.NoError(t, s.SetPinned(-100, PinnedMessage{
+		Base:      "USD",
+		Target:    "VES",
+		MessageID: 3,
+		UpdatedAt: updatedAt,
+	}))
+
+	require.NoError(t, s.Close())
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+
+	chat, ok := reopened.Chat(-100)
+	require.True(t, ok)
+	require.Len(t, chat.Pinned, 2)
+
+	pinned, ok := chat.PinnedFor("USD", "VES")
+	require.True(t, ok)
+	assert.Equal(t, 3, pinned.MessageID)
+	assert.Equal(t, updatedAt, pinned.UpdatedAt)
+
+	_, ok = chat.PinnedFor("USDT", "VES")
+	assert.False(t, ok)
+
+	// The returned chat is a copy
+	chat.Pinned[0].MessageID = 10
+
+	stored, _ := reopened.Chat(-100)
+	assert.Equal(t, 3, stored.Pinned[0].MessageID)
+}
+
+func TestStore_RemovePinned(t *testing.T) {
+	t.Parallel()
+
+	s := NewMemory()
+
+	require.NoError(t, s.SetPinned(-100, PinnedMessage{Base: "USD", Target: "VES", MessageID: 1}))
+	require.NoError(t, s.SetPinned(-100, PinnedMessage{Base: "EUR", Target: "VES", MessageID: 2}))
+
+	require.NoError(t, s.RemovePinned(-100, "USD", "VES"))
+
+	// Unknown chats are ignored
+	require.NoError(t, s.RemovePinned(-200, "USD", "VES"))
+
+	chat, ok := s.Chat(-100)
+	require.True(t, ok)
+
+	_, ok = chat.PinnedFor("USD", "VES")
+	assert.False(t, ok)
+
+	_, ok = chat.PinnedFor("EUR", "VES")
+	assert.True(t, ok)
+}
@@ -0,0 +1,340 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// batchSize is how many batched changes are kept in memory before persisting the store
+const batchSize = 50
+
+var (
+	// ErrLocked is returned when opening a store another process has open
+	ErrLocked = errors.New("store is locked by another process")
+
+	// ErrReadOnly is returned when persisting a store opened read-only
+	ErrReadOnly = errors.New("store is read-only")
+)
+
+// state is the persisted store content
+type state struct {
+	Chats map[int64]*Chat `json:"chats"`
+
+	// Broadcast is the latest broadcast, if any
+	Broadcast *Broadcast `json:"broadcast,omitempty"`
+
+	// Usage holds the daily usage counters, by day
+	Usage map[string]*DailyUsage `json:"usage,omitempty"`
+
+	// Announced holds the latest rate announced, by "BASE/TARGET" pair
+	Announced map[string]AnnouncedRate `json:"announced,omitempty"`
+}
+
+// Chat holds the persisted settings for a single Telegram chat
+type Chat struct {
+	// JoinedAt is when the chat first used the bot
+	JoinedAt time.Time `json:"joined_at,omitzero"`
+
+	// BlockedAt is when a message to the chat was last rejected,
+	// because the bot was blocked or removed from it
+	BlockedAt time.Time `json:"blocked_at,omitzero"`
+
+	// NumberFormat overrides the language default number format, if set
+	NumberFormat string `json:"number_format,omitempty"`
+
+	// TimeZone overrides the IANA time zone times are displayed in, if set
+	TimeZone string `json:"time_zone,omitempty"`
+
+	// Announcements is the language new rate announcements are sent in,
+	// or empty if the chat didn't opt in to them
+	Announcements string `json:"announcements,omitempty"`
+
+	// Pinned are the rate messages kept up to date in the chat
+	Pinned []PinnedMessage `json:"pinned,omitempty"`
+
+	// Type is the Telegram chat type: "private", "group", "supergroup" or "channel"
+	Type string `json:"type,omitempty"`
+	ID   int64  `json:"id"`
+}
+
+// Store is a small JSON file backed store for bot state.
+// If no path is given, the state is kept in memory only.
+// A file backed store is locked while open, so a single process writes it
+type Store struct {
+	data *state
+
+	// lock holds the exclusive lock on the store, if it's writable
+	lock *os.File
+	path string
+
+	// unsaved counts the batched changes not persisted yet
+	unsaved int
+	mux     sync.RWMutex
+
+	readOnly bool
+}
+
+// NewMemory creates a new in-memory store
+func NewMemory() *Store {
+	return &Store{
+		data: newState(),
+	}
+}
+
+// Open opens the store at the given path, creating it if it doesn't exist.
+// It's locked until closed, returning ErrLocked if another process has it open.
+// An empty path opens an in-memory store
+func Open(path string) (*Store, error) {
+	if path == "" {
+		return NewMemory(), nil
+	}
+
+	lock, err := lockFile(path + ".lock")
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := read(path)
+	if err != nil {
+		lock.Close()
+
+		return nil, err
+	}
+
+	s.lock = lock
+
+	return s, nil
+}
+
+// OpenReadOnly opens the store at the given path without locking it,
+// so it can be read while another process has it open. It can't be persisted
+func OpenReadOnly(path string) (*Store, error) {
+	if path == "" {
+		return NewMemory(), nil
+	}
+
+	s, err := read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.readOnly = true
+
+	return s, nil
+}
+
+// read reads the store at the given path, if it exists
+func read(path string) (*Store, error) {
+	s := &Store{
+		data: newState(),
+		path: path,
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to read store: %w", err)
+	}
+
+	if err := json.Unmarshal(content, s.data); err != nil {
+		return nil, fmt.Errorf("unable to decode store: %w", err)
+	}
+
+	if s.data.Chats == nil {
+		s.data.Chats = make(map[int64]*Chat)
+	}
+
+	return s, nil
+}
+
+func newState() *state {
+	return &state{
+		Chats: make(map[int64]*Chat),
+	}
+}
+
+// Chat returns a copy of the chat with the given ID, if any
+func (s *Store) Chat(id int64) (Chat, bool) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	chat, ok := s.data.Chats[id]
+	if !ok {
+		return Chat{}, false
+	}
+
+	return chat.clone(), true
+}
+
+// Chats returns a copy of every known chat, sorted by ID
+func (s *Store) Chats() []Chat {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	chats := make([]Chat, 0, len(s.data.Chats))
+	for _, chat := range s.data.Chats {
+		chats = append(chats, chat.clone())
+	}
+
+	sort.Slice(chats, func(i, j int) bool {
+		return chats[i].ID < chats[j].ID
+	})
+
+	return chats
+}
+
+// RegisterChat records a chat using the bot, if it's not registered yet,
+// and reports whether it was registered now. Registered chats are only
+// updated to clear a block, so the store is rarely persisted more than once per chat
+func (s *Store) RegisterChat(id int64, chatType string, joinedAt time.Time) (bool, error) {
+	s.mux.RLock()
+	chat, ok := s.data.Chats[id]
+	registered := ok && chat.Type != "" && chat.BlockedAt.IsZero()
+	s.mux.RUnlock()
+
+	if registered {
+		return false, nil
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	chat, ok = s.data.Chats[id]
+	if !ok {
+		chat = &Chat{ID: id}
+		s.data.Chats[id] = chat
+	}
+
+	if chat.Type != "" {
+		if chat.BlockedAt.IsZero() {
+			// Registered concurrently
+			return false, nil
+		}
+
+		// The chat is using the bot again, so it's no longer blocked
+		chat.BlockedAt = time.Time{}
+
+		return false, s.persist()
+	}
+
+	chat.Type = chatType
+
+	if chat.JoinedAt.IsZero() {
+		chat.JoinedAt = joinedAt
+	}
+
+	return true, s.persist()
+}
+
+// UpdateChat applies the update to the chat with the given ID, creating it if needed,
+// and persists the store
+func (s *Store) UpdateChat(id int64, update func(*Chat)) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	chat, ok := s.data.Chats[id]
+	if !ok {
+		chat = &Chat{ID: id}
+		s.data.Chats[id] = chat
+	}
+
+	update(chat)
+
+	return s.persist()
+}
+
+// Flush persists the batched changes, if any
+func (s *Store) Flush() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.unsaved == 0 {
+		return nil
+	}
+
+	return s.persist()
+}
+
+// Close persists the batched changes, and releases the store lock
+func (s *Store) Close() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	var err error
+
+	if s.unsaved > 0 {
+		err = s.persist()
+	}
+
+	if s.lock != nil {
+		err = errors.Join(err, s.lock.Close())
+		s.lock = nil
+	}
+
+	return err
+}
+
+// persistBatched persists the store once every batchSize changes,
+// so frequent small changes don't rewrite it every time.
+// The caller must hold the write lock
+func (s *Store) persistBatched() error {
+	s.unsaved++
+
+	if s.unsaved < batchSize {
+		return nil
+	}
+
+	return s.persist()
+}
+
+// persist writes the store to disk, if it's file backed.
+// The caller must hold the write lock
+func (s *Store) persist() error {
+	if s.path == "" {
+		return nil
+	}
+
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	encoded, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode store: %w", err)
+	}
+
+	// Write to a temporary file first, so a crash
+	// mid-write doesn't corrupt the existing store
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("unable to create temporary store file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(encoded); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("unable to write store: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to close store: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("unable to replace store: %w", err)
+	}
+
+	s.unsaved = 0
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Memory(t *testing.T) {
+	t.Parallel()
+
+	s, err := Open("")
+	require.NoError(t, err)
+
+	_, ok := s.Chat(1)
+	assert.False(t, ok)
+
+	require.NoError(t, s.UpdateChat(1, func(chat *Chat) {
+		chat.NumberFormat = "comma"
+	}))
+
+	chat, ok := s.Chat(1)
+	require.True(t, ok)
+
+	assert.Equal(t, int64(1), chat.ID)
+	assert.Equal(t, "comma", chat.NumberFormat)
+}
+
+func TestStore_ChatIsCopy(t *testing.T) {
+	t.Parallel()
+
+	s := NewMemory()
+
+	require.NoError(t, s.UpdateChat(1, func(chat *Chat) {
+		chat.NumberFormat = "comma"
+	}))
+
+	chat, _ := s.Chat(1)
+	chat.NumberFormat = "point"
+
+	stored, _ := s.Chat(1)
+	assert.Equal(t, "comma", stored.NumberFormat)
+}
+
+func TestStore_Chats(t *testing.T) {
+	t.Parallel()
+
+	s := NewMemory()
+
+	for _, id := range []int64{3, -100, 1} {
+		require.NoError(t, s.UpdateChat(id, func(*Chat) {}))
+	}
+
+	chats := s.Chats()
+	require.Len(t, chats, 3)
+
+	assert.Equal(t, int64(-100), chats[0].ID)
+	assert.Equal(t, int64(1), chats[1].ID)
+	assert.Equal(t, int64(3), chats[2].ID)
+}
+
+func TestStore_RegisterChat(t *testing.T) {
+	t.Parallel()
+
+	var (
+		s        = NewMemory()
+		joinedAt = time.Date(2026, time.January, 2, 15, 4, 0, 0, time.UTC)
+	)
+
+	// Chats with settings, but no type, get registered too
+	require.NoError(t, s.UpdateChat(2, func(chat *Chat) {
+		chat.NumberFormat = "comma"
+	}))
+
+	for _, id := range []int64{1, 2} {
+		registered, err := s.RegisterChat(id, "private", joinedAt)
+		require.NoError(t, err)
+		assert.True(t, registered)
+	}
+
+	registered, err := s.RegisterChat(1, "group", joinedAt.Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, registered)
+
+	chat, ok := s.Chat(1)
+	require.True(t, ok)
+	assert.Equal(t, "private", chat.Type)
+	assert.Equal(t, joinedAt, chat.JoinedAt)
+
+	chat, ok = s.Chat(2)
+	require.True(t, ok)
+	assert.Equal(t, "comma", chat.NumberFormat)
+	assert.Equal(t, "private", chat.Type)
+}
+
+func TestStore_Persistence(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "store.json")
+
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	require.NoError(t, s.UpdateChat(42, func(chat *Chat) {
+		chat.NumberFormat = "point"
+	}))
+
+	require.NoError(t, s.Close())
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+
+	chat, ok := reopened.Chat(42)
+	require.True(t, ok)
+	assert.Equal(t, "point", chat.NumberFormat)
+
+	// No temporary files are left behind, only the store and its lock
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestStore_Lock(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "store.json")
+
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	require.NoError(t, s.UpdateChat(42, func(chat *Chat) {
+		chat.NumberFormat = "point"
+	}))
+
+	// Another writer can't open it, so it can't overwrite the changes
+	_, err = Open(path)
+	assert.ErrorIs(t, err, ErrLocked)
+
+	// Readers can
+	reader, err := OpenReadOnly(path)
+	require.NoError(t, err)
+
+	chat, ok := reader.Chat(42)
+	require.True(t, ok)
+	assert.Equal(t, "point", chat.NumberFormat)
+
+	assert.ErrorIs(t, reader.UpdateChat(42, func(chat *Chat) {
+		chat.NumberFormat = "comma"
+	}), ErrReadOnly)
+
+	// Closing releases the lock
+	require.NoError(t, s.Close())
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+	require.NoError(t, reopened.Close())
+}
+
+func TestStore_OpenInvalid(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "store.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+
+	_, err := Open(path)
+
+	assert.ErrorContains(t, err, "unable to decode store")
+}
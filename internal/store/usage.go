@@ -0,0 +1,133 @@
+package store
+
+import (
+	"maps"
+	"sort"
+)
+
+// DailyUsage holds the usage counters of a single day
+type DailyUsage struct {
+	// Chats are the updates received from every active chat, by chat ID
+	Chats map[int64]int `json:"chats,omitempty"`
+
+	// Commands are the handled commands, by command
+	Commands map[string]int `json:"commands,omitempty"`
+
+	// Pairs are the requested rates, by "BASE/TARGET" pair
+	Pairs map[string]int `json:"pairs,omitempty"`
+
+	// Languages are the updates received, by response language
+	Languages map[string]int `json:"languages,omitempty"`
+
+	// ChatTypes are the updates received, by Telegram chat type
+	ChatTypes map[string]int `json:"chat_types,omitempty"`
+
+	// Day is the date of the counters, as YYYY-MM-DD
+	Day string `json:"day"`
+
+	Updates int `json:"updates"`
+}
+
+// Merge adds the counters of the other day to the day
+func (u *DailyUsage) Merge(other DailyUsage) {
+	u.Chats = mergeCounts(u.Chats, other.Chats)
+	u.Commands = mergeCounts(u.Commands, other.Commands)
+	u.Pairs = mergeCounts(u.Pairs, other.Pairs)
+	u.Languages = mergeCounts(u.Languages, other.Languages)
+	u.ChatTypes = mergeCounts(u.ChatTypes, other.ChatTypes)
+	u.Updates += other.Updates
+}
+
+// clone returns a deep copy of the day
+func (u *DailyUsage) clone() DailyUsage {
+	return DailyUsage{
+		Chats:     maps.Clone(u.Chats),
+		Commands:  maps.Clone(u.Commands),
+		Pairs:     maps.Clone(u.Pairs),
+		Languages: maps.Clone(u.Languages),
+		ChatTypes: maps.Clone(u.ChatTypes),
+		Day:       u.Day,
+		Updates:   u.Updates,
+	}
+}
+
+// mergeCounts adds the counts of src to dst, allocating it if needed
+func mergeCounts[K comparable](dst, src map[K]int) map[K]int {
+	if len(src) == 0 {
+		return dst
+	}
+
+	if dst == nil {
+		dst = make(map[K]int, len(src))
+	}
+
+	for key, count := range src {
+		dst[key] += count
+	}
+
+	return dst
+}
+
+// AddUsage merges the counters into the stored days, drops the days
+// before the oldest one kept (YYYY-MM-DD), and persists the store
+func (s *Store) AddUsage(usage []DailyUsage, oldest string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	// Merge into a copy, so the counters are left untouched
+	// if they can't be persisted, and the caller can retry
+	updated := make(map[string]*DailyUsage, len(s.data.Usage)+len(usage))
+
+	for day, stored := range s.data.Usage {
+		// Days sort lexicographically
+		if day >= oldest {
+			clone := stored.clone()
+			updated[day] = &clone
+		}
+	}
+
+	for _, day := range usage {
+		if day.Day < oldest {
+			continue
+		}
+
+		stored, ok := updated[day.Day]
+		if !ok {
+			stored = &DailyUsage{Day: day.Day}
+			updated[day.Day] = stored
+		}
+
+		stored.Merge(day)
+	}
+
+	previous := s.data.Usage
+	s.data.Usage = updated
+
+	if err := s.persist(); err != nil {
+		s.data.Usage = previous
+
+		return err
+	}
+
+	return nil
+}
+
+// Usage returns a copy of the stored days since the given one (YYYY-MM-DD), oldest first
+func (s *Store) Usage(since string) []DailyUsage {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	usage := make([]DailyUsage, 0, len(s.data.Usage))
+
+	for day, stored := range s.data.Usage {
+		if day >= since {
+			usage = append(usage, stored.clone())
+		}
+	}
+
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Day < usage[j].Day
+	})
+
+	return usage
+}
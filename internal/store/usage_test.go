@@ -0,0 +1,50 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Usage(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "store.json")
+
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	require.NoError(t, s.AddUsage([]DailyUsage{
+		{Day: "2026-01-01", Updates: 1, Chats: map[int64]int{1: 1}},
+		{Day: "2026-01-02", Updates: 2, Commands: map[string]int{"/tasa": 2}},
+	}, "2026-01-01"))
+
+	require.NoError(t, s.AddUsage([]DailyUsage{
+		{Day: "2026-01-02", Updates: 1, Commands: map[string]int{"/tasa": 1, "/dolar": 1}},
+		{Day: "2026-01-03", Updates: 1, Pairs: map[string]int{"USD/VES": 1}},
+	}, "2026-01-02"))
+
+	require.NoError(t, s.Close())
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+
+	// The first day is past the retention
+	usage := reopened.Usage("")
+	require.Len(t, usage, 2)
+
+	assert.Equal(t, DailyUsage{
+		Day:      "2026-01-02",
+		Updates:  3,
+		Commands: map[string]int{"/tasa": 3, "/dolar": 1},
+	}, usage[0])
+	assert.Equal(t, "2026-01-03", usage[1].Day)
+
+	assert.Len(t, reopened.Usage("2026-01-03"), 1)
+
+	// The returned days are copies
+	usage[0].Commands["/tasa"] = 10
+	assert.Equal(t, 3, reopened.Usage("")[0].Commands["/tasa"])
+}